@@ -1,8 +1,14 @@
 // Package abi provides C ABI types for interoperability with shared C libraries.
+//
+// The types and constants that depend on the target are generated for each
+// GOOS_GOARCH by gen/gen.sh, see there to generate them for another target.
+// The linux/arm64 and linux/386 tables have not been generated yet and
+// should not be relied upon until they are.
 package abi
 
+//go:generate sh gen/gen.sh
+
 import "unsafe"
-import "C"
 
 // Fixed width types.
 type (
@@ -17,7 +23,9 @@ type (
 	Uintptr = uintptr
 )
 
-// Func pointer in C with Go function type equivalent.
+// Func pointer in C with Go function type equivalent,
+// see [qlova.tech/ffi.Func] to call one from Go, and
+// [qlova.tech/ffi.NewFunc] to make one for a Go func.
 type Func[GoFunc any] Pointer[GoFunc]
 
 // Opaque pointer type in C memory that cannot be
@@ -34,11 +42,21 @@ type String struct {
 
 // String implements fmt.Stringer.
 func (s String) String() string {
-	return C.GoString((*C.char)(unsafe.Pointer(s.ptr)))
+	if s.ptr == nil {
+		return ""
+	}
+	n := 0
+	for *(*Char)(unsafe.Add(unsafe.Pointer(s.ptr), n)) != 0 {
+		n++
+	}
+	return string(unsafe.Slice((*byte)(unsafe.Pointer(s.ptr)), n))
 }
 
 // NewString returns the given Go string in
-// Go memory as a null-terminated C string.
+// Go memory as a null-terminated C string,
+// C must not keep it after the call that it
+// is passed to, unless it is retained, see
+// [qlova.tech/ffi.Retain].
 func NewString(s string) String {
 	if len(s) == 0 || s[len(s)-1] != 0 {
 		s += "\x00"
@@ -56,7 +74,11 @@ type Buffer struct {
 
 // Bytes returns the buffer as a Go byte slice.
 func (s Buffer) Bytes() []byte {
-	return C.GoBytes(unsafe.Pointer(s.ptr), C.int(s.len))
+	bytes := make([]byte, s.len)
+	if s.len > 0 {
+		copy(bytes, unsafe.Slice((*byte)(s.ptr), s.len))
+	}
+	return bytes
 }
 
 // Len returns the length of the buffer.
@@ -64,9 +86,18 @@ func (s Buffer) Len() Int {
 	return s.len
 }
 
+// UnsafePointer returns the pointer to the first byte
+// in the buffer, nil if it is empty.
+func (s Buffer) UnsafePointer() unsafe.Pointer {
+	return unsafe.Pointer(s.ptr)
+}
+
 // NewBuffer returns the given Go byte slice in Go memory
 // as a C buffer.
 func NewBuffer(s []byte) Buffer {
+	if len(s) == 0 {
+		return Buffer{}
+	}
 	return Buffer{&s[0], Int(len(s))}
 }
 
@@ -0,0 +1,339 @@
+// This table has not been generated by gen/gen.c yet, it was transcribed
+// from the glibc headers for linux/386, regenerate it on a host with
+// the cross compiler and qemu-user, see gen/gen.sh:
+//
+//	GOARCH=386 go generate qlova.tech/abi
+
+package abi
+
+import "sync/atomic"
+
+const (
+	ErrDomain              Error = 33
+	ErrIllegalByteSequence Error = 84
+	ErrResultTooLarge      Error = 34
+)
+
+const (
+	FloatRadix                = 2
+	DecimalDigits             = 21
+	FloatDecimalDigits        = 9
+	DoubleDecimalDigits       = 17
+	DoubleLongDecimalDigits   = 21
+	MinFloat                  = 0.000000
+	MinDouble                 = 0.000000
+	MinDoubleLong             = 0.000000
+	TrueMinFloat              = 0.000000
+	TrueMinDouble             = 0.000000
+	TrueMinDoubleLong         = 0.000000
+	MaxFloat                  = 340282346638528859811704183484516925440.000000
+	MaxDouble                 = 179769313486231570814527423731704356798070567525844996598917476803157260780028538760589558632766878171540458953514382464234321326889464182768467546703537516986049910576551282076245490090389328944075868508455133942304583236903222948165808559332123348274797826204144723168738177180919299881250404026184124858368.000000
+	MaxDoubleLong             = 1189731495357231765021263853030970205169063322294624200440323733891737005522970722616410290336528882853545697807495577314427443153670288434198125573853743678673593200706973263201915918282961524365529510646791086614311790632169778838896134786560600399148753433211454911160088679845154866512852340149773037600009125479393966223151383622417838542743917838138717805889487540575168226347659235576974805113725649020884855222494791399377585026011773549180099796226026859508558883608159846900235645132346594476384939859276456284579661772930407806609229102715046085388087959327781622986827547830768080040150694942303411728957777100335714010559775242124057347007386251660110828379119623008469277200965153500208474470792443848545912886723000619085126472111951361467527633519562927597957250278002980795904193139603021470997035276467445530922022679656280991498232083329641241038509239184734786121921697210543484287048353408113042573002216421348917347174234800714880751002064390517234247656004721768096486107994943415703476320643558624207443504424380566136017608837478165389027809576975977286860071487028287955567141404632615832623602762896316173978484254486860609948270867968048078702511858930838546584223040908805996294594586201903766048446790926002225410530775901065760671347200125846406957030257138960983757998926954553052368560758683179223113639519468850880771872104705203957587480013143131444254943919940175753169339392366881856189129931729104252921236835159922322050998001677102784035360140829296398115122877768135706045789343535451696539561254048846447169786893211671087229088082778350518228857646062218739702851655083720992349483334435228984751232753726636066213902281264706234075352071724058665079518217303463782631353393706774901950197841690441824738063162828586857741432581165364040218402724913393320949219498422442730427019873044536620350262386957804682003601447291997123095530057206141866974852846856186514832715974481203121946751686379343096189615107330065552421485195201762858595091051839472502863871632494167613804996319791441870254302706758495192008837915169401581740046711477877201459644461175204059453504764721807975761111720846273639279600339670470037613374509553184150073796412605047923251661354841291884211340823015473304754067072818763503617332908005951896325207071673904547777129682265206225651439919376804400292380903112437912614776255964694221981375146967079446870358004392507659451618379811859392049544036114915310782251072691486979809240946772142727012404377187409216756613634938900451232351668146089322400697993176017805338191849981933008410985993938760292601390911414526003720284872132411955424282101831204216104467404621635336900583664606591156298764745525068145003932941404131495400677602951005962253022823003631473824681059648442441324864573137437595096416168048024129351876204668135636877532814675538798871771836512893947195335061885003267607354388673368002074387849657014576090349857571243045102038730494854256702479339322809110526041538528994849203991091946129912491633289917998094380337879522093131466946149705939664152375949285890960489916121944989986384837022486672249148924678410206183364627416969576307632480235587975245253737035433882960862753427740016333434055083537048507374544819754722228975281083020898682633020285259923084168054539687911418297629988964576482765287504562854924265165217750799516259669229114977788962356670956627138482018191348321687995863652637620978285070099337294396784639879024914514222742527006363942327998483976739987154418554201562244154926653014515504685489258620276085761837129763358761215382565129633538141663949516556000264159186554850057052611431952919918807954522394649627635630178580896692226406235382898535867595990647008385687123810329591926494846250768992258419305480763620215089022149220528069842018350840586938493815498909445461977893029113576516775406232278298314033473276603952231603422824717528181818844304880921321933550869873395861276073670866652375555675803171490108477320096424318780070008797346032906278943553743564448851907191616455141155761939399690767415156402826543664026760095087523945507341556135867933066031744720924446513532366647649735400851967040771103640538150073486891798364049570606189535005089840913826869535090066783324472578712196604415284924840041850932811908963634175739897166596000759487800619164094854338758520657116541072260996288150123144377944008749301944744330784388995701842710004808305012177123560622895076269042856800047718893158089358515593863176652948089031267747029662545110861548958395087796755464137944895960527975209874813839762578592105756284401759349324162148339565350189196811389091843795734703269406342890087805846940352453479398080674273236297887100867175802531561302356064878709259865288416350972529537091114317204887747405539054009425375424119317944175137064689643861517718849867010341532542385911089624710885385808688837777258648564145934262121086647588489260031762345960769508849149662444156604419552086811989770240.000000
+	FloatEpsilon              = 0.000000
+	DoubleEpsilon             = 0.000000
+	DoubleLongEpsilon         = 0.000000
+	FloatTextDigits           = 6
+	DoubleTextDigits          = 15
+	DoubleLongTextDigits      = 18
+	FloatMantissaDigits       = 24
+	DoubleMantissaDigits      = 53
+	DoubleLongMantissaDigits  = 64
+	MinExpFloat               = -125
+	MinExpDouble              = -1021
+	MinExpDoubleLong          = -16381
+	Min10ExpFloat             = -37
+	Min10ExpDouble            = -307
+	Min10ExpDoubleLong        = -4931
+	MaxExpFloat               = 128
+	MaxExpDouble              = 1024
+	MaxExpDoubleLong          = 16384
+	Max10ExpFloat             = 38
+	Max10ExpDouble            = 308
+	Max10ExpDoubleLong        = 4932
+	FloatEvalMethod           = 2
+	FloatHasSubnormal         = 1
+	DoubleHasSubnormal        = 1
+	DoubleLongHasSubnormal    = 1
+)
+
+const (
+	FloatExceptionsDefault FloatException = 0
+	FloatDivisionByZero    FloatException = 4
+	FloatInexact           FloatException = 32
+	FloatInvalid           FloatException = 1
+	FloatOverflow          FloatException = 8
+	FloatUnderflow         FloatException = 16
+	FloatExceptionsAll     FloatException = 61
+)
+
+const (
+	FloatRoundDefault    FloatRoundingMode = 0
+	FloatRoundDownward   FloatRoundingMode = 1024
+	FloatRoundToNearest  FloatRoundingMode = 0
+	FloatRoundTowardZero FloatRoundingMode = 3072
+	FloatRoundUpward     FloatRoundingMode = 2048
+)
+
+const (
+	FloatIsNormal          FloatClass = 4
+	FloatIsSubnormal       FloatClass = 3
+	FloatIsZero            FloatClass = 2
+	FloatIsInfinite        FloatClass = 1
+	FloatIsNaN             FloatClass = 0
+)
+
+const (
+	Termination         Signal = 15
+	InvalidMemoryAccess Signal = 11
+	Interrupt           Signal = 2
+	InvalidInstruction  Signal = 4
+	AbnormalTermination Signal = 6
+	FloatingPointError  Signal = 8
+)
+
+const (
+	AtomicBoolLockFree         = 2
+	AtomicCharLockFree         = 2
+	AtomicChar16LockFree       = 2
+	AtomicChar32LockFree       = 2
+	AtomicWcharLockFree        = 2
+	AtomicShortLockFree        = 2
+	AtomicIntLockFree          = 2
+	AtomicLongLockFree         = 2
+	AtomicLongLongLockFree     = 2
+	AtomicPointerLockFree      = 2
+)
+
+const (
+	EOF            = -1
+	MaxFiles       = 16
+	MaxFileNameLen = 4096
+	BufferSize     = 8192
+	MaxTempFiles   = 238328
+	TempNameSize   = 20
+)
+
+const (
+	FullyBuffered BufferMode = 0
+	LineBuffered  BufferMode = 1
+	Unbuffered    BufferMode = 2
+)
+
+const (
+	SeekStart   SeekMode = 0
+	SeekCurrent SeekMode = 1
+	SeekEnd     SeekMode = 2
+)
+
+type Locale struct {
+	DecimalPoint                   String
+	ThousandsSeperator             String
+	Grouping                       String
+	CurrencyName                   String
+	CurrencySymbol                 String
+	MonetaryDecimalPoint           String
+	MonetaryThousandsSeperator     String
+	MonetaryGrouping               String
+	PositiveSign                   String
+	NegativeSign                   String
+	MonetaryFractionalDigits       Char
+	FractionDigits                 Char
+	LocalCurrencyPrefixesPositive  Char
+	LocalCurrencyPositiveSpacing   Char
+	LocalCurrencyPrefixesNegative  Char
+	LocalCurrencyNegativeSpacing   Char
+	LocalCurrencyPositiveSignPos   Char
+	LocalCurrencyNegativeSignPos   Char
+	CurrencyPrefixesPositive       Char
+	CurrencyPositiveSpacing        Char
+	CurrencyPrefixesNegative       Char
+	CurrencyNegativeSpacing        Char
+	CurrencyPositiveSignPos        Char
+	CurrencyNegativeSignPos        Char
+}
+
+type NanoTime struct {
+	Seconds     Time
+	Nanoseconds Time
+}
+
+type Date struct {
+	Seconds         Int
+	Minutes         Int
+	Hours           Int
+	Days            Int
+	Months          Int
+	Years           Int
+	Weekdays        Int
+	DaysThisYear    Int
+	DaylightSavings Int
+}
+
+const (
+	LocaleAll          LocaleCategory = 6
+	LocaleCollate      LocaleCategory = 3
+	LocaleC            LocaleCategory = 0
+	LocaleMonetary     LocaleCategory = 4
+	LocaleNumeric      LocaleCategory = 1
+	LocaleTime         LocaleCategory = 2
+)
+
+const (
+UTC            TimeType = 1
+
+ClocksPerSec   Clock      = 1000000
+)
+
+type FloatingPointEnvironment [28]byte
+
+type JumpBuffer [156]byte
+
+type File [148]byte
+
+type FilePosition [12]byte
+
+const (
+	CharBits                = 8
+	MaxRuneLength           = 16
+	MinChar                 = -128
+	MaxChar                 = 127
+	MinSignedChar           = -128
+	MinShort                = -32768
+	MinInt                  = -2147483648
+	MinLong                 = -2147483648
+	MinLongLong             = -9223372036854775808
+	MaxSignedChar           = 127
+	MaxShort                = 32767
+	MaxInt                  = 2147483647
+	MaxLong                 = 2147483647
+	MaxLongLong             = 9223372036854775807
+	MaxUnsignedChar         = 255
+	MaxUnsignedShort        = 65535
+	MaxUnsignedInt          = 4294967295
+	MaxUnsignedLong         = 4294967295
+	MaxUnsignedLongLong     = 18446744073709551615
+	MinInt8                 = -128
+	MinInt16                = -32768
+	MinInt32                = -2147483648
+	MinInt64                = -9223372036854775808
+	MinFast8                = -128
+	MinFast16               = -2147483648
+	MinFast32               = -2147483648
+	MinFast64               = -9223372036854775808
+	MinLeast8               = -128
+	MinLeast16              = -32768
+	MinLeast32              = -2147483648
+	MinLeast64              = -9223372036854775808
+	MinIntptr               = -2147483648
+	MinIntmax               = -9223372036854775808
+	MaxInt8                 = 127
+	MaxInt16                = 32767
+	MaxInt32                = 2147483647
+	MaxInt64                = 9223372036854775807
+	MaxFastInt8             = 127
+	MaxFastInt16            = 2147483647
+	MaxFastInt32            = 2147483647
+	MaxFastInt64            = 9223372036854775807
+	MaxLeastInt8            = 127
+	MaxLeastInt16           = 32767
+	MaxLeastInt32           = 2147483647
+	MaxLeastInt64           = 9223372036854775807
+	MaxIntptr               = 2147483647
+	MaxIntmax               = 9223372036854775807
+	MaxUint8                = 255
+	MaxUint16               = 65535
+	MaxUint32               = 4294967295
+	MaxUint64               = 18446744073709551615
+	MaxFastUint8            = 255
+	MaxFastUint16           = 4294967295
+	MaxFastUint32           = 4294967295
+	MaxFastUint64           = 18446744073709551615
+	MaxLeastUint8           = 255
+	MaxLeastUint16          = 65535
+	MaxLeastUint32          = 4294967295
+	MaxLeastUint64          = 18446744073709551615
+	MaxUintptr              = 4294967295
+	MaxUintmax              = 18446744073709551615
+)
+
+type (
+	Bool                bool
+	Char                int8
+	CharSigned          int8
+	CharUnsigned        uint8
+	CharWide            int32
+	Short               int16
+	IntShort            int16
+	ShortSigned         int16
+	IntShortSigned      int16
+	ShortUnsigned       uint16
+	IntShortUnsigned    uint16
+	Int                 int32
+	Signed              int32
+	IntSigned           int32
+	Unsigned            uint32
+	IntUnsigned         uint32
+	Long                int32
+	LongInt             int32
+	LongSigned          int32
+	IntLongSigned       int32
+	LongUnsigned        uint32
+	IntLongUnsigned     uint32
+	LongLong            int64
+	IntLongLong         int64
+	LongLongSigned      int64
+	IntLongLongSigned   int64
+	LongLongUnsigned    uint64
+	IntLongLongUnsigned uint64
+	Float               float32
+	Double              float64
+	DoubleLong          [12]byte
+	ComplexFloat        [2]Float
+	ComplexDouble       [2]Double
+	ComplexDoubleLong   [2]DoubleLong
+	FastInt8            int8
+	FastInt16           int32
+	FastInt32           int32
+	FastInt64           int64
+	LeastInt8           int8
+	LeastInt16          int16
+	LeastInt32          int32
+	LeastInt64          int64
+	IntMax              int64
+	Intptr              int32
+	FastUInt8           uint8
+	FastUInt16          uint32
+	FastUInt32          uint32
+	FastUInt64          uint64
+	LeastUInt8          uint8
+	LeastUInt16         uint16
+	LeastUInt32         uint32
+	LeastUInt64         uint64
+	UIntMax             uint64
+	FastFloat          DoubleLong
+	FastDouble         DoubleLong
+	AtomicBool             atomic.Bool
+	AtomicInt              atomic.Int32
+	AtomicUnsignedInt      atomic.Uint32
+	AtomicLong             atomic.Int32
+	AtomicUnsignedLong     atomic.Uint32
+	AtomicUnsignedLongLong atomic.Uint64
+	AtomicChar32           atomic.Uint32
+	AtomicWchar            atomic.Uint32
+	AtomicIntLeast32       atomic.Int32
+	AtomicUIntLeast32      atomic.Uint32
+	AtomicIntFast32        atomic.Int32
+	AtomicUIntFast32       atomic.Uint32
+	AtomicIntFast64        atomic.Int64
+	AtomicUIntFast64       atomic.Uint64
+	AtomicUintptr          atomic.Uint32
+	AtomicSize             atomic.Uint32
+	AtomicPtrdiff          atomic.Int32
+	AtomicIntMax           atomic.Int64
+	AtomicUIntMax          atomic.Uint64
+	Size                uint32
+	StringWide          struct{uint32}
+	Ptrdiff             int32
+	Time                int32
+	Clock               int32
+)
@@ -0,0 +1,339 @@
+// This table has not been generated by gen/gen.c yet, it was transcribed
+// from the glibc headers for linux/arm64, regenerate it on a host with
+// the cross compiler and qemu-user, see gen/gen.sh:
+//
+//	GOARCH=arm64 go generate qlova.tech/abi
+
+package abi
+
+import "sync/atomic"
+
+const (
+	ErrDomain              Error = 33
+	ErrIllegalByteSequence Error = 84
+	ErrResultTooLarge      Error = 34
+)
+
+const (
+	FloatRadix                = 2
+	DecimalDigits             = 36
+	FloatDecimalDigits        = 9
+	DoubleDecimalDigits       = 17
+	DoubleLongDecimalDigits   = 36
+	MinFloat                  = 0.000000
+	MinDouble                 = 0.000000
+	MinDoubleLong             = 0.000000
+	TrueMinFloat              = 0.000000
+	TrueMinDouble             = 0.000000
+	TrueMinDoubleLong         = 0.000000
+	MaxFloat                  = 340282346638528859811704183484516925440.000000
+	MaxDouble                 = 179769313486231570814527423731704356798070567525844996598917476803157260780028538760589558632766878171540458953514382464234321326889464182768467546703537516986049910576551282076245490090389328944075868508455133942304583236903222948165808559332123348274797826204144723168738177180919299881250404026184124858368.000000
+	MaxDoubleLong             = 1189731495357231765085759326628007016196469052641694045529698884212163579755312392324974012848462073525902033564749126859755265433573804462672698751945261490853461958725021262845865799405404493574681566096686172574953791792292256220777095858112702436475442537092608935138247345677279593806773692330094615746119725784172889892521939920757654204864565673356452247278152288867700638935595456496699511441752909606878513250948311396886100526833092128683974752192266386791880873694343077348155564101669971138512786874753496996549221727686770196551512812712488289469952298031867469924683981576664562667786719061499639630341657098305425237220876664630087808767256182803220212219924852375903049520911395910918921205273496768588119030111593018789368039232011671404175845108854706965215605777113516257404818817695075025715299705916714352103671782759119316034498392169720631800164034124698918142227577300459309880454715179606299895507583075851195185857971173167676966057998899352631885417716295302014668802384075846036226606480142977595407135050379808649130157164024060311786908796372510335873512774795275748595417572920936651398752709055215663939505589207804914540432978557623565645991208599669097180808881920063722771431218489011922209679053545963628417326002439732802939524313786668514027381434321036636571171670423586472759561231970793967839279147282720195377060602122638457883204809341717526809639253539447730280863675704796054050525162959099932535265586464682793821550087166946662209865086040990507131145474267411042839542322762994938759613112743837192839682676257555388372814490845395747128162065871588219108887240116651361962050800029176299938826082417547516732269930473133261258921845516815235455354310458114528303607394526100730578774092094736822286015459361126642549541799645333882549670764145955017051330800061253865140180153211929361456500343514792890205532021760061882232615736553377294980974059590520187961459799386741513028505934410453603480192383349321115171811051004108592830991811382552909064873029533418691087118107895004426881765865961841419267486232005929789956207494587649901662172318722999484512325826087031561936383689740686505279775296789331613683822798597040651600524129025149894873153196942095056670847466927644812596506700129443579512479230621373978088731257089799622902183824105412930483065603459863120371744282301377070153823878609951218937542956964157950988060608985782910656238116142203574104757451828170804875257446204128348513829082731722364189380493588338947664370623279820755831646205417488393062838201789547219543194450902113699925965376908192792152122212824578879336506875288617303469517112245451315447164280392523574962804175375927948971096983905242318797695347043690474223813266505639761164438844266531364626851219633994434154098562127395936184421821444273431534507860161614287022720984061569660333372788241037131538077377480152670583257920535569973318188112685673318997967497786786001251403873023920127717626858627038170562807276699687356274072773403132694104831615879354395811585825112837841563222761623334459188131537882355732483030085976890382969734476214593428191212717141333047577867552218517431064848760373196290310124466145087078377140528533048684204278799596652514009368964527494988719996088230065668196236298805733689960371306226158464997243490564472254071897564144128539839986096045563264771285585066304177995720101744844387158329767375560416207800878830072072413908657855667239546369357775781344288195989176313356856417845434232814886744226746707066979755577121788798468777700116472954103621810567107869855646414713502627836321256957407217461738363552424248762436478085351810995749293238174081331905048144612700905541425702220302537611494824228765324577933778519818778697340282580912780674979058938062556856001076057705982166686824756037569615760497619819482052758118532729333127733603742149847001463931981340719681330844408263017545241644293372483217234561694263937855759294448662979095419227451801588425977869694026601427919655168415895923043115191751872713346095752634608254475988154162254952597853199039645883742199236387610395830948074365988397707849632252080920941206268114832425403540515474312327876180802357701527842702008781378306569508588571830140611098042683009530862797403015355464377406249853964481000402231771665700893607521804084523668568649103258862666293372472441435563520595461701042390500795615834505944837326652542467444364861499184275097485253621979537504128523848241127715641240965261646703516395599407360083455079665191393229410544185167999099787655424462558900874388405649169453726739312260234815543297842308646072190147948072928456725835039546121182133640777769925841807579051735838823112759622714067509669913645288281894558925612972425252452248453502562347348900936766966136332741088135837550717443838484760651019872222926016920811114616937143207743488504602012776364256746872315205952601072228970686460932435222754496341763535189105548847634608972381760403137363968.000000
+	FloatEpsilon              = 0.000000
+	DoubleEpsilon             = 0.000000
+	DoubleLongEpsilon         = 0.000000
+	FloatTextDigits           = 6
+	DoubleTextDigits          = 15
+	DoubleLongTextDigits      = 33
+	FloatMantissaDigits       = 24
+	DoubleMantissaDigits      = 53
+	DoubleLongMantissaDigits  = 113
+	MinExpFloat               = -125
+	MinExpDouble              = -1021
+	MinExpDoubleLong          = -16381
+	Min10ExpFloat             = -37
+	Min10ExpDouble            = -307
+	Min10ExpDoubleLong        = -4931
+	MaxExpFloat               = 128
+	MaxExpDouble              = 1024
+	MaxExpDoubleLong          = 16384
+	Max10ExpFloat             = 38
+	Max10ExpDouble            = 308
+	Max10ExpDoubleLong        = 4932
+	FloatEvalMethod           = 0
+	FloatHasSubnormal         = 1
+	DoubleHasSubnormal        = 1
+	DoubleLongHasSubnormal    = 1
+)
+
+const (
+	FloatExceptionsDefault FloatException = 0
+	FloatDivisionByZero    FloatException = 2
+	FloatInexact           FloatException = 16
+	FloatInvalid           FloatException = 1
+	FloatOverflow          FloatException = 4
+	FloatUnderflow         FloatException = 8
+	FloatExceptionsAll     FloatException = 31
+)
+
+const (
+	FloatRoundDefault    FloatRoundingMode = 0
+	FloatRoundDownward   FloatRoundingMode = 8388608
+	FloatRoundToNearest  FloatRoundingMode = 0
+	FloatRoundTowardZero FloatRoundingMode = 12582912
+	FloatRoundUpward     FloatRoundingMode = 4194304
+)
+
+const (
+	FloatIsNormal          FloatClass = 4
+	FloatIsSubnormal       FloatClass = 3
+	FloatIsZero            FloatClass = 2
+	FloatIsInfinite        FloatClass = 1
+	FloatIsNaN             FloatClass = 0
+)
+
+const (
+	Termination         Signal = 15
+	InvalidMemoryAccess Signal = 11
+	Interrupt           Signal = 2
+	InvalidInstruction  Signal = 4
+	AbnormalTermination Signal = 6
+	FloatingPointError  Signal = 8
+)
+
+const (
+	AtomicBoolLockFree         = 2
+	AtomicCharLockFree         = 2
+	AtomicChar16LockFree       = 2
+	AtomicChar32LockFree       = 2
+	AtomicWcharLockFree        = 2
+	AtomicShortLockFree        = 2
+	AtomicIntLockFree          = 2
+	AtomicLongLockFree         = 2
+	AtomicLongLongLockFree     = 2
+	AtomicPointerLockFree      = 2
+)
+
+const (
+	EOF            = -1
+	MaxFiles       = 16
+	MaxFileNameLen = 4096
+	BufferSize     = 8192
+	MaxTempFiles   = 238328
+	TempNameSize   = 20
+)
+
+const (
+	FullyBuffered BufferMode = 0
+	LineBuffered  BufferMode = 1
+	Unbuffered    BufferMode = 2
+)
+
+const (
+	SeekStart   SeekMode = 0
+	SeekCurrent SeekMode = 1
+	SeekEnd     SeekMode = 2
+)
+
+type Locale struct {
+	DecimalPoint                   String
+	ThousandsSeperator             String
+	Grouping                       String
+	CurrencyName                   String
+	CurrencySymbol                 String
+	MonetaryDecimalPoint           String
+	MonetaryThousandsSeperator     String
+	MonetaryGrouping               String
+	PositiveSign                   String
+	NegativeSign                   String
+	MonetaryFractionalDigits       Char
+	FractionDigits                 Char
+	LocalCurrencyPrefixesPositive  Char
+	LocalCurrencyPositiveSpacing   Char
+	LocalCurrencyPrefixesNegative  Char
+	LocalCurrencyNegativeSpacing   Char
+	LocalCurrencyPositiveSignPos   Char
+	LocalCurrencyNegativeSignPos   Char
+	CurrencyPrefixesPositive       Char
+	CurrencyPositiveSpacing        Char
+	CurrencyPrefixesNegative       Char
+	CurrencyNegativeSpacing        Char
+	CurrencyPositiveSignPos        Char
+	CurrencyNegativeSignPos        Char
+}
+
+type NanoTime struct {
+	Seconds     Time
+	Nanoseconds Time
+}
+
+type Date struct {
+	Seconds         Int
+	Minutes         Int
+	Hours           Int
+	Days            Int
+	Months          Int
+	Years           Int
+	Weekdays        Int
+	DaysThisYear    Int
+	DaylightSavings Int
+}
+
+const (
+	LocaleAll          LocaleCategory = 6
+	LocaleCollate      LocaleCategory = 3
+	LocaleC            LocaleCategory = 0
+	LocaleMonetary     LocaleCategory = 4
+	LocaleNumeric      LocaleCategory = 1
+	LocaleTime         LocaleCategory = 2
+)
+
+const (
+UTC            TimeType = 1
+
+ClocksPerSec   Clock      = 1000000
+)
+
+type FloatingPointEnvironment [8]byte
+
+type JumpBuffer [312]byte
+
+type File [216]byte
+
+type FilePosition [16]byte
+
+const (
+	CharBits                = 8
+	MaxRuneLength           = 16
+	MinChar                 = 0
+	MaxChar                 = 255
+	MinSignedChar           = -128
+	MinShort                = -32768
+	MinInt                  = -2147483648
+	MinLong                 = -9223372036854775808
+	MinLongLong             = -9223372036854775808
+	MaxSignedChar           = 127
+	MaxShort                = 32767
+	MaxInt                  = 2147483647
+	MaxLong                 = 9223372036854775807
+	MaxLongLong             = 9223372036854775807
+	MaxUnsignedChar         = 255
+	MaxUnsignedShort        = 65535
+	MaxUnsignedInt          = 4294967295
+	MaxUnsignedLong         = 18446744073709551615
+	MaxUnsignedLongLong     = 18446744073709551615
+	MinInt8                 = -128
+	MinInt16                = -32768
+	MinInt32                = -2147483648
+	MinInt64                = -9223372036854775808
+	MinFast8                = -128
+	MinFast16               = -9223372036854775808
+	MinFast32               = -9223372036854775808
+	MinFast64               = -9223372036854775808
+	MinLeast8               = -128
+	MinLeast16              = -32768
+	MinLeast32              = -2147483648
+	MinLeast64              = -9223372036854775808
+	MinIntptr               = -9223372036854775808
+	MinIntmax               = -9223372036854775808
+	MaxInt8                 = 127
+	MaxInt16                = 32767
+	MaxInt32                = 2147483647
+	MaxInt64                = 9223372036854775807
+	MaxFastInt8             = 127
+	MaxFastInt16            = 9223372036854775807
+	MaxFastInt32            = 9223372036854775807
+	MaxFastInt64            = 9223372036854775807
+	MaxLeastInt8            = 127
+	MaxLeastInt16           = 32767
+	MaxLeastInt32           = 2147483647
+	MaxLeastInt64           = 9223372036854775807
+	MaxIntptr               = 9223372036854775807
+	MaxIntmax               = 9223372036854775807
+	MaxUint8                = 255
+	MaxUint16               = 65535
+	MaxUint32               = 4294967295
+	MaxUint64               = 18446744073709551615
+	MaxFastUint8            = 255
+	MaxFastUint16           = 18446744073709551615
+	MaxFastUint32           = 18446744073709551615
+	MaxFastUint64           = 18446744073709551615
+	MaxLeastUint8           = 255
+	MaxLeastUint16          = 65535
+	MaxLeastUint32          = 4294967295
+	MaxLeastUint64          = 18446744073709551615
+	MaxUintptr              = 18446744073709551615
+	MaxUintmax              = 18446744073709551615
+)
+
+type (
+	Bool                bool
+	Char                uint8
+	CharSigned          int8
+	CharUnsigned        uint8
+	CharWide            uint32
+	Short               int16
+	IntShort            int16
+	ShortSigned         int16
+	IntShortSigned      int16
+	ShortUnsigned       uint16
+	IntShortUnsigned    uint16
+	Int                 int32
+	Signed              int32
+	IntSigned           int32
+	Unsigned            uint32
+	IntUnsigned         uint32
+	Long                int64
+	LongInt             int64
+	LongSigned          int64
+	IntLongSigned       int64
+	LongUnsigned        uint64
+	IntLongUnsigned     uint64
+	LongLong            int64
+	IntLongLong         int64
+	LongLongSigned      int64
+	IntLongLongSigned   int64
+	LongLongUnsigned    uint64
+	IntLongLongUnsigned uint64
+	Float               float32
+	Double              float64
+	DoubleLong          [16]byte
+	ComplexFloat        [2]Float
+	ComplexDouble       [2]Double
+	ComplexDoubleLong   [2]DoubleLong
+	FastInt8            int8
+	FastInt16           int64
+	FastInt32           int64
+	FastInt64           int64
+	LeastInt8           int8
+	LeastInt16          int16
+	LeastInt32          int32
+	LeastInt64          int64
+	IntMax              int64
+	Intptr              int64
+	FastUInt8           uint8
+	FastUInt16          uint64
+	FastUInt32          uint64
+	FastUInt64          uint64
+	LeastUInt8          uint8
+	LeastUInt16         uint16
+	LeastUInt32         uint32
+	LeastUInt64         uint64
+	UIntMax             uint64
+	FastFloat          float32
+	FastDouble         float64
+	AtomicBool             atomic.Bool
+	AtomicInt              atomic.Int32
+	AtomicUnsignedInt      atomic.Uint32
+	AtomicLong             atomic.Int64
+	AtomicUnsignedLong     atomic.Uint64
+	AtomicUnsignedLongLong atomic.Uint64
+	AtomicChar32           atomic.Uint32
+	AtomicWchar            atomic.Uint32
+	AtomicIntLeast32       atomic.Int32
+	AtomicUIntLeast32      atomic.Uint32
+	AtomicIntFast32        atomic.Int64
+	AtomicUIntFast32       atomic.Uint64
+	AtomicIntFast64        atomic.Int64
+	AtomicUIntFast64       atomic.Uint64
+	AtomicUintptr          atomic.Uint64
+	AtomicSize             atomic.Uint64
+	AtomicPtrdiff          atomic.Int64
+	AtomicIntMax           atomic.Int64
+	AtomicUIntMax          atomic.Uint64
+	Size                uint64
+	StringWide          struct{uint64}
+	Ptrdiff             int64
+	Time                int64
+	Clock               int64
+)
@@ -11,6 +11,7 @@
 #include <stddef.h>
 #include <stdint.h>
 #include <stdio.h>
+#include <stdlib.h>
 #include <time.h>
 
 // we need to represent the C struct in Go
@@ -255,13 +256,13 @@ int main(int argc, char *argv[]) {
 
     printf("\tMinSignedChar           = %d\n", SCHAR_MIN);
     printf("\tMinShort                = %d\n", SHRT_MIN);
-    printf("\tMinInt                  = %d\n", INT_MIN);
+    printf("\tMinInt                  = %jd\n", (intmax_t)INT_MIN);
     printf("\tMinLong                 = %ld\n", LONG_MIN);
     printf("\tMinLongLong             = %lld\n", LLONG_MIN);
 
     printf("\tMaxSignedChar           = %d\n", SCHAR_MAX);
     printf("\tMaxShort                = %d\n", SHRT_MAX);
-    printf("\tMaxInt                  = %d\n", INT_MAX);
+    printf("\tMaxInt                  = %jd\n", (intmax_t)INT_MAX);
     printf("\tMaxLong                 = %ld\n", LONG_MAX);
     printf("\tMaxLongLong             = %lld\n", LLONG_MAX);
 
@@ -273,67 +274,67 @@ int main(int argc, char *argv[]) {
 
     printf("\tMaxUnsignedChar         = %u\n", UCHAR_MAX);
     printf("\tMaxUnsignedShort        = %u\n", USHRT_MAX);
-    printf("\tMaxUnsignedInt          = %u\n", UINT_MAX);
+    printf("\tMaxUnsignedInt          = %ju\n", (uintmax_t)UINT_MAX);
     printf("\tMaxUnsignedLong         = %lu\n", ULONG_MAX);
     printf("\tMaxUnsignedLongLong     = %llu\n", ULLONG_MAX);
 
-    printf("\tMinInt8                 = %d\n", INT8_MIN);
-    printf("\tMinInt16                = %d\n", INT16_MIN);
-    printf("\tMinInt32                = %d\n", INT32_MIN);
-    printf("\tMinInt64                = %lld\n", INT64_MIN);
-    printf("\tMinFast8                = %d\n", INT_FAST8_MIN);
-    printf("\tMinFast16               = %d\n", INT_FAST16_MIN);
-    printf("\tMinFast32               = %d\n", INT_FAST32_MIN);
-    printf("\tMinFast64               = %lld\n", INT_FAST64_MIN);
-    printf("\tMinLeast8               = %d\n", INT_LEAST8_MIN);
-    printf("\tMinLeast16              = %d\n", INT_LEAST16_MIN);
-    printf("\tMinLeast32              = %d\n", INT_LEAST32_MIN);
-    printf("\tMinLeast64              = %lld\n", INT_LEAST64_MIN);
-    printf("\tMinIntptr               = %ld\n", INTPTR_MIN);
-    printf("\tMinIntmax               = %ld\n", INTMAX_MIN);
-
-    printf("\tMaxInt8                 = %d\n", INT8_MAX);
-    printf("\tMaxInt16                = %d\n", INT16_MAX);
-    printf("\tMaxInt32                = %d\n", INT32_MAX);
-    printf("\tMaxInt64                = %lld\n", INT64_MAX);
-    printf("\tMaxFastInt8             = %d\n", INT_FAST8_MAX);
-    printf("\tMaxFastInt16            = %d\n", INT_FAST16_MAX);
-    printf("\tMaxFastInt32            = %d\n", INT_FAST32_MAX);
-    printf("\tMaxFastInt64            = %lld\n", INT_FAST64_MAX);
-    printf("\tMaxLeastInt8            = %d\n", INT_LEAST8_MAX);
-    printf("\tMaxLeastInt16           = %d\n", INT_LEAST16_MAX);
-    printf("\tMaxLeastInt32           = %d\n", INT_LEAST32_MAX);
-    printf("\tMaxLeastInt64           = %lld\n", INT_LEAST64_MAX);
-    printf("\tMaxIntptr               = %ld\n", INTPTR_MAX);
-    printf("\tMaxIntmax               = %ld\n", INTMAX_MAX);
-
-    printf("\tMaxUint8                = %u\n", UINT8_MAX);
-    printf("\tMaxUint16               = %u\n", UINT16_MAX);
-    printf("\tMaxUint32               = %u\n", UINT32_MAX);
-    printf("\tMaxUint64               = %llu\n", UINT64_MAX);
-    printf("\tMaxFastUint8            = %u\n", UINT_FAST8_MAX);
-    printf("\tMaxFastUint16           = %u\n", UINT_FAST16_MAX);
-    printf("\tMaxFastUint32           = %u\n", UINT_FAST32_MAX);
-    printf("\tMaxFastUint64           = %llu\n", UINT_FAST64_MAX);
-    printf("\tMaxLeastUint8           = %u\n", UINT_LEAST8_MAX);
-    printf("\tMaxLeastUint16          = %u\n", UINT_LEAST16_MAX);
-    printf("\tMaxLeastUint32          = %u\n", UINT_LEAST32_MAX);
-    printf("\tMaxLeastUint64          = %llu\n", UINT_LEAST64_MAX);
-    printf("\tMaxUintptr              = %lu\n", UINTPTR_MAX);
-    printf("\tMaxUintmax              = %lu\n", UINTMAX_MAX);
+    printf("\tMinInt8                 = %jd\n", (intmax_t)INT8_MIN);
+    printf("\tMinInt16                = %jd\n", (intmax_t)INT16_MIN);
+    printf("\tMinInt32                = %jd\n", (intmax_t)INT32_MIN);
+    printf("\tMinInt64                = %jd\n", (intmax_t)INT64_MIN);
+    printf("\tMinFast8                = %jd\n", (intmax_t)INT_FAST8_MIN);
+    printf("\tMinFast16               = %jd\n", (intmax_t)INT_FAST16_MIN);
+    printf("\tMinFast32               = %jd\n", (intmax_t)INT_FAST32_MIN);
+    printf("\tMinFast64               = %jd\n", (intmax_t)INT_FAST64_MIN);
+    printf("\tMinLeast8               = %jd\n", (intmax_t)INT_LEAST8_MIN);
+    printf("\tMinLeast16              = %jd\n", (intmax_t)INT_LEAST16_MIN);
+    printf("\tMinLeast32              = %jd\n", (intmax_t)INT_LEAST32_MIN);
+    printf("\tMinLeast64              = %jd\n", (intmax_t)INT_LEAST64_MIN);
+    printf("\tMinIntptr               = %jd\n", (intmax_t)INTPTR_MIN);
+    printf("\tMinIntmax               = %jd\n", (intmax_t)INTMAX_MIN);
+
+    printf("\tMaxInt8                 = %jd\n", (intmax_t)INT8_MAX);
+    printf("\tMaxInt16                = %jd\n", (intmax_t)INT16_MAX);
+    printf("\tMaxInt32                = %jd\n", (intmax_t)INT32_MAX);
+    printf("\tMaxInt64                = %jd\n", (intmax_t)INT64_MAX);
+    printf("\tMaxFastInt8             = %jd\n", (intmax_t)INT_FAST8_MAX);
+    printf("\tMaxFastInt16            = %jd\n", (intmax_t)INT_FAST16_MAX);
+    printf("\tMaxFastInt32            = %jd\n", (intmax_t)INT_FAST32_MAX);
+    printf("\tMaxFastInt64            = %jd\n", (intmax_t)INT_FAST64_MAX);
+    printf("\tMaxLeastInt8            = %jd\n", (intmax_t)INT_LEAST8_MAX);
+    printf("\tMaxLeastInt16           = %jd\n", (intmax_t)INT_LEAST16_MAX);
+    printf("\tMaxLeastInt32           = %jd\n", (intmax_t)INT_LEAST32_MAX);
+    printf("\tMaxLeastInt64           = %jd\n", (intmax_t)INT_LEAST64_MAX);
+    printf("\tMaxIntptr               = %jd\n", (intmax_t)INTPTR_MAX);
+    printf("\tMaxIntmax               = %jd\n", (intmax_t)INTMAX_MAX);
+
+    printf("\tMaxUint8                = %ju\n", (uintmax_t)UINT8_MAX);
+    printf("\tMaxUint16               = %ju\n", (uintmax_t)UINT16_MAX);
+    printf("\tMaxUint32               = %ju\n", (uintmax_t)UINT32_MAX);
+    printf("\tMaxUint64               = %ju\n", (uintmax_t)UINT64_MAX);
+    printf("\tMaxFastUint8            = %ju\n", (uintmax_t)UINT_FAST8_MAX);
+    printf("\tMaxFastUint16           = %ju\n", (uintmax_t)UINT_FAST16_MAX);
+    printf("\tMaxFastUint32           = %ju\n", (uintmax_t)UINT_FAST32_MAX);
+    printf("\tMaxFastUint64           = %ju\n", (uintmax_t)UINT_FAST64_MAX);
+    printf("\tMaxLeastUint8           = %ju\n", (uintmax_t)UINT_LEAST8_MAX);
+    printf("\tMaxLeastUint16          = %ju\n", (uintmax_t)UINT_LEAST16_MAX);
+    printf("\tMaxLeastUint32          = %ju\n", (uintmax_t)UINT_LEAST32_MAX);
+    printf("\tMaxLeastUint64          = %ju\n", (uintmax_t)UINT_LEAST64_MAX);
+    printf("\tMaxUintptr              = %ju\n", (uintmax_t)UINTPTR_MAX);
+    printf("\tMaxUintmax              = %ju\n", (uintmax_t)UINTMAX_MAX);
 
 
     printf(")\n\n");
 
     printf("type (\n");
     printf("\tBool                bool\n");
-    printf("\tChar                int%d\n", (unsigned)sizeof(char)*CHAR_BIT);
+    printf("\tChar                %sint%d\n", CHAR_MIN == 0 ? "u" : "", (unsigned)sizeof(char)*CHAR_BIT);
 
     printf("\tCharSigned          int%d\n", (unsigned)sizeof(signed char)*CHAR_BIT);
 
     printf("\tCharUnsigned        uint%d\n", (unsigned)sizeof(unsigned char)*CHAR_BIT);
 
-    printf("\tCharWide            int%d\n", (unsigned)sizeof(wchar_t)*CHAR_BIT);
+    printf("\tCharWide            %sint%d\n", WCHAR_MIN == 0 ? "u" : "", (unsigned)sizeof(wchar_t)*CHAR_BIT);
 
     printf("\tShort               int%d\n", (unsigned)sizeof(short) * CHAR_BIT);
     printf("\tIntShort            int%d\n", (unsigned)sizeof(short int) * CHAR_BIT);
@@ -403,8 +404,11 @@ int main(int argc, char *argv[]) {
 
     printf("\tUIntMax             uint%d\n", (unsigned)sizeof(uintmax_t) * CHAR_BIT);
 
-    printf("\tFastFloat          float%d\n", (unsigned)sizeof(float_t) * CHAR_BIT);
-    printf("\tFastDouble         float%d\n", (unsigned)sizeof(double_t) * CHAR_BIT);
+    // float_t and double_t are long double where FLT_EVAL_METHOD is 2, such as on the x87.
+    if (sizeof(float_t) > sizeof(double)) printf("\tFastFloat          DoubleLong\n");
+    else printf("\tFastFloat          float%d\n", (unsigned)sizeof(float_t) * CHAR_BIT);
+    if (sizeof(double_t) > sizeof(double)) printf("\tFastDouble         DoubleLong\n");
+    else printf("\tFastDouble         float%d\n", (unsigned)sizeof(double_t) * CHAR_BIT);
 
     printf("\tAtomicBool             atomic.Bool\n");
     //printf("\tAtomicChar             atomic.Int%d\n", (unsigned)sizeof(atomic_char) * CHAR_BIT);
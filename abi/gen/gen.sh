@@ -0,0 +1,54 @@
+#!/bin/sh
+# gen.sh writes ../abi_$GOOS_$GOARCH.go by compiling and running gen.c
+# for the target given by GOOS and GOARCH, which go generate sets, so
+# that the tables for another target are generated with:
+#
+#	GOARCH=arm64 go generate qlova.tech/abi
+#
+# Targets other than the host are compiled with a cross compiler, $CC
+# or else the GNU cross compiler for the target, into a static binary,
+# which is run under qemu-user, $QEMU or else qemu-<arch>, unless the
+# host can run it, so that no hardware of the target is needed, for
+# example, on Debian:
+#
+#	apt install gcc-aarch64-linux-gnu gcc-i686-linux-gnu qemu-user
+set -e
+
+cd "$(dirname "$0")"
+
+GOOS=${GOOS:-$(go env GOOS)}
+GOARCH=${GOARCH:-$(go env GOARCH)}
+target=${GOOS}_${GOARCH}
+host=$(go env GOHOSTOS)_$(go env GOHOSTARCH)
+
+case $target in
+linux_amd64) triple=x86_64-linux-gnu qemu=qemu-x86_64 ;;
+linux_arm64) triple=aarch64-linux-gnu qemu=qemu-aarch64 ;;
+linux_386) triple=i686-linux-gnu qemu=qemu-i386 ;;
+darwin_arm64) triple= qemu= ;;
+*)
+	echo "gen.sh: unsupported target $target" >&2
+	exit 1
+	;;
+esac
+
+bin=$(mktemp)
+trap 'rm -f "$bin" "../abi_$target.go.tmp"' EXIT
+
+if [ "$target" = "$host" ]; then
+	${CC:-cc} -o "$bin" gen.c -lm
+	run=
+else
+	if [ -z "$triple" ]; then
+		echo "gen.sh: $target can only be generated on $target" >&2
+		exit 1
+	fi
+	${CC:-$triple-gcc} -static -o "$bin" gen.c -lm
+	run=${QEMU:-$qemu}
+	if [ "$target" = linux_386 ] && [ "$host" = linux_amd64 ]; then
+		run= # 32-bit binaries run natively.
+	fi
+fi
+
+$run "$bin" > "../abi_$target.go.tmp"
+mv "../abi_$target.go.tmp" "../abi_$target.go"
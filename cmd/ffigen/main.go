@@ -0,0 +1,625 @@
+// Command ffigen generates static bindings for the ffi.Library structs
+// of a Go package, such that their funcs call straight into C without
+// any reflection. It is intended to be run with go generate:
+//
+//	//go:generate go run qlova.tech/cmd/ffigen
+//
+// The bindings are written to ffigen.go and are only built with the
+// ffistatic build tag, without it, the package is linked by reflection
+// as usual. Funcs whose last result is an error are bound statically,
+// with their err= condition, if the rest of their signature can be.
+// Funcs with signatures that cannot be bound statically, but that can
+// be linked by reflection, such as variadic funcs, callbacks, structs
+// passed by value and out-params, are listed in the generated file and
+// left to reflection, as are funcs with out= positions and since
+// versions. Signatures that cannot be linked either way, such as those
+// with long double parameters, are errors, unless the field is tagged
+// with `ffigen:"-"`, for example:
+//
+//	RoundLong func(abi.DoubleLong) abi.Long `ffi:"lroundl" ffigen:"-"`
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ffiPath = "qlova.tech/ffi"
+	abiPath = "qlova.tech/abi"
+)
+
+var (
+	output = flag.String("o", "ffigen.go", "output file name")
+	tag    = flag.String("tag", "ffistatic", "build tag that selects the generated bindings")
+)
+
+func main() {
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	src, err := generate(dir, *tag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ffigen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(dir, *output), src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ffigen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the static bindings for the package in dir.
+func generate(dir, tag string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkg, err := load(fset, dir)
+	if err != nil {
+		return nil, err
+	}
+	g := generator{fset: fset, pkg: pkg, imports: map[string]string{ffiPath: "ffi", "unsafe": "unsafe"}}
+	var names []string
+	for _, name := range pkg.Scope().Names() {
+		if v, ok := pkg.Scope().Lookup(name).(*types.Var); ok && isLibrary(v.Type()) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no ffi.Library structs in %v", pkg.Path())
+	}
+	// keep the libraries in source order.
+	sort.Slice(names, func(i, j int) bool {
+		return pkg.Scope().Lookup(names[i]).Pos() < pkg.Scope().Lookup(names[j]).Pos()
+	})
+	var body bytes.Buffer
+	for _, name := range names {
+		g.library(&body, name, pkg.Scope().Lookup(name).Type().Underlying().(*types.Struct))
+	}
+	if len(g.unsupported) > 0 {
+		return nil, fmt.Errorf("unsupported signatures, tag these fields with ffigen:\"-\" to skip them:\n%v", strings.Join(g.unsupported, "\n"))
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by ffigen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "//go:build %v\n\n", tag)
+	fmt.Fprintf(&out, "package %v\n\n", pkg.Name())
+	fmt.Fprintf(&out, "import (\n")
+	var paths []string
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if name := g.imports[path]; name != filepath.Base(path) {
+			fmt.Fprintf(&out, "\t%v %q\n", name, path)
+		} else {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// load type checks the package in dir, excluding any
+// previously generated bindings.
+func load(fset *token.FileSet, dir string) (*types.Package, error) {
+	ctx := build.Default
+	bpkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, name := range bpkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	path := bpkg.ImportPath
+	if path == "" || path == "." {
+		path = bpkg.Name
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return conf.Check(path, fset, files, nil)
+}
+
+// isLibrary reports whether t is a struct whose first
+// field is, or leads to, an embedded ffi.Library.
+func isLibrary(t types.Type) bool {
+	for {
+		s, ok := t.Underlying().(*types.Struct)
+		if !ok || s.NumFields() == 0 || !s.Field(0).Embedded() {
+			return false
+		}
+		t = s.Field(0).Type()
+		if named, ok := t.(*types.Named); ok {
+			obj := named.Obj()
+			if obj.Pkg() != nil && obj.Pkg().Path() == ffiPath && obj.Name() == "Library" {
+				return true
+			}
+		}
+	}
+}
+
+type generator struct {
+	fset    *token.FileSet
+	pkg     *types.Package
+	imports map[string]string // import path to package name.
+
+	unsupported []string // fields that cannot be bound, with their position.
+}
+
+// qualifier qualifies types from other packages, recording their import.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+// describe returns the name of t for messages, without importing it.
+func (g *generator) describe(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == g.pkg {
+			return ""
+		}
+		return pkg.Name()
+	})
+}
+
+// library writes the static bindings for the library variable name.
+func (g *generator) library(w *bytes.Buffer, name string, s *types.Struct) {
+	var bound bytes.Buffer
+	var skipped []string
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		sig, ok := field.Type().Underlying().(*types.Signature)
+		if !ok || field.Embedded() || !field.Exported() {
+			continue
+		}
+		if outParams(s.Tag(i)) {
+			skipped = append(skipped, fmt.Sprintf("//   - %v: out= positions", field.Name()))
+			continue
+		}
+		if reflect.StructTag(s.Tag(i)).Get("since") != "" {
+			skipped = append(skipped, fmt.Sprintf("//   - %v: since version", field.Name()))
+			continue
+		}
+		imports := maps.Clone(g.imports)
+		binding, err := g.binding(name, field.Name(), sig, s, s.Tag(i))
+		if err != nil {
+			g.imports = imports // of a binding that is not generated.
+		}
+		if reflect.StructTag(s.Tag(i)).Get("ffigen") == "-" {
+			reason := `ffigen:"-"`
+			if err != nil {
+				reason = err.Error()
+			}
+			skipped = append(skipped, fmt.Sprintf("//   - %v: %v", field.Name(), reason))
+			continue
+		}
+		if err != nil {
+			if rerr := g.reflectable(sig); rerr != nil {
+				g.unsupported = append(g.unsupported, fmt.Sprintf("%v: %v.%v: %v", g.fset.Position(field.Pos()), name, field.Name(), rerr))
+				continue
+			}
+			skipped = append(skipped, fmt.Sprintf("//   - %v: %v", field.Name(), err))
+			continue
+		}
+		fmt.Fprintf(&bound, "%q: func(symbol unsafe.Pointer) {\n%v\n},\n", field.Name(), binding)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(w, "// %v is linked by reflection for:\n//\n%v\n", name, strings.Join(skipped, "\n"))
+	}
+	fmt.Fprintf(w, "func init() {\nffi.Static(&%v, map[string]func(symbol unsafe.Pointer){\n%v})\n}\n\n", name, bound.String())
+}
+
+// outParams reports whether the struct tag of a func field places
+// its out-params with an out= option, these are not generated.
+func outParams(tag string) bool {
+	for _, part := range strings.Split(reflect.StructTag(tag).Get("ffi"), ",") {
+		if strings.HasPrefix(strings.TrimSpace(part), "out=") {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolOf returns the first symbol named by the struct tag of a
+// func field, and its err= condition, as parsed by the ffi package.
+func symbolOf(field, tag string) (symbol, cond string) {
+	for _, part := range strings.Split(reflect.StructTag(tag).Get("ffi"), ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "", part == "optional", part == "keep":
+		case strings.HasPrefix(part, "err="):
+			cond = strings.TrimPrefix(part, "err=")
+		case strings.Contains(part, "="), strings.Trim(part, "0123456789") == "":
+		case symbol == "":
+			symbol = part
+		}
+	}
+	if symbol == "" {
+		symbol = field
+	}
+	return symbol, cond
+}
+
+// conversion describes how a Go type is passed as an [ffi.Word].
+type conversion struct {
+	word string              // the word type.
+	in   func(string) string // converts a Go expression into a word.
+	out  func(string) string // converts a word variable into the Go type.
+}
+
+// binding returns the statement that binds the field of the library
+// lib, a struct s, to symbol.
+func (g *generator) binding(lib, name string, sig *types.Signature, s *types.Struct, tag string) (string, error) {
+	field := lib + "." + name
+	if sig.Variadic() {
+		return "", fmt.Errorf("variadic")
+	}
+	if sig.Params().Len() > 6 {
+		return "", fmt.Errorf("more than 6 parameters")
+	}
+	results := sig.Results().Len()
+	fails := results > 0 && isError(sig.Results().At(results-1).Type())
+	if fails {
+		results--
+		if results == 0 {
+			return "", fmt.Errorf("no C result for its error")
+		}
+	}
+	if results > 1 {
+		return "", fmt.Errorf("more than one result")
+	}
+	var (
+		params []conversion
+		direct = !fails
+	)
+	for i := 0; i < sig.Params().Len(); i++ {
+		conv, err := g.conversion(sig.Params().At(i).Type(), false)
+		if err != nil {
+			return "", fmt.Errorf("parameter %v: %w", i, err)
+		}
+		direct = direct && conv.in == nil
+		params = append(params, conv)
+	}
+	var result *conversion
+	if results == 1 {
+		conv, err := g.conversion(sig.Results().At(0).Type(), true)
+		if err != nil {
+			return "", fmt.Errorf("result: %w", err)
+		}
+		direct = direct && conv.out == nil
+		result = &conv
+	}
+	var failed, failure string
+	if fails {
+		symbol, cond := symbolOf(name, tag)
+		var err error
+		if failed, err = g.failure(sig.Results().At(0).Type(), result.word, cond); err != nil {
+			return "", err
+		}
+		describe := "nil"
+		if g.describes(s) {
+			describe = lib + ".Error"
+		}
+		failure = fmt.Sprintf("ffi.Failure(%v, errno, %q)", describe, symbol)
+	}
+
+	var helper strings.Builder
+	var words []string
+	for _, param := range params {
+		words = append(words, param.word)
+	}
+	switch {
+	case fails:
+		words = append(words, result.word)
+		fmt.Fprintf(&helper, "ffi.FuncErrno%d", len(params))
+	case result != nil:
+		words = append(words, result.word)
+		fmt.Fprintf(&helper, "ffi.Func%d", len(params))
+	default:
+		fmt.Fprintf(&helper, "ffi.Proc%d", len(params))
+	}
+	if len(words) > 0 {
+		fmt.Fprintf(&helper, "[%v]", strings.Join(words, ", "))
+	}
+	if direct {
+		return fmt.Sprintf("%v = %v(symbol)", field, helper.String()), nil
+	}
+
+	var (
+		decl []string
+		args []string
+	)
+	for i, param := range params {
+		name := "a" + strconv.Itoa(i)
+		decl = append(decl, name+" "+g.typeString(sig.Params().At(i).Type()))
+		if param.in != nil {
+			args = append(args, param.in(name))
+		} else {
+			args = append(args, name)
+		}
+	}
+	var fn strings.Builder
+	fmt.Fprintf(&fn, "call := %v(symbol)\n", helper.String())
+	fmt.Fprintf(&fn, "%v = func(%v)", field, strings.Join(decl, ", "))
+	call := "call(" + strings.Join(args, ", ") + ")"
+	switch {
+	case result == nil:
+		fmt.Fprintf(&fn, " {\n%v\n}", call)
+	case fails:
+		value := "r"
+		if result.out != nil {
+			value = result.out("r")
+		}
+		fmt.Fprintf(&fn, " (%v, error) {\nr, errno := %v\nif %v {\nreturn %v, %v\n}\nreturn %v, nil\n}", g.typeString(sig.Results().At(0).Type()), call, failed, value, failure, value)
+	case result.out != nil:
+		fmt.Fprintf(&fn, " %v {\nr := %v\nreturn %v\n}", g.typeString(sig.Results().At(0).Type()), call, result.out("r"))
+	default:
+		fmt.Fprintf(&fn, " %v {\nreturn %v\n}", g.typeString(sig.Results().At(0).Type()), call)
+	}
+	return fn.String(), nil
+}
+
+// failure returns the condition on the C result r, of the given word
+// type, and errno, under which a call fails, for the err= condition
+// cond of a func whose first result has type t, see [ffi.Set].
+func (g *generator) failure(t types.Type, word, cond string) (string, error) {
+	zero := "0"
+	switch word {
+	case "unsafe.Pointer":
+		zero = "nil"
+	case "bool":
+		zero = "false"
+	}
+	switch cond {
+	case "":
+		return "r == " + zero, nil
+	case "nil":
+		switch t.Underlying().(type) {
+		case *types.Pointer, *types.Struct:
+		default:
+			if word != "unsafe.Pointer" {
+				return "", fmt.Errorf("%v is not a pointer for err=nil", g.describe(t))
+			}
+		}
+		return "r == nil", nil
+	case "-1":
+		if basic, ok := t.Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 || basic.Info()&types.IsUnsigned != 0 {
+			return "", fmt.Errorf("%v is not a signed integer for err=-1", g.describe(t))
+		}
+		return "r == -1", nil
+	case "nonzero":
+		return "r != " + zero, nil
+	case "errno":
+		return "errno != 0", nil
+	default:
+		return "", fmt.Errorf("unknown err=%v condition", cond)
+	}
+}
+
+// describes reports whether the library struct s has an Error func
+// that describes failures, see [ffi.Set].
+func (g *generator) describes(s *types.Struct) bool {
+	obj, _, _ := types.LookupFieldOrMethod(s, false, g.pkg, "Error")
+	field, ok := obj.(*types.Var)
+	if !ok || !field.IsField() {
+		return false
+	}
+	sig, ok := field.Type().Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	basic, ok := sig.Results().At(0).Type().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.String
+}
+
+// isError reports whether t is the error interface.
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// conversion returns how values of type t are passed to, or returned
+// from C as a single word, or an error if they cannot be.
+func (g *generator) conversion(t types.Type, result bool) (conversion, error) {
+	name := g.typeString(t)
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Kind() == types.UnsafePointer, u.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat) != 0:
+			return conversion{word: name}, nil
+		case u.Info()&types.IsString != 0:
+			abi := g.abi()
+			conv := conversion{word: "unsafe.Pointer"}
+			if result {
+				conv.out = func(r string) string {
+					return fmt.Sprintf("%v((*(*%v.String)(unsafe.Pointer(&%v))).String())", name, abi, r)
+				}
+			} else {
+				conv.in = func(v string) string {
+					return fmt.Sprintf("%v.NewString(string(%v)).Pointer()", abi, v)
+				}
+			}
+			return conv, nil
+		}
+	case *types.Pointer:
+		return conversion{
+			word: "unsafe.Pointer",
+			in:   func(v string) string { return "unsafe.Pointer(" + v + ")" },
+			out:  func(r string) string { return "(" + name + ")(" + r + ")" },
+		}, nil
+	case *types.Struct:
+		if g.isPointerLike(t) {
+			return conversion{
+				word: "unsafe.Pointer",
+				in:   func(v string) string { return "*(*unsafe.Pointer)(unsafe.Pointer(&" + v + "))" },
+				out:  func(r string) string { return "*(*" + name + ")(unsafe.Pointer(&" + r + "))" },
+			}, nil
+		}
+		return conversion{}, fmt.Errorf("%v is passed by value", name)
+	case *types.Array:
+		return conversion{}, fmt.Errorf("%v is an array", name)
+	case *types.Signature:
+		return conversion{}, fmt.Errorf("%v is a callback", name)
+	}
+	return conversion{}, fmt.Errorf("unsupported type %v", name)
+}
+
+// reflectable returns an error if a func field of the given signature,
+// which cannot be bound statically, cannot be linked by reflection
+// either, see [ffi.Set].
+func (g *generator) reflectable(sig *types.Signature) error {
+	results := sig.Results().Len()
+	fails := results > 0 && isError(sig.Results().At(results-1).Type())
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		if i == 0 && g.isContext(t) {
+			if !fails {
+				return fmt.Errorf("parameter 0: %v without an error result", g.describe(t))
+			}
+			continue
+		}
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			t = t.(*types.Slice).Elem()
+			if _, ok := t.Underlying().(*types.Interface); ok {
+				continue // each argument is passed by its dynamic type.
+			}
+		}
+		if err := g.reflected(t); err != nil {
+			return fmt.Errorf("parameter %v: %w", i, err)
+		}
+	}
+	for i := 0; i < results; i++ {
+		if fails && i == results-1 {
+			break
+		}
+		if err := g.reflected(sig.Results().At(i).Type()); err != nil {
+			return fmt.Errorf("result %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// reflected returns an error if values of type t cannot be passed to,
+// or returned from C by reflection.
+func (g *generator) reflected(t types.Type) error {
+	name := g.describe(t)
+	if g.isLongDouble(t) {
+		return fmt.Errorf("%v is a long double, which is not supported", name)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Kind() == types.UnsafePointer || u.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) != 0 {
+			return nil
+		}
+	case *types.Pointer, *types.Slice:
+		return nil
+	case *types.Array:
+		return g.reflected(u.Elem())
+	case *types.Struct:
+		if g.isPointerLike(t) {
+			return nil
+		}
+		for i := 0; i < u.NumFields(); i++ {
+			if err := g.reflected(u.Field(i).Type()); err != nil {
+				return fmt.Errorf("%v: %w", name, err)
+			}
+		}
+		return nil
+	case *types.Signature:
+		if err := g.reflectable(u); err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported type %v", name)
+}
+
+// isLongDouble reports whether t is, or contains, abi.DoubleLong,
+// where it is represented as an array of bytes.
+func (g *generator) isLongDouble(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == abiPath && obj.Name() == "DoubleLong" {
+			_, ok := t.Underlying().(*types.Array)
+			return ok
+		}
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Array:
+		return g.isLongDouble(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if g.isLongDouble(u.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isContext reports whether t is context.Context.
+func (g *generator) isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// abi returns the name of the abi package, importing it.
+func (g *generator) abi() string {
+	if g.pkg.Path() == abiPath {
+		panic("ffigen: cannot generate bindings for the abi package")
+	}
+	if name, ok := g.imports[abiPath]; ok {
+		return name
+	}
+	g.imports[abiPath] = "abi"
+	return "abi"
+}
+
+// isPointerLike reports whether t is represented as a single C
+// pointer, ie. abi.String or a struct implementing abi.IsPointer.
+func (g *generator) isPointerLike(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == abiPath && obj.Name() == "String" {
+			return true
+		}
+	}
+	method, _, _ := types.LookupFieldOrMethod(t, false, nil, "Pointer")
+	fn, ok := method.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	basic, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && basic.Kind() == types.Uintptr && types.NewMethodSet(t).Lookup(fn.Pkg(), "Pointer") != nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, dir := range []string{"../../lib/std", "../../lib/sdl/v2"} {
+		src, err := generate(dir, "ffistatic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		existing, err := os.ReadFile(filepath.Join(dir, "ffigen.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, existing) {
+			t.Errorf("%v/ffigen.go is out of date, run go generate", dir)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	_, err := generate("testdata/unsupported", "ffistatic")
+	if err == nil {
+		t.Fatal("expected unsupported signatures to be an error")
+	}
+	msg := err.Error()
+	for _, field := range []string{"Lib.Getenv: parameter 0: unsupported type map[string]string", "Lib.Setenv: parameter 2: unsupported type chan int32"} {
+		if !strings.Contains(msg, field) {
+			t.Errorf("expected %q to be reported, got %v", field, msg)
+		}
+	}
+	for _, field := range []string{"Lib.Strlen", "Lib.Printf", "Lib.Atexit", "Lib.Qsort"} {
+		if strings.Contains(msg, field) {
+			t.Errorf("%v should not be reported, got %v", field, msg)
+		}
+	}
+}
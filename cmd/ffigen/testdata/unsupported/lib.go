@@ -0,0 +1,14 @@
+package unsupported
+
+import "qlova.tech/ffi"
+
+var Lib struct {
+	ffi.Library `linux:"libc.so.6"`
+
+	Strlen func(string) uintptr             `ffi:"strlen"`
+	Printf func(string, ...any) int32       `ffi:"printf"`
+	Atexit func(func()) int32               `ffi:"atexit,keep"`
+	Getenv func(map[string]string) *byte    `ffi:"getenv"`
+	Setenv func(string, string, chan int32) `ffi:"setenv"`
+	Qsort  func(uintptr, uintptr, chan int) `ffi:"qsort" ffigen:"-"`
+}
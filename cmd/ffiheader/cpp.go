@@ -0,0 +1,582 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tIdent tokenKind = iota
+	tNumber
+	tString
+	tChar
+	tPunct
+	tComment
+)
+
+// token of C source.
+type token struct {
+	kind  tokenKind
+	text  string
+	file  string
+	line  int
+	bol   bool // first token on its line.
+	space bool // preceded by whitespace.
+}
+
+func (t token) is(text string) bool { return t.kind == tPunct && t.text == text }
+
+// puncts are the multi-character punctuators, longest first.
+var puncts = []string{
+	"...", "<<=", ">>=",
+	"->", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||", "##",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=",
+}
+
+// lex splits C source into tokens, comments are kept so
+// that they can be used as documentation.
+func lex(file string, src string) []token {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	var (
+		tokens []token
+		line   = 1
+		bol    = true
+		space  = false
+	)
+	emit := func(kind tokenKind, text string) {
+		tokens = append(tokens, token{kind: kind, text: text, file: file, line: line, bol: bol, space: space})
+		bol, space = false, false
+		line += strings.Count(text, "\n")
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src) && src[i+1] == '\n':
+			i += 2
+			line++
+		case c == '\n':
+			i++
+			line++
+			bol, space = true, true
+		case c == ' ' || c == '\t' || c == '\f' || c == '\v' || c == '\r':
+			i++
+			space = true
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			emit(tComment, src[i:i+end])
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			text := src[i:]
+			if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+				text = src[i : i+2+end+2]
+			}
+			emit(tComment, text)
+			i += len(text)
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && isIdent(src[j]) {
+				j++
+			}
+			emit(tIdent, src[i:j])
+			i = j
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i + 1
+			for j < len(src) {
+				if isIdent(src[j]) || src[j] == '.' {
+					j++
+				} else if (src[j] == '+' || src[j] == '-') && strings.ContainsRune("eEpP", rune(src[j-1])) {
+					j++
+				} else {
+					break
+				}
+			}
+			emit(tNumber, src[i:j])
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(src) {
+				j++
+			}
+			kind := tString
+			if c == '\'' {
+				kind = tChar
+			}
+			emit(kind, src[i:j])
+			i = j
+		default:
+			text := src[i : i+1]
+			for _, p := range puncts {
+				if strings.HasPrefix(src[i:], p) {
+					text = p
+					break
+				}
+			}
+			emit(tPunct, text)
+			i += len(text)
+		}
+	}
+	return tokens
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdent(c byte) bool { return isIdentStart(c) || isDigit(c) }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// macro is a preprocessor definition.
+type macro struct {
+	function bool
+	params   []string
+	variadic bool
+	body     []token
+
+	name string
+	at   token  // the position of the definition.
+	doc  string // comments before, or on the same line as the definition.
+}
+
+// condition is an entry of the #if stack.
+type condition struct {
+	active bool // the current branch is being processed.
+	taken  bool // a branch has been taken.
+	outer  bool // the enclosing section is active.
+}
+
+// preprocessor expands macros, includes and conditionals,
+// headers that cannot be found are skipped, such that the
+// system headers do not need to be present.
+type preprocessor struct {
+	macros  map[string]*macro
+	defined []*macro // in order of definition.
+	include []string
+	once    map[string]bool
+	depth   int
+	out     []token
+}
+
+// predefined macros of a 64-bit GCC compatible compiler, along with
+// the limits of the (usually missing) standard headers, so that
+// headers take the same branches as they would with a C compiler.
+var predefined = []string{
+	"__STDC__=1", "__GNUC__=4", "__LP64__=1", "__CHAR_BIT__=8",
+	"CHAR_BIT=8", "SCHAR_MAX=127", "UCHAR_MAX=255",
+	"SHRT_MAX=32767", "USHRT_MAX=65535",
+	"INT_MAX=2147483647", "UINT_MAX=4294967295U",
+	"LONG_MAX=9223372036854775807L", "ULONG_MAX=18446744073709551615UL",
+	"NULL=((void*)0)",
+}
+
+// platform returns the predefined macros of the host platform.
+func platform() []string {
+	var defines []string
+	switch runtime.GOOS {
+	case "linux":
+		defines = append(defines, "__linux__=1", "__unix__=1")
+	case "darwin":
+		defines = append(defines, "__APPLE__=1", "__MACH__=1")
+	case "windows":
+		defines = append(defines, "_WIN32=1", "_WIN64=1")
+	}
+	switch runtime.GOARCH {
+	case "amd64":
+		defines = append(defines, "__x86_64__=1")
+	case "arm64":
+		defines = append(defines, "__aarch64__=1")
+	}
+	return defines
+}
+
+func newPreprocessor(include []string, defines []string) *preprocessor {
+	pp := &preprocessor{macros: make(map[string]*macro), include: include, once: make(map[string]bool)}
+	defines = append(append(append([]string(nil), predefined...), platform()...), defines...)
+	for _, define := range defines {
+		name, value, _ := strings.Cut(define, "=")
+		if value == "" {
+			value = "1"
+		}
+		pp.macros[name] = &macro{body: lex("<define>", value)}
+	}
+	return pp
+}
+
+// file preprocesses the named file, appending the result to out.
+func (pp *preprocessor) file(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if pp.once[abs] {
+		return nil
+	}
+	if pp.depth > 64 {
+		return fmt.Errorf("%v: includes nested too deeply", path)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pp.depth++
+	defer func() { pp.depth-- }()
+
+	tokens := lex(path, string(src))
+	var (
+		stack   []condition
+		pending []token
+	)
+	active := func() bool { return len(stack) == 0 || stack[len(stack)-1].active }
+	flush := func() {
+		pp.out = append(pp.out, pp.expand(pending, nil)...)
+		pending = pending[:0]
+	}
+	for i := 0; i < len(tokens); {
+		if !tokens[i].bol || !tokens[i].is("#") {
+			if active() {
+				pending = append(pending, tokens[i])
+			}
+			i++
+			continue
+		}
+		// directive, up until the end of the line.
+		j := i + 1
+		var line, comments []token
+		for j < len(tokens) && !tokens[j].bol {
+			if tokens[j].kind == tComment {
+				comments = append(comments, tokens[j])
+			} else {
+				line = append(line, tokens[j])
+			}
+			j++
+		}
+		i = j
+		if len(line) == 0 {
+			continue
+		}
+		flush()
+		name, args := line[0].text, line[1:]
+		switch name {
+		case "if", "ifdef", "ifndef":
+			outer := active()
+			cond := false
+			if outer {
+				switch name {
+				case "if":
+					cond = pp.eval(args) != 0
+				case "ifdef":
+					cond = len(args) > 0 && pp.macros[args[0].text] != nil
+				case "ifndef":
+					cond = len(args) > 0 && pp.macros[args[0].text] == nil
+				}
+			}
+			stack = append(stack, condition{active: outer && cond, taken: cond, outer: outer})
+		case "elif":
+			if len(stack) == 0 {
+				return fmt.Errorf("%v:%v: #elif without #if", path, line[0].line)
+			}
+			top := &stack[len(stack)-1]
+			top.active = false
+			if top.outer && !top.taken && pp.eval(args) != 0 {
+				top.active, top.taken = true, true
+			}
+		case "else":
+			if len(stack) == 0 {
+				return fmt.Errorf("%v:%v: #else without #if", path, line[0].line)
+			}
+			top := &stack[len(stack)-1]
+			top.active = top.outer && !top.taken
+			top.taken = true
+		case "endif":
+			if len(stack) == 0 {
+				return fmt.Errorf("%v:%v: #endif without #if", path, line[0].line)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if !active() {
+				continue
+			}
+			switch name {
+			case "define":
+				// comments directly above a definition document it.
+				for above := line[0].line; len(pp.out) > 0; {
+					c := pp.out[len(pp.out)-1]
+					if c.kind != tComment || c.file != path || c.line+strings.Count(c.text, "\n") < above-1 {
+						break
+					}
+					comments = append([]token{c}, comments...)
+					pp.out = pp.out[:len(pp.out)-1]
+					above = c.line
+				}
+				if m := pp.define(args); m != nil {
+					m.doc = commentText(comments)
+				}
+			case "undef":
+				if len(args) > 0 {
+					delete(pp.macros, args[0].text)
+				}
+			case "include", "include_next":
+				if err := pp.includeFile(path, pp.expand(args, nil)); err != nil {
+					return err
+				}
+			case "pragma":
+				if len(args) > 0 && args[0].text == "once" {
+					pp.once[abs] = true
+				}
+			}
+		}
+	}
+	flush()
+	if len(stack) > 0 {
+		return fmt.Errorf("%v: unterminated #if", path)
+	}
+	return nil
+}
+
+// define records a #define directive.
+func (pp *preprocessor) define(args []token) *macro {
+	if len(args) == 0 || args[0].kind != tIdent {
+		return nil
+	}
+	m := &macro{name: args[0].text, at: args[0]}
+	body := args[1:]
+	if len(body) > 0 && body[0].is("(") && !body[0].space {
+		m.function = true
+		body = body[1:]
+		for len(body) > 0 && !body[0].is(")") {
+			switch {
+			case body[0].is("..."):
+				m.variadic = true
+			case body[0].kind == tIdent:
+				m.params = append(m.params, body[0].text)
+			}
+			body = body[1:]
+		}
+		if len(body) > 0 {
+			body = body[1:]
+		}
+	}
+	m.body = body
+	pp.macros[m.name] = m
+	pp.defined = append(pp.defined, m)
+	return m
+}
+
+// includeFile processes an #include of the given tokens, from the
+// file at path. Headers that cannot be found are skipped.
+func (pp *preprocessor) includeFile(path string, args []token) error {
+	if len(args) == 0 {
+		return nil
+	}
+	var name string
+	var dirs []string
+	switch {
+	case args[0].kind == tString:
+		name, _ = strconv.Unquote(args[0].text)
+		dirs = append([]string{filepath.Dir(path)}, pp.include...)
+	case args[0].is("<"):
+		for _, t := range args[1:] {
+			if t.is(">") {
+				break
+			}
+			name += t.text
+		}
+		dirs = pp.include
+	default:
+		return nil
+	}
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return pp.file(candidate)
+		}
+	}
+	return nil
+}
+
+// expand returns the tokens with every macro expanded, hide
+// holds the macros that are being expanded.
+func (pp *preprocessor) expand(tokens []token, hide map[string]bool) []token {
+	var out []token
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		m := pp.macros[t.text]
+		if t.kind != tIdent || m == nil || hide[t.text] {
+			out = append(out, t)
+			continue
+		}
+		inner := map[string]bool{t.text: true}
+		for name := range hide {
+			inner[name] = true
+		}
+		if !m.function {
+			out = append(out, pp.expand(relocate(m.body, t), inner)...)
+			continue
+		}
+		// function-like macros are only expanded when invoked.
+		j := i + 1
+		for j < len(tokens) && tokens[j].kind == tComment {
+			j++
+		}
+		if j >= len(tokens) || !tokens[j].is("(") {
+			out = append(out, t)
+			continue
+		}
+		args, end := arguments(tokens, j)
+		i = end
+		out = append(out, pp.expand(pp.substitute(m, args, t), inner)...)
+	}
+	return out
+}
+
+// arguments returns the comma separated arguments of the macro
+// invocation whose opening parenthesis is at tokens[open], along
+// with the index of the closing parenthesis.
+func arguments(tokens []token, open int) ([][]token, int) {
+	var (
+		args  [][]token
+		arg   []token
+		depth = 0
+	)
+	for i := open + 1; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.kind == tComment:
+			continue
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			if depth == 0 {
+				return append(args, arg), i
+			}
+			depth--
+		case t.is(",") && depth == 0:
+			args = append(args, arg)
+			arg = nil
+			continue
+		}
+		arg = append(arg, t)
+	}
+	return append(args, arg), len(tokens) - 1
+}
+
+// substitute returns the body of m with its parameters replaced.
+func (pp *preprocessor) substitute(m *macro, args [][]token, at token) []token {
+	param := func(name string) ([]token, bool) {
+		for i, p := range m.params {
+			if p == name {
+				if i < len(args) {
+					return args[i], true
+				}
+				return nil, true
+			}
+		}
+		if name == "__VA_ARGS__" && m.variadic {
+			var rest []token
+			for i := len(m.params); i < len(args); i++ {
+				if i > len(m.params) {
+					rest = append(rest, token{kind: tPunct, text: ","})
+				}
+				rest = append(rest, args[i]...)
+			}
+			return rest, true
+		}
+		return nil, false
+	}
+	var out []token
+	body := relocate(m.body, at)
+	for i := 0; i < len(body); i++ {
+		t := body[i]
+		switch {
+		case t.is("#") && i+1 < len(body):
+			if arg, ok := param(body[i+1].text); ok {
+				var text []string
+				for _, a := range arg {
+					text = append(text, a.text)
+				}
+				out = append(out, token{kind: tString, text: strconv.Quote(strings.Join(text, " ")), file: at.file, line: at.line})
+				i++
+				continue
+			}
+		case t.is("##") && len(out) > 0 && i+1 < len(body):
+			next := []token{body[i+1]}
+			if arg, ok := param(body[i+1].text); ok {
+				next = arg
+			}
+			i++
+			if len(next) == 0 {
+				continue
+			}
+			last := out[len(out)-1]
+			pasted := lex(at.file, last.text+next[0].text)
+			for k := range pasted {
+				pasted[k].line = at.line
+			}
+			out = append(append(out[:len(out)-1], pasted...), next[1:]...)
+			continue
+		case t.kind == tIdent:
+			if arg, ok := param(t.text); ok {
+				if i+1 < len(body) && body[i+1].is("##") {
+					out = append(out, arg...)
+				} else {
+					out = append(out, pp.expand(arg, nil)...)
+				}
+				continue
+			}
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// relocate returns a copy of tokens at the position of at.
+func relocate(tokens []token, at token) []token {
+	out := make([]token, len(tokens))
+	for i, t := range tokens {
+		t.file, t.line, t.bol = at.file, at.line, false
+		out[i] = t
+	}
+	return out
+}
+
+// eval evaluates the expression of an #if directive.
+func (pp *preprocessor) eval(tokens []token) int64 {
+	var resolved []token
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind == tIdent && t.text == "defined" {
+			var name string
+			if i+1 < len(tokens) && tokens[i+1].is("(") && i+2 < len(tokens) {
+				name = tokens[i+2].text
+				i += 3
+			} else if i+1 < len(tokens) {
+				name = tokens[i+1].text
+				i++
+			}
+			value := "0"
+			if pp.macros[name] != nil {
+				value = "1"
+			}
+			resolved = append(resolved, token{kind: tNumber, text: value})
+			continue
+		}
+		resolved = append(resolved, t)
+	}
+	value, _ := evaluate(pp.expand(resolved, nil), func(string) (int64, bool) { return 0, true })
+	return value
+}
@@ -0,0 +1,632 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// context in which a C type is converted into a Go type.
+type context int
+
+const (
+	inParam context = iota
+	inResult
+	inField
+)
+
+// standard maps the C types, and typedefs of the standard
+// headers (which are usually not available), to abi types.
+var standard = map[string]string{
+	"char":               "abi.Char",
+	"signed char":        "abi.CharSigned",
+	"unsigned char":      "abi.CharUnsigned",
+	"short":              "abi.Short",
+	"unsigned short":     "abi.ShortUnsigned",
+	"int":                "abi.Int",
+	"unsigned int":       "abi.IntUnsigned",
+	"long":               "abi.Long",
+	"unsigned long":      "abi.LongUnsigned",
+	"long long":          "abi.LongLong",
+	"unsigned long long": "abi.LongLongUnsigned",
+	"float":              "abi.Float",
+	"double":             "abi.Double",
+	"_Bool":              "abi.Bool",
+	"bool":               "abi.Bool",
+	"int8_t":             "abi.Int8",
+	"int16_t":            "abi.Int16",
+	"int32_t":            "abi.Int32",
+	"int64_t":            "abi.Int64",
+	"uint8_t":            "abi.Uint8",
+	"uint16_t":           "abi.Uint16",
+	"uint32_t":           "abi.Uint32",
+	"uint64_t":           "abi.Uint64",
+	"intptr_t":           "abi.Intptr",
+	"uintptr_t":          "abi.Uintptr",
+	"intmax_t":           "abi.IntMax",
+	"uintmax_t":          "abi.UIntMax",
+	"size_t":             "abi.Size",
+	"ssize_t":            "abi.Ptrdiff",
+	"ptrdiff_t":          "abi.Ptrdiff",
+	"time_t":             "abi.Time",
+	"clock_t":            "abi.Clock",
+	"wchar_t":            "abi.CharWide",
+	"va_list":            "abi.UnsafePointer",
+	"FILE":               "abi.File",
+}
+
+// emitter converts the declarations of a parser into Go source.
+type emitter struct {
+	p        *parser
+	pp       *preprocessor
+	pkg      string
+	lib      string   // name of the embedded ffi.Library type.
+	variable string   // name of the library variable.
+	prefixes []string // stripped from C names.
+
+	records  map[*record]string // Go name of each named record.
+	enums    map[*enum]string
+	typedefs map[string]string // Go name of each emitted typedef.
+	aliases  map[string]bool   // typedefs of records or enums named by another.
+	emitted  map[any]bool      // records and enums.
+	pending  []*record         // referenced, but not declared at the top level.
+	used     map[string]bool   // package scope names.
+	fields   map[string]bool   // library field names.
+	valid    map[*record]bool
+}
+
+// emit returns the Go source for the parsed declarations.
+func (e *emitter) emit() ([]byte, error) {
+	e.records = make(map[*record]string)
+	e.enums = make(map[*enum]string)
+	e.typedefs = make(map[string]string)
+	e.aliases = make(map[string]bool)
+	e.emitted = make(map[any]bool)
+	e.used = make(map[string]bool)
+	e.fields = make(map[string]bool)
+	e.valid = make(map[*record]bool)
+	e.used[e.lib] = true
+	e.used[e.variable] = true
+
+	// name records and enums after their typedefs, or their tags.
+	for _, d := range e.p.decls {
+		if d.kind != declTypedef {
+			continue
+		}
+		if _, ok := e.typedefs[d.name]; ok {
+			continue
+		}
+		switch {
+		case d.typ.kind == kRecord && e.records[d.typ.record] == "":
+			e.records[d.typ.record] = e.claim(d.name)
+		case d.typ.kind == kEnum && e.enums[d.typ.enum] == "":
+			e.enums[d.typ.enum] = e.claim(d.name)
+		default:
+			e.typedefs[d.name] = e.claim(d.name)
+			e.aliases[d.name] = d.typ.kind == kRecord || d.typ.kind == kEnum
+		}
+	}
+	for _, d := range e.p.decls {
+		switch {
+		case d.kind == declRecord && e.records[d.typ.record] == "":
+			e.records[d.typ.record] = e.claim(d.name)
+		case d.kind == declEnum && d.name != "" && e.enums[d.typ.enum] == "":
+			e.enums[d.typ.enum] = e.claim(d.name)
+		}
+	}
+
+	var body, funcs bytes.Buffer
+	for _, d := range e.p.decls {
+		switch d.kind {
+		case declTypedef:
+			e.typedef(&body, d)
+		case declRecord:
+			e.record(&body, d.typ.record, d.doc)
+		case declEnum:
+			e.enum(&body, d.typ.enum, d.doc)
+		case declFunc:
+			e.function(&funcs, d)
+		}
+	}
+	for i := 0; i < len(e.pending); i++ {
+		e.record(&body, e.pending[i], "")
+	}
+	constants := e.constants()
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by ffiheader. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %v\n\n", e.pkg)
+	var decls bytes.Buffer
+	decls.Write(constants)
+	decls.Write(body.Bytes())
+	fmt.Fprintf(&decls, "var %v struct {\n%v\n\n%v}\n", e.variable, e.lib, funcs.String())
+	var imports []string
+	if bytes.Contains(decls.Bytes(), []byte("abi.")) {
+		imports = append(imports, "\"qlova.tech/abi\"")
+	}
+	if bytes.Contains(decls.Bytes(), []byte("unsafe.")) {
+		imports = append(imports, "\"unsafe\"")
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&out, "import (\n%v\n)\n\n", strings.Join(imports, "\n"))
+	}
+	out.Write(decls.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return out.Bytes(), err
+	}
+	return src, nil
+}
+
+// claim returns the Go name for the C name, reserving it.
+func (e *emitter) claim(name string) string {
+	goName := e.name(name)
+	for e.used[goName] {
+		goName += "_"
+	}
+	e.used[goName] = true
+	return goName
+}
+
+// name returns the exported Go name for a C name, without its prefix.
+func (e *emitter) name(name string) string {
+	for _, prefix := range e.prefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	return exported(name)
+}
+
+// exported converts a C name into an exported Go name, words
+// separated by underscores are joined in CamelCase.
+func exported(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		if strings.ToUpper(word) == word {
+			word = strings.ToLower(word)
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if b.Len() == 0 || !unicode.IsLetter(rune(b.String()[0])) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// comment writes doc as a Go comment.
+func comment(w *bytes.Buffer, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		if line == "" {
+			fmt.Fprintln(w, "//")
+		} else {
+			fmt.Fprintf(w, "// %v\n", line)
+		}
+	}
+}
+
+// resolve follows typedefs to the underlying type of t.
+func (e *emitter) resolve(t *ctype) *ctype {
+	for t.kind == kNamed {
+		if _, ok := standard[t.name]; ok {
+			return t
+		}
+		next, ok := e.p.typedefs[t.name]
+		if !ok {
+			return t
+		}
+		t = next
+	}
+	return t
+}
+
+// representable reports whether the record can be declared as a Go struct.
+func (e *emitter) representable(r *record) bool {
+	if valid, ok := e.valid[r]; ok {
+		return valid
+	}
+	e.valid[r] = true // self references are through pointers.
+	valid := r.complete && !r.bitfield
+	for _, f := range r.fields {
+		if !valid {
+			break
+		}
+		if _, err := e.goType(f.typ, inField); err != nil || f.name == "" {
+			valid = false
+		}
+	}
+	e.valid[r] = valid
+	return valid
+}
+
+// recordName returns the Go name of the record, naming
+// anonymous or undeclared records by their tag.
+func (e *emitter) recordName(r *record) (string, error) {
+	if name, ok := e.records[r]; ok {
+		return name, nil
+	}
+	if r.tag == "" {
+		return "", fmt.Errorf("anonymous struct")
+	}
+	name := e.claim(r.tag)
+	e.records[r] = name
+	e.pending = append(e.pending, r)
+	return name, nil
+}
+
+// goType returns the Go type for t in the given context.
+func (e *emitter) goType(t *ctype, ctx context) (string, error) {
+	switch t.kind {
+	case kBasic:
+		if t.name == "void" {
+			if ctx == inResult {
+				return "", nil
+			}
+			return "", fmt.Errorf("void %v", ctxName(ctx))
+		}
+		if name, ok := standard[t.name]; ok {
+			return name, nil
+		}
+		return "", fmt.Errorf("unsupported type %v", t.name)
+	case kNamed:
+		if name, ok := standard[t.name]; ok {
+			return name, nil
+		}
+		if name, ok := e.typedefs[t.name]; ok {
+			return name, nil
+		}
+		under, ok := e.p.typedefs[t.name]
+		if !ok {
+			return "", fmt.Errorf("unknown type %v", t.name)
+		}
+		return e.goType(under, ctx)
+	case kRecord:
+		if !e.representable(t.record) || (t.record.union && ctx != inField) {
+			// unions are classified by their members, not their bytes.
+			return "", fmt.Errorf("%v is passed by value", cName(t))
+		}
+		return e.recordName(t.record)
+	case kEnum:
+		if name, ok := e.enums[t.enum]; ok {
+			return name, nil
+		}
+		return "abi.Enum", nil
+	case kPointer:
+		return e.pointer(t, ctx)
+	case kArray:
+		if ctx != inField {
+			return "", fmt.Errorf("array %v", ctxName(ctx))
+		}
+		if t.length < 0 {
+			return "", fmt.Errorf("array of unknown length")
+		}
+		elem, err := e.goType(t.elem, inField)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%d]%v", t.length, elem), nil
+	case kFunc:
+		return "", fmt.Errorf("function %v", ctxName(ctx))
+	}
+	return "", fmt.Errorf("unsupported type")
+}
+
+// pointer returns the Go type for the pointer t.
+func (e *emitter) pointer(t *ctype, ctx context) (string, error) {
+	elem := t.elem
+	under := e.resolve(elem)
+	switch {
+	case under.kind == kBasic && under.name == "void":
+		return "abi.UnsafePointer", nil
+	case under.kind == kBasic && under.name == "char" && (elem.isConst || under.isConst):
+		if ctx == inParam {
+			return "string", nil
+		}
+		return "abi.String", nil
+	case under.kind == kRecord && !e.representable(under.record):
+		// incomplete records are passed around as opaque pointers.
+		if name, ok := e.records[under.record]; ok {
+			return name, nil
+		}
+		return e.recordName(under.record)
+	case under.kind == kFunc:
+		if elem.kind == kNamed {
+			if name, ok := e.typedefs[elem.name]; ok {
+				return name, nil
+			}
+		}
+		fn, err := e.signature(under, false)
+		if err != nil {
+			return "", err
+		}
+		return "abi.Func[" + fn + "]", nil
+	}
+	inner, err := e.goType(elem, inField)
+	if err != nil {
+		return "", err
+	}
+	return "*" + inner, nil
+}
+
+// signature returns the Go func type for the C function type t.
+func (e *emitter) signature(t *ctype, named bool) (string, error) {
+	var params []string
+	for i, param := range t.params {
+		typ, err := e.goType(param.typ, inParam)
+		if err != nil {
+			return "", fmt.Errorf("parameter %v: %w", i, err)
+		}
+		params = append(params, typ)
+	}
+	if t.variadic {
+		if !named {
+			return "", fmt.Errorf("variadic callback")
+		}
+		params = append(params, "...any")
+	}
+	if named {
+		names := paramNames(t)
+		if names != nil {
+			for i := range t.params {
+				params[i] = names[i] + " " + params[i]
+			}
+			if t.variadic {
+				params[len(params)-1] = "args " + params[len(params)-1]
+			}
+		}
+	}
+	result, err := e.goType(t.elem, inResult)
+	if err != nil {
+		return "", fmt.Errorf("result: %w", err)
+	}
+	fn := "func(" + strings.Join(params, ", ") + ")"
+	if result != "" {
+		fn += " " + result
+	}
+	return fn, nil
+}
+
+// paramNames returns the Go names of the parameters of t, or
+// nil if any of them is unnamed.
+func paramNames(t *ctype) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, param := range t.params {
+		name := param.name
+		if name == "" || seen[name] || name == "args" {
+			return nil
+		}
+		seen[name] = true
+		if isKeyword(name) {
+			name += "_"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// isKeyword reports whether name is a Go keyword.
+func isKeyword(name string) bool {
+	switch name {
+	case "break", "case", "chan", "const", "continue", "default", "defer", "else",
+		"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+		"map", "package", "range", "return", "select", "struct", "switch", "type", "var":
+		return true
+	}
+	return false
+}
+
+func ctxName(ctx context) string {
+	switch ctx {
+	case inParam:
+		return "parameter"
+	case inResult:
+		return "result"
+	}
+	return "field"
+}
+
+func cName(t *ctype) string {
+	switch t.kind {
+	case kRecord:
+		if t.record.union {
+			return "union " + t.record.tag
+		}
+		return "struct " + t.record.tag
+	case kEnum:
+		return "enum " + t.enum.tag
+	}
+	return t.name
+}
+
+// typedef writes the Go type for a typedef.
+func (e *emitter) typedef(w *bytes.Buffer, d decl) {
+	name := e.typedefs[d.name]
+	switch {
+	case d.typ.kind == kRecord:
+		e.record(w, d.typ.record, d.doc)
+	case d.typ.kind == kEnum:
+		e.enum(w, d.typ.enum, d.doc)
+	}
+	if name == "" {
+		return
+	}
+	var (
+		typ string
+		err error
+	)
+	switch under := e.resolve(d.typ); {
+	case e.aliases[d.name] && d.typ.kind == kRecord:
+		typ, err = e.recordName(d.typ.record)
+		typ = "= " + typ
+	case e.aliases[d.name]:
+		typ, err = e.goType(d.typ, inField)
+		typ = "= " + typ
+	case under.kind == kFunc:
+		typ, err = e.signature(under, false)
+		typ = "abi.Func[" + typ + "]"
+	case under.kind == kPointer && e.resolve(under.elem).kind == kFunc:
+		typ, err = e.signature(e.resolve(under.elem), false)
+		typ = "abi.Func[" + typ + "]"
+	default:
+		typ, err = e.goType(d.typ, inField)
+	}
+	if err != nil {
+		delete(e.typedefs, d.name)
+		fmt.Fprintf(w, "// %v is not supported: %v\n\n", d.name, err)
+		return
+	}
+	comment(w, d.doc)
+	fmt.Fprintf(w, "type %v %v\n\n", name, typ)
+}
+
+// record writes the Go type for a record, once.
+func (e *emitter) record(w *bytes.Buffer, r *record, doc string) {
+	if e.emitted[r] {
+		return
+	}
+	e.emitted[r] = true
+	name, err := e.recordName(r)
+	if err != nil {
+		return
+	}
+	comment(w, doc)
+	if !e.representable(r) {
+		fmt.Fprintf(w, "type %v abi.Opaque[%[1]v]\n\n", name)
+		return
+	}
+	fmt.Fprintf(w, "type %v struct {\n", name)
+	if r.union {
+		// the union has the size of its largest member, and
+		// the alignment of its most aligned member.
+		var sizes []string
+		for _, f := range r.fields {
+			typ, _ := e.goType(f.typ, inField)
+			comment(w, f.doc)
+			fmt.Fprintf(w, "_ [0]%v // %v\n", typ, f.name)
+			sizes = append(sizes, "unsafe.Sizeof(*new("+typ+"))")
+		}
+		fmt.Fprintf(w, "_ [max(%v)]byte\n}\n\n", strings.Join(sizes, ", "))
+		return
+	}
+	for _, f := range r.fields {
+		typ, _ := e.goType(f.typ, inField)
+		comment(w, f.doc)
+		fmt.Fprintf(w, "%v %v\n", exported(f.name), typ)
+	}
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// enum writes the Go type and constants for an enum.
+func (e *emitter) enum(w *bytes.Buffer, en *enum, doc string) {
+	if e.emitted[en] {
+		return
+	}
+	e.emitted[en] = true
+	name := e.enums[en]
+	comment(w, doc)
+	if name != "" {
+		fmt.Fprintf(w, "type %v abi.Enum\n\n", name)
+	}
+	if len(en.values) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "const (\n")
+	for _, v := range en.values {
+		comment(w, v.doc)
+		if name != "" {
+			fmt.Fprintf(w, "%v %v = %v\n", e.claim(v.name), name, v.value)
+		} else {
+			fmt.Fprintf(w, "%v = %v\n", e.claim(v.name), v.value)
+		}
+	}
+	fmt.Fprintf(w, ")\n\n")
+}
+
+// function writes the library field for a function.
+func (e *emitter) function(w *bytes.Buffer, d decl) {
+	name := e.name(d.name)
+	for e.fields[name] {
+		name += "_"
+	}
+	e.fields[name] = true
+	fn, err := e.signature(d.typ, true)
+	if err != nil {
+		fmt.Fprintf(w, "\n// %v is not supported: %v\n", d.name, err)
+		return
+	}
+	if d.doc != "" {
+		fmt.Fprintln(w)
+		comment(w, d.doc)
+	}
+	fmt.Fprintf(w, "%v %v `ffi:%q`\n", name, fn, d.name)
+}
+
+// constants returns a const block for the object-like macros
+// of the input files with a constant value.
+func (e *emitter) constants() []byte {
+	var w bytes.Buffer
+	for _, m := range e.pp.defined {
+		if m.function || len(m.body) == 0 || strings.HasPrefix(m.name, "_") || !e.p.files[m.at.file] {
+			continue
+		}
+		if e.pp.macros[m.name] != m {
+			continue // undefined or redefined.
+		}
+		value, ok := e.constant(e.pp.expand(m.body, nil))
+		if !ok {
+			continue
+		}
+		comment(&w, m.doc)
+		fmt.Fprintf(&w, "%v = %v\n", e.claim(m.name), value)
+	}
+	if w.Len() == 0 {
+		return nil
+	}
+	return []byte("const (\n" + w.String() + ")\n\n")
+}
+
+// constant returns the Go constant expression for the macro body.
+func (e *emitter) constant(body []token) (string, bool) {
+	var tokens []token
+	for _, t := range body {
+		if t.kind != tComment {
+			tokens = append(tokens, t)
+		}
+	}
+	if len(tokens) == 0 {
+		return "", false
+	}
+	if tokens[0].kind == tString {
+		var s strings.Builder
+		for _, t := range tokens {
+			unquoted, err := strconv.Unquote(t.text)
+			if t.kind != tString || err != nil {
+				return "", false
+			}
+			s.WriteString(unquoted)
+		}
+		return strconv.Quote(s.String()), true
+	}
+	if len(tokens) == 1 && tokens[0].kind == tNumber && !strings.HasPrefix(tokens[0].text, "0x") {
+		text := strings.TrimRight(strings.ToLower(tokens[0].text), "fl")
+		if _, err := strconv.ParseFloat(text, 64); err == nil && strings.ContainsAny(text, ".e") {
+			return text, true
+		}
+	}
+	value, ok := evaluate(tokens, func(name string) (int64, bool) {
+		v, ok := e.p.constants[name]
+		return v, ok
+	})
+	return strconv.FormatInt(value, 10), ok
+}
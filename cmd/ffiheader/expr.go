@@ -0,0 +1,228 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// evaluate evaluates a C integer constant expression, identifiers
+// are resolved with ident. Reports false if the expression could
+// not be evaluated.
+func evaluate(tokens []token, ident func(name string) (int64, bool)) (int64, bool) {
+	var filtered []token
+	for _, t := range tokens {
+		if t.kind != tComment {
+			filtered = append(filtered, t)
+		}
+	}
+	e := &evaluator{tokens: filtered, ident: ident, ok: true}
+	value := e.ternary()
+	return value, e.ok && e.pos == len(e.tokens)
+}
+
+type evaluator struct {
+	tokens []token
+	pos    int
+	ident  func(string) (int64, bool)
+	ok     bool
+}
+
+func (e *evaluator) peek() token {
+	if e.pos < len(e.tokens) {
+		return e.tokens[e.pos]
+	}
+	return token{kind: tPunct}
+}
+
+func (e *evaluator) accept(op string) bool {
+	if e.peek().is(op) {
+		e.pos++
+		return true
+	}
+	return false
+}
+
+func (e *evaluator) ternary() int64 {
+	cond := e.binary(0)
+	if e.accept("?") {
+		a := e.ternary()
+		if !e.accept(":") {
+			e.ok = false
+		}
+		b := e.ternary()
+		if cond != 0 {
+			return a
+		}
+		return b
+	}
+	return cond
+}
+
+// precedence of the binary operators, lowest first.
+var precedence = [][]string{
+	{"||"}, {"&&"}, {"|"}, {"^"}, {"&"}, {"==", "!="}, {"<", ">", "<=", ">="}, {"<<", ">>"}, {"+", "-"}, {"*", "/", "%"},
+}
+
+func (e *evaluator) binary(level int) int64 {
+	if level == len(precedence) {
+		return e.unary()
+	}
+	left := e.binary(level + 1)
+	for {
+		var op string
+		for _, candidate := range precedence[level] {
+			if e.peek().is(candidate) {
+				op = candidate
+			}
+		}
+		if op == "" {
+			return left
+		}
+		e.pos++
+		right := e.binary(level + 1)
+		left = apply(op, left, right, &e.ok)
+	}
+}
+
+func apply(op string, a, b int64, ok *bool) int64 {
+	truth := func(v bool) int64 {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	switch op {
+	case "||":
+		return truth(a != 0 || b != 0)
+	case "&&":
+		return truth(a != 0 && b != 0)
+	case "|":
+		return a | b
+	case "^":
+		return a ^ b
+	case "&":
+		return a & b
+	case "==":
+		return truth(a == b)
+	case "!=":
+		return truth(a != b)
+	case "<":
+		return truth(a < b)
+	case ">":
+		return truth(a > b)
+	case "<=":
+		return truth(a <= b)
+	case ">=":
+		return truth(a >= b)
+	case "<<":
+		return a << uint64(b)
+	case ">>":
+		return a >> uint64(b)
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/", "%":
+		if b == 0 {
+			*ok = false
+			return 0
+		}
+		if op == "/" {
+			return a / b
+		}
+		return a % b
+	}
+	*ok = false
+	return 0
+}
+
+func (e *evaluator) unary() int64 {
+	switch {
+	case e.accept("!"):
+		if e.unary() == 0 {
+			return 1
+		}
+		return 0
+	case e.accept("~"):
+		return ^e.unary()
+	case e.accept("-"):
+		return -e.unary()
+	case e.accept("+"):
+		return e.unary()
+	}
+	return e.primary()
+}
+
+func (e *evaluator) primary() int64 {
+	t := e.peek()
+	e.pos++
+	switch t.kind {
+	case tNumber:
+		value, ok := parseInt(t.text)
+		if !ok {
+			e.ok = false
+		}
+		return value
+	case tChar:
+		unquoted, err := strconv.Unquote(t.text)
+		if err != nil || len(unquoted) == 0 {
+			e.ok = false
+			return 0
+		}
+		return int64(unquoted[0])
+	case tIdent:
+		value, ok := e.ident(t.text)
+		if !ok {
+			e.ok = false
+		}
+		return value
+	}
+	if t.is("(") {
+		// casts to integer types are ignored.
+		if e.isCast() {
+			for !e.accept(")") {
+				e.pos++
+			}
+			return e.unary()
+		}
+		value := e.ternary()
+		if !e.accept(")") {
+			e.ok = false
+		}
+		return value
+	}
+	e.ok = false
+	return 0
+}
+
+// isCast reports whether the tokens after an opening parenthesis
+// are a type name followed by a closing parenthesis and an operand.
+func (e *evaluator) isCast() bool {
+	i := e.pos
+	for i < len(e.tokens) && e.tokens[i].kind == tIdent {
+		i++
+	}
+	if i == e.pos || i+1 >= len(e.tokens) || !e.tokens[i].is(")") {
+		return false
+	}
+	next := e.tokens[i+1]
+	return next.kind == tNumber || next.kind == tIdent || next.kind == tChar || next.is("(") || next.is("~") || next.is("-")
+}
+
+// parseInt parses a C integer literal, ignoring its suffix.
+func parseInt(text string) (int64, bool) {
+	text = strings.TrimRight(strings.ToLower(text), "ul")
+	base := 10
+	switch {
+	case strings.HasPrefix(text, "0x"):
+		base, text = 16, text[2:]
+	case strings.HasPrefix(text, "0b"):
+		base, text = 2, text[2:]
+	case len(text) > 1 && text[0] == '0':
+		base, text = 8, text[1:]
+	}
+	value, err := strconv.ParseUint(text, base, 64)
+	return int64(value), err == nil
+}
@@ -0,0 +1,132 @@
+// Command ffiheader generates Go ffi.Library structs from C headers,
+// such that bindings for a library do not have to be written by hand:
+//
+//	go run qlova.tech/cmd/ffiheader -pkg sdl -prefix SDL_ -I include include/SDL.h
+//
+// The headers are preprocessed by ffiheader itself, so that it runs
+// offline without a C toolchain, headers that cannot be found (such as
+// the system headers) are skipped and the standard C types are mapped
+// to their abi equivalents. Typedefs, enums, structs and function
+// prototypes are converted, along with their documentation comments,
+// incomplete structs become abi.Opaque types, unions become structs
+// with the size and alignment of their members and enums become typed
+// constants. Functions that cannot be represented, such as those that
+// pass unions by value or take a long double, are noted in the output. The generated library struct embeds the type named by -lib,
+// which is expected to be declared by hand, ie.
+//
+//	type Lib struct {
+//		ffi.Library `linux:"libSDL2-2.0.so.0"`
+//	}
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// list is a repeatable flag.
+type list []string
+
+func (l *list) String() string     { return strings.Join(*l, ",") }
+func (l *list) Set(s string) error { *l = append(*l, s); return nil }
+
+var (
+	pkg      = flag.String("pkg", "main", "package name of the generated file")
+	lib      = flag.String("lib", "Lib", "type embedded by the generated library struct")
+	variable = flag.String("var", "Functions", "name of the generated library variable")
+	prefix   = flag.String("prefix", "", "comma separated prefixes to strip from C names")
+	output   = flag.String("o", "", "output file name, defaults to standard output")
+	includes list
+	defines  list
+)
+
+// warnings is where problems with the headers are reported.
+var warnings io.Writer = os.Stderr
+
+func main() {
+	flag.Var(&includes, "I", "include directory (repeatable)")
+	flag.Var(&defines, "D", "macro definition name[=value] (repeatable)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: ffiheader [flags] header.h...")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	var prefixes []string
+	if *prefix != "" {
+		prefixes = strings.Split(*prefix, ",")
+	}
+	src, err := generate(config{
+		headers:  flag.Args(),
+		include:  includes,
+		defines:  defines,
+		pkg:      *pkg,
+		lib:      *lib,
+		variable: *variable,
+		prefixes: prefixes,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ffiheader:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ffiheader:", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	headers  []string
+	include  []string
+	defines  []string
+	pkg      string
+	lib      string
+	variable string
+	prefixes []string
+}
+
+// generate returns the Go source for the headers.
+func generate(c config) ([]byte, error) {
+	pp := newPreprocessor(c.include, c.defines)
+	files := make(map[string]bool)
+	for _, header := range c.headers {
+		files[header] = true
+		if err := pp.file(header); err != nil {
+			return nil, err
+		}
+	}
+	// headers included from the same directory, or below
+	// are converted too.
+	within := func(file string) bool {
+		for _, header := range c.headers {
+			rel, err := filepath.Rel(filepath.Dir(header), file)
+			if err == nil && !strings.HasPrefix(rel, "..") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, t := range pp.out {
+		files[t.file] = files[t.file] || within(t.file)
+	}
+	for _, m := range pp.defined {
+		files[m.at.file] = files[m.at.file] || within(m.at.file)
+	}
+	e := emitter{
+		p:        parse(pp.out, files),
+		pp:       pp,
+		pkg:      c.pkg,
+		lib:      c.lib,
+		variable: c.variable,
+		prefixes: c.prefixes,
+	}
+	return e.emit()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	gotoken "go/token"
+	"go/types"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	warnings = io.Discard
+	src, err := generate(config{
+		headers:  []string{"testdata/fixture.h"},
+		pkg:      "fixture",
+		lib:      "Lib",
+		variable: "Functions",
+		prefixes: []string{"FIX_", "Fix_"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile("testdata/fixture.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, golden) {
+		t.Errorf("generated source does not match testdata/fixture.go.golden:\n%s", src)
+	}
+
+	// the generated source must type check against abi and ffi.
+	fset := gotoken.NewFileSet()
+	var files []*ast.File
+	for name, src := range map[string]string{
+		"fixture.go": string(src),
+		"lib.go":     "package fixture\n\nimport \"qlova.tech/ffi\"\n\ntype Lib struct{ ffi.Library }\n",
+	} {
+		file, err := goparser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("fixture", fset, files, nil); err != nil {
+		t.Fatal(err)
+	}
+}
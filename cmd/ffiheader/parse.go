@@ -0,0 +1,715 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type kind int
+
+const (
+	kBasic   kind = iota // void, int, unsigned long, etc.
+	kNamed               // typedef name.
+	kRecord              // struct or union.
+	kEnum                // enum.
+	kPointer             // pointer to elem.
+	kArray               // array of length elems.
+	kFunc                // function returning elem.
+)
+
+// ctype is a C type.
+type ctype struct {
+	kind     kind
+	name     string // basic spelling, or typedef name.
+	isConst  bool
+	elem     *ctype
+	length   int64 // of an array, -1 if unknown.
+	params   []field
+	variadic bool
+	record   *record
+	enum     *enum
+}
+
+// field of a record, or parameter of a function.
+type field struct {
+	name string
+	typ  *ctype
+	doc  string
+}
+
+type record struct {
+	tag      string
+	union    bool
+	complete bool
+	bitfield bool // has bitfields, which cannot be represented.
+	fields   []field
+}
+
+type enum struct {
+	tag    string
+	values []enumerator
+}
+
+type enumerator struct {
+	name  string
+	value int64
+	doc   string
+}
+
+type declKind int
+
+const (
+	declTypedef declKind = iota
+	declFunc
+	declRecord
+	declEnum
+)
+
+// decl is a top-level declaration in one of the input files.
+type decl struct {
+	kind declKind
+	name string
+	typ  *ctype
+	doc  string
+}
+
+// parser of top-level C declarations.
+type parser struct {
+	tokens []token
+	pos    int
+	files  map[string]bool // declarations are only kept from these.
+
+	typedefs  map[string]*ctype
+	records   map[string]*record // by "struct tag" or "union tag".
+	enums     map[string]*enum
+	constants map[string]int64 // enumerators.
+
+	decls []decl
+}
+
+// parseError aborts the current declaration.
+type parseError struct{ at token }
+
+func parse(tokens []token, files map[string]bool) *parser {
+	p := &parser{
+		tokens:    tokens,
+		files:     files,
+		typedefs:  make(map[string]*ctype),
+		records:   make(map[string]*record),
+		enums:     make(map[string]*enum),
+		constants: make(map[string]int64),
+	}
+	var (
+		doc     string
+		docLine int
+		docFile string
+	)
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		switch {
+		case t.kind == tComment:
+			start := p.pos
+			for p.pos < len(p.tokens) && p.tokens[p.pos].kind == tComment {
+				p.pos++
+			}
+			last := p.tokens[p.pos-1]
+			doc, docLine, docFile = commentText(p.tokens[start:p.pos]), last.line+strings.Count(last.text, "\n"), last.file
+		case t.is(";"), t.is("}"):
+			p.pos++
+		case t.kind == tIdent && t.text == "extern" && p.peekAt(1).kind == tString:
+			p.pos += 2
+			if p.peek().is("{") {
+				p.pos++
+			}
+		default:
+			// only documentation directly above a declaration is kept.
+			if docFile != t.file || t.line > docLine+1 {
+				doc = ""
+			}
+			p.declaration(doc)
+			doc = ""
+		}
+	}
+	return p
+}
+
+func (p *parser) peek() token { return p.peekAt(0) }
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return token{kind: tPunct, text: "<EOF>"}
+}
+
+// next returns the next non-comment token.
+func (p *parser) next() token {
+	p.comments()
+	t := p.peek()
+	if p.pos >= len(p.tokens) {
+		panic(parseError{t})
+	}
+	p.pos++
+	return t
+}
+
+// comments skips any comments, returning them.
+func (p *parser) comments() []token {
+	start := p.pos
+	for p.pos < len(p.tokens) && p.tokens[p.pos].kind == tComment {
+		p.pos++
+	}
+	return p.tokens[start:p.pos]
+}
+
+func (p *parser) accept(op string) bool {
+	p.comments()
+	if p.peek().is(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) {
+	if t := p.next(); !t.is(op) {
+		panic(parseError{t})
+	}
+}
+
+// skipBalanced skips a bracketed group, starting at its opening bracket.
+func (p *parser) skipBalanced() {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.is("("), t.is("["), t.is("{"):
+			depth++
+		case t.is(")"), t.is("]"), t.is("}"):
+			depth--
+		}
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// skipDeclaration skips a declaration that could not be parsed,
+// up to its semicolon, or the end of its function body.
+func (p *parser) skipDeclaration(start int) {
+	p.pos = start
+	var bodies []bool // for each open bracket, whether it opens a function body.
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		p.pos++
+		switch {
+		case t.is("("), t.is("["), t.is("{"):
+			bodies = append(bodies, t.is("{") && p.pos > 1 && p.tokens[p.pos-2].is(")"))
+		case t.is(")"), t.is("]"), t.is("}"):
+			if len(bodies) == 0 {
+				return
+			}
+			body := bodies[len(bodies)-1]
+			bodies = bodies[:len(bodies)-1]
+			if body && len(bodies) == 0 {
+				return
+			}
+		case t.is(";") && len(bodies) == 0:
+			return
+		}
+	}
+}
+
+// declaration parses a top-level declaration.
+func (p *parser) declaration(doc string) {
+	start := p.pos
+	file := p.tokens[start].file
+	keep := p.files[file]
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseError); !ok {
+				panic(r)
+			}
+			p.skipDeclaration(start)
+		}
+	}()
+	base, typedef := p.specifiers()
+	if p.accept(";") {
+		if !keep {
+			return
+		}
+		switch {
+		case base.kind == kRecord && base.record.tag != "" && base.record.complete:
+			p.decls = append(p.decls, decl{kind: declRecord, name: base.record.tag, typ: base, doc: doc})
+		case base.kind == kEnum:
+			p.decls = append(p.decls, decl{kind: declEnum, name: base.enum.tag, typ: base, doc: doc})
+		}
+		return
+	}
+	for {
+		name, wrap := p.declarator()
+		typ := wrap(base)
+		switch {
+		case name == "":
+			panic(parseError{p.peek()})
+		case typedef:
+			p.typedefs[name] = typ
+			if keep {
+				p.decls = append(p.decls, decl{kind: declTypedef, name: name, typ: typ, doc: doc})
+			}
+		case typ.kind == kFunc:
+			if p.comments(); p.peek().is("{") {
+				p.skipBalanced() // inline definitions are not exported.
+				return
+			}
+			if keep {
+				p.decls = append(p.decls, decl{kind: declFunc, name: name, typ: typ, doc: doc})
+			}
+		}
+		// initializers are skipped.
+		if p.accept("=") {
+			for p.comments(); !p.peek().is(",") && !p.peek().is(";"); p.comments() {
+				if t := p.peek(); t.is("(") || t.is("{") || t.is("[") {
+					p.skipBalanced()
+				} else {
+					p.next()
+				}
+			}
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(";")
+}
+
+// qualifiers and storage classes that do not affect the type.
+var ignored = map[string]bool{
+	"extern": true, "static": true, "inline": true, "__inline": true, "__inline__": true,
+	"register": true, "auto": true, "_Noreturn": true, "__extension__": true,
+	"restrict": true, "__restrict": true, "__restrict__": true,
+	"volatile": true, "__volatile__": true, "_Thread_local": true, "__thread": true,
+}
+
+// attributes that are followed by a parenthesised argument list.
+var attributes = map[string]bool{
+	"__attribute__": true, "__attribute": true, "__declspec": true,
+	"__asm__": true, "__asm": true, "asm": true, "_Alignas": true, "__pragma": true,
+}
+
+var basics = map[string]bool{
+	"void": true, "char": true, "short": true, "int": true, "long": true,
+	"signed": true, "unsigned": true, "float": true, "double": true,
+	"_Bool": true, "__signed__": true, "__int128": true,
+}
+
+// skipQualifier skips a qualifier or attribute, reporting whether
+// there was one and whether it was const.
+func (p *parser) skipQualifier() (skipped, isConst bool) {
+	p.comments()
+	t := p.peek()
+	if t.kind != tIdent {
+		return false, false
+	}
+	switch {
+	case t.text == "const" || t.text == "__const":
+		p.pos++
+		return true, true
+	case ignored[t.text]:
+		p.pos++
+		return true, false
+	case attributes[t.text]:
+		p.pos++
+		if p.comments(); p.peek().is("(") {
+			p.skipBalanced()
+		}
+		return true, false
+	}
+	return false, false
+}
+
+// specifiers parses declaration specifiers, returning the base
+// type and whether the declaration is a typedef.
+func (p *parser) specifiers() (base *ctype, typedef bool) {
+	var (
+		words   []string
+		isConst bool
+	)
+	for {
+		if skipped, c := p.skipQualifier(); skipped {
+			isConst = isConst || c
+			continue
+		}
+		t := p.peek()
+		if t.kind != tIdent {
+			break
+		}
+		switch {
+		case t.text == "typedef":
+			typedef = true
+			p.pos++
+			continue
+		case basics[t.text]:
+			words = append(words, t.text)
+			p.pos++
+			continue
+		case base != nil || len(words) > 0:
+		case t.text == "struct" || t.text == "union":
+			p.pos++
+			base = p.recordSpecifier(t.text == "union")
+			continue
+		case t.text == "enum":
+			p.pos++
+			base = p.enumSpecifier()
+			continue
+		case t.text == "bool" && p.typedefs["bool"] == nil:
+			words = append(words, "_Bool")
+			p.pos++
+			continue
+		default:
+			base = &ctype{kind: kNamed, name: t.text}
+			p.pos++
+			continue
+		}
+		break
+	}
+	if base == nil {
+		if len(words) == 0 {
+			panic(parseError{p.peek()})
+		}
+		base = &ctype{kind: kBasic, name: basicName(words)}
+	}
+	if isConst {
+		copied := *base
+		copied.isConst = true
+		base = &copied
+	}
+	return base, typedef
+}
+
+// basicName returns the canonical spelling of a basic type.
+func basicName(words []string) string {
+	count := make(map[string]int)
+	for _, word := range words {
+		count[word]++
+	}
+	unsigned := count["unsigned"] > 0
+	switch {
+	case count["void"] > 0:
+		return "void"
+	case count["_Bool"] > 0:
+		return "_Bool"
+	case count["float"] > 0:
+		return "float"
+	case count["double"] > 0:
+		if count["long"] > 0 {
+			return "long double"
+		}
+		return "double"
+	case count["char"] > 0:
+		switch {
+		case unsigned:
+			return "unsigned char"
+		case count["signed"] > 0 || count["__signed__"] > 0:
+			return "signed char"
+		}
+		return "char"
+	}
+	var name string
+	switch {
+	case count["short"] > 0:
+		name = "short"
+	case count["long"] > 1:
+		name = "long long"
+	case count["long"] > 0:
+		name = "long"
+	case count["__int128"] > 0:
+		name = "__int128"
+	default:
+		name = "int"
+	}
+	if unsigned {
+		return "unsigned " + name
+	}
+	return name
+}
+
+// recordSpecifier parses a struct or union, after its keyword.
+func (p *parser) recordSpecifier(union bool) *ctype {
+	for skipped, _ := p.skipQualifier(); skipped; skipped, _ = p.skipQualifier() {
+	}
+	var tag string
+	if p.peek().kind == tIdent {
+		tag = p.next().text
+	}
+	key := "struct " + tag
+	if union {
+		key = "union " + tag
+	}
+	rec := p.records[key]
+	if rec == nil || tag == "" {
+		rec = &record{tag: tag, union: union}
+		if tag != "" {
+			p.records[key] = rec
+		}
+	}
+	if !p.accept("{") {
+		return &ctype{kind: kRecord, name: tag, record: rec}
+	}
+	rec.fields, rec.complete, rec.bitfield = nil, true, false
+	for {
+		doc := commentText(p.comments())
+		if p.accept("}") {
+			break
+		}
+		base, _ := p.specifiers()
+		for {
+			name, wrap := p.declarator()
+			f := field{name: name, typ: wrap(base), doc: doc}
+			if p.accept(":") {
+				rec.bitfield = true
+				for !p.peek().is(",") && !p.peek().is(";") {
+					p.next()
+				}
+			}
+			rec.fields = append(rec.fields, f)
+			if !p.accept(",") {
+				break
+			}
+		}
+		semicolon := p.peek()
+		p.expect(";")
+		p.trailing(semicolon, &rec.fields[len(rec.fields)-1].doc)
+	}
+	for skipped, _ := p.skipQualifier(); skipped; skipped, _ = p.skipQualifier() {
+	}
+	return &ctype{kind: kRecord, name: tag, record: rec}
+}
+
+// trailing uses a comment on the same line as after, if any,
+// as the documentation of the preceding field or enumerator.
+func (p *parser) trailing(after token, doc *string) {
+	t := p.peek()
+	if t.kind == tComment && t.file == after.file && t.line == after.line {
+		*doc = commentText([]token{t})
+		p.pos++
+	}
+}
+
+// enumSpecifier parses an enum, after its keyword.
+func (p *parser) enumSpecifier() *ctype {
+	for skipped, _ := p.skipQualifier(); skipped; skipped, _ = p.skipQualifier() {
+	}
+	var tag string
+	if p.peek().kind == tIdent {
+		tag = p.next().text
+	}
+	if p.accept(":") {
+		p.specifiers()
+	}
+	e := p.enums[tag]
+	if e == nil || tag == "" {
+		e = &enum{tag: tag}
+		if tag != "" {
+			p.enums[tag] = e
+		}
+	}
+	if !p.accept("{") {
+		return &ctype{kind: kEnum, name: tag, enum: e}
+	}
+	e.values = nil
+	var next int64
+	for {
+		doc := commentText(p.comments())
+		if p.accept("}") {
+			break
+		}
+		name := p.next()
+		if name.kind != tIdent {
+			panic(parseError{name})
+		}
+		for skipped, _ := p.skipQualifier(); skipped; skipped, _ = p.skipQualifier() {
+		}
+		value, ok := next, true
+		if p.accept("=") {
+			var expr []token
+			depth := 0
+			for p.comments(); depth > 0 || (!p.peek().is(",") && !p.peek().is("}")); p.comments() {
+				t := p.next()
+				switch {
+				case t.is("("):
+					depth++
+				case t.is(")"):
+					depth--
+				}
+				expr = append(expr, t)
+			}
+			value, ok = evaluate(expr, func(name string) (int64, bool) {
+				v, ok := p.constants[name]
+				return v, ok
+			})
+		}
+		last := p.tokens[p.pos-1]
+		p.accept(",")
+		if !ok {
+			// enumerators that cannot be evaluated are left out.
+			fmt.Fprintf(warnings, "ffiheader: %v:%v: cannot evaluate %v\n", name.file, name.line, name.text)
+			continue
+		}
+		next = value + 1
+		p.constants[name.text] = value
+		e.values = append(e.values, enumerator{name: name.text, value: value, doc: doc})
+		p.trailing(last, &e.values[len(e.values)-1].doc)
+	}
+	return &ctype{kind: kEnum, name: tag, enum: e}
+}
+
+// declarator parses a possibly abstract declarator, returning its
+// name and a func that derives its type from the base type.
+func (p *parser) declarator() (string, func(*ctype) *ctype) {
+	var pointers []bool // const
+	for p.accept("*") || p.accept("^") {
+		isConst := false
+		for {
+			skipped, c := p.skipQualifier()
+			if !skipped {
+				break
+			}
+			isConst = isConst || c
+		}
+		pointers = append(pointers, isConst)
+	}
+	for skipped, _ := p.skipQualifier(); skipped; skipped, _ = p.skipQualifier() {
+	}
+	var (
+		name  string
+		inner = func(t *ctype) *ctype { return t }
+	)
+	switch t := p.peek(); {
+	case t.is("(") && p.nested():
+		p.pos++
+		name, inner = p.declarator()
+		p.expect(")")
+	case t.kind == tIdent:
+		name = t.text
+		p.pos++
+	}
+	var suffixes []func(*ctype) *ctype
+	for {
+		p.comments()
+		switch {
+		case p.peek().is("["):
+			var expr []token
+			p.pos++
+			for !p.peek().is("]") {
+				expr = append(expr, p.next())
+			}
+			p.pos++
+			length, ok := evaluate(expr, func(name string) (int64, bool) {
+				v, ok := p.constants[name]
+				return v, ok
+			})
+			if !ok {
+				length = -1
+			}
+			suffixes = append(suffixes, func(t *ctype) *ctype {
+				return &ctype{kind: kArray, elem: t, length: length}
+			})
+			continue
+		case p.peek().is("("):
+			p.pos++
+			params, variadic := p.parameters()
+			suffixes = append(suffixes, func(t *ctype) *ctype {
+				return &ctype{kind: kFunc, elem: t, params: params, variadic: variadic}
+			})
+			continue
+		}
+		break
+	}
+	for skipped, _ := p.skipQualifier(); skipped; skipped, _ = p.skipQualifier() {
+	}
+	return name, func(t *ctype) *ctype {
+		for _, isConst := range pointers {
+			t = &ctype{kind: kPointer, elem: t, isConst: isConst}
+		}
+		for i := len(suffixes) - 1; i >= 0; i-- {
+			t = suffixes[i](t)
+		}
+		return inner(t)
+	}
+}
+
+// nested reports whether the parenthesis at the current position
+// opens a nested declarator, rather than a parameter list.
+func (p *parser) nested() bool {
+	t := p.peekAt(1)
+	switch {
+	case t.is("*"), t.is("^"), t.is("("):
+		return true
+	case t.kind == tIdent:
+		return !basics[t.text] && !ignored[t.text] && p.typedefs[t.text] == nil &&
+			t.text != "const" && t.text != "struct" && t.text != "union" && t.text != "enum" && !attributes[t.text]
+	}
+	return false
+}
+
+// parameters parses a parameter list, after its opening parenthesis.
+func (p *parser) parameters() (params []field, variadic bool) {
+	if p.accept(")") {
+		return nil, false
+	}
+	if t := p.peek(); t.kind == tIdent && t.text == "void" && p.peekAt(1).is(")") {
+		p.pos += 2
+		return nil, false
+	}
+	for {
+		if p.accept("...") {
+			variadic = true
+		} else {
+			base, _ := p.specifiers()
+			name, wrap := p.declarator()
+			typ := wrap(base)
+			switch typ.kind {
+			case kArray:
+				typ = &ctype{kind: kPointer, elem: typ.elem}
+			case kFunc:
+				typ = &ctype{kind: kPointer, elem: typ}
+			}
+			params = append(params, field{name: name, typ: typ})
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	return params, variadic
+}
+
+// commentText returns the text of the given comments, without
+// their comment markers and decoration.
+func commentText(comments []token) string {
+	var lines []string
+	for _, c := range comments {
+		text := c.text
+		if strings.HasPrefix(text, "//") {
+			text = strings.TrimLeft(text[2:], "/!<")
+			lines = append(lines, strings.TrimSpace(text))
+			continue
+		}
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		text = strings.TrimLeft(text, "*!<")
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimSpace(strings.TrimLeft(line, "*"))
+			lines = append(lines, line)
+		}
+	}
+	// trim leading and trailing blank lines.
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,114 @@
+/* A small header exercising the features of ffiheader. */
+#ifndef FIXTURE_H
+#define FIXTURE_H
+
+#include <stdint.h>
+#include <stddef.h>
+#include "fixture_types.h"
+
+#define FIX_VERSION_MAJOR 2
+#define FIX_VERSION_MINOR 1
+#define FIX_VERSION ((FIX_VERSION_MAJOR << 8) | FIX_VERSION_MINOR)
+
+/** Name of the library. */
+#define FIX_NAME "fixture"
+
+#define FIX_PI 3.14159f
+
+#define FIX_BIT(n) (1u << (n))
+#define FIXCALL
+#define FIX_DEPRECATED __attribute__((deprecated))
+
+#if FIX_VERSION >= 0x200 && defined(FIX_NAME)
+#define FIX_HAS_FLAGS 1
+#else
+#define FIX_HAS_FLAGS 0
+#endif
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/**
+ * Flags for FIX_Init.
+ */
+typedef enum {
+    FIX_INIT_AUDIO = FIX_BIT(0), /**< the audio subsystem */
+    FIX_INIT_VIDEO = FIX_BIT(1), /**< the video subsystem */
+    FIX_INIT_EVERYTHING = FIX_INIT_AUDIO | FIX_INIT_VIDEO
+} FIX_InitFlags;
+
+enum {
+    FIX_FIRST,
+    FIX_SECOND,
+    FIX_TENTH = 10,
+    FIX_ELEVENTH
+};
+
+/* A window, which is never complete. */
+typedef struct FIX_Window FIX_Window;
+
+/**
+ * A rectangle.
+ */
+typedef struct FIX_Rect {
+    int x, y;
+    int w, h;   /**< size of the rectangle */
+} FIX_Rect;
+
+typedef struct FIX_Pixels {
+    Fix_Uint8 data[4 * 4];
+    const char *name;
+    FIX_Rect bounds;
+    struct FIX_Pixels *next;
+    FIX_Window *window;
+} FIX_Pixels;
+
+typedef union FIX_Value {
+    int i;
+    float f;
+} FIX_Value;
+
+/** Called for each event. */
+typedef int (FIXCALL *FIX_EventFilter)(void *userdata, FIX_Window *window);
+
+typedef Fix_Uint32 FIX_WindowID;
+
+/**
+ * Initializes the library.
+ *
+ * \param flags subsystems to initialize.
+ * \returns 0 on success.
+ */
+extern int FIXCALL FIX_Init(FIX_InitFlags flags);
+
+/** Returns the name of the library. */
+extern const char *FIX_GetName(void);
+
+extern FIX_Window *FIX_CreateWindow(const char *title, int w, int h, uint32_t flags);
+extern FIX_WindowID FIX_GetWindowID(FIX_Window *window);
+extern void FIX_DestroyWindow(FIX_Window *window) FIX_DEPRECATED;
+extern int FIX_FillRect(FIX_Pixels *pixels, const FIX_Rect *rect, uint32_t color);
+extern FIX_Rect FIX_GetBounds(FIX_Window *);
+extern void FIX_SetEventFilter(FIX_EventFilter filter, void *userdata);
+extern void FIX_Visit(void (*visit)(FIX_Window *window, size_t index), void *userdata);
+extern int FIX_Log(const char *format, ...);
+extern void FIX_GetValue(FIX_Value *value);
+extern void FIX_SetValue(FIX_Value value);
+extern int FIX_Lookup(const char *names[], int type);
+
+#if FIX_HAS_FLAGS
+extern FIX_InitFlags FIX_WasInit(void);
+#else
+extern int FIX_NotIncluded(void);
+#endif
+
+static inline int FIX_Max(int a, int b) { return a > b ? a : b; }
+
+extern void FIX_Unsupported(long double value, struct { int x; } point);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* FIXTURE_H */
@@ -0,0 +1,4 @@
+#pragma once
+
+typedef uint8_t Fix_Uint8;
+typedef uint32_t Fix_Uint32;
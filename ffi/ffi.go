@@ -1,56 +1,63 @@
 // Package ffi provides an interface for loading C shared libraries dynamically.
+//
+// On linux/amd64, the package also builds with CGO_ENABLED=0, in which case
+// the program is linked against libc without a C toolchain and C functions
+// are called through assembly stubs, so that tools can be cross-compiled.
 package ffi
 
 import (
 	"errors"
-	"log"
 	"reflect"
-	"runtime"
 	"strings"
-	"sync"
 	"unsafe"
 
 	"qlova.tech/abi"
 	"qlova.tech/ffi/internal/dyncall"
 )
 
-// #include <internal/dyncall/dyncall.h>
-import "C"
-
 // Library can be embedded inside of a struct to
 // mark it as a library interface structure. Each
-// other field in the struct must be a func.
+// other field in the struct must be a func, or a
+// global variable, see [Var].
 type Library interface {
 	library()
 }
 
-var vm4096 sync.Pool
-var vm8 sync.Pool
-
-func init() {
-	vm8.New = func() any {
-		return dyncall.NewVM(8)
-	}
-	vm4096.New = func() any {
-		return dyncall.NewVM(4096)
-	}
-}
-
 // Link dynamically links the given libraries, based on
-// the platform struct tags of the embedded [Library] field.
+// the platform struct tags of the embedded [Library] field,
+// for example `linux:"libSDL2-2.0.so.0,libSDL2.so"`, where
+// a GOOS_GOARCH tag, such as `linux_arm64:"..."` takes
+// precedence over the GOOS tag.
+// Every library is linked, even if some of their symbols
+// are missing, in which case they are all reported in a
+// single [*LinkError]. Symbols of version-gated funcs are
+// not missing, [Features] reports which are available.
 func Link(libraries ...Library) error {
+	var unresolved LinkError
 	for _, library := range libraries {
-		var header = reflect.TypeOf(library).Elem().Field(0)
-		for header.Type.Kind() == reflect.Struct {
-			header = header.Type.Field(0)
-		}
-		if err := Set(library, header.Tag.Get(runtime.GOOS)); err != nil {
-			return err
+		if err := Set(library, libraryTag(headerOf(library))); err != nil {
+			var missing *LinkError
+			if !errors.As(err, &missing) {
+				return err
+			}
+			unresolved.merge(missing)
 		}
 	}
+	if len(unresolved.Symbols) > 0 {
+		return &unresolved
+	}
 	return nil
 }
 
+// headerOf returns the embedded [Library] field of the library.
+func headerOf(library Library) reflect.StructField {
+	var header = reflect.TypeOf(library).Elem().Field(0)
+	for header.Type.Kind() == reflect.Struct {
+		header = header.Type.Field(0)
+	}
+	return header
+}
+
 func sigRune(t reflect.Type) rune {
 	switch t.Kind() {
 	case reflect.TypeOf(abi.Bool(false)).Kind():
@@ -81,12 +88,19 @@ func sigRune(t reflect.Type) rune {
 		return dyncall.Double
 	case reflect.String:
 		return dyncall.String
-	case reflect.Pointer:
+	case reflect.Pointer, reflect.UnsafePointer:
 		return dyncall.Pointer
-	case reflect.Struct:
-		if t.Implements(reflect.TypeOf([0]abi.IsPointer{}).Elem()) {
+	case reflect.Struct, reflect.Array:
+		switch {
+		case isPointerLike(t):
 			return dyncall.Pointer
-		} else {
+		case isLongDouble(t):
+			panic(errLongDouble + ", " + t.String())
+		case isAggregate(t):
+			return dyncall.Aggregate
+		case t.Kind() == reflect.Array:
+			return dyncall.Pointer
+		default:
 			panic("unsupported struct " + t.String())
 		}
 	default:
@@ -94,27 +108,68 @@ func sigRune(t reflect.Type) rune {
 	}
 }
 
-func newSignature(ftype reflect.Type) dyncall.Signature {
+// newSignature returns the signature of a C function with the given
+// parameters, see [params], that returns the first Go result, if
+// returns is true. Split parameters have lengths of the given type.
+func newSignature(ftype reflect.Type, order []param, returns bool, length string) dyncall.Signature {
 	var sig dyncall.Signature
-	for i := 0; i < ftype.NumIn(); i++ {
-		sig.Args = append(sig.Args, sigRune(ftype.In(i)))
+	for _, param := range order {
+		switch {
+		case param.in < 0:
+			sig.Args = append(sig.Args, dyncall.Pointer)
+			continue
+		case param.length:
+			sig.Args = append(sig.Args, scalarRune(lengthType(length)))
+			continue
+		case splits(ftype, param.in, length):
+			sig.Args = append(sig.Args, dyncall.Pointer)
+			continue
+		}
+		arg := sigRune(ftype.In(param.in))
+		sig.Args = append(sig.Args, arg)
+		if arg == dyncall.Aggregate {
+			sig.Aggrs = append(sig.Aggrs, layoutOf(ftype.In(param.in)))
+		}
 	}
-	if ftype.NumOut() > 1 {
+	if returns {
 		sig.Returns = sigRune(ftype.Out(0))
+		if sig.Returns == dyncall.Aggregate {
+			sig.Aggrs = append(sig.Aggrs, layoutOf(ftype.Out(0)))
+		}
 	} else {
 		sig.Returns = dyncall.Void
 	}
 	return sig
 }
 
-func newCallback(signature dyncall.Signature, function reflect.Value) dyncall.CallbackHandler {
+// newCallback returns the handler of a C function pointer to the Go
+// function, with the given parameters, see [params]. The Go results
+// that are out-params are written to the pointers passed by C.
+func newCallback(signature dyncall.Signature, order []param, function reflect.Value) dyncall.CallbackHandler {
 	return func(cb *dyncall.Callback, args *dyncall.Args, result unsafe.Pointer) rune {
-		var values = make([]reflect.Value, len(signature.Args))
+		var values = make([]reflect.Value, function.Type().NumIn())
 		for i := range values {
 			values[i] = reflect.New(function.Type().In(i)).Elem()
 		}
-		for i := 0; i < len(signature.Args); i++ {
-			switch signature.Args[i] {
+		var outs = make([]unsafe.Pointer, function.Type().NumOut())
+		for j, param := range order {
+			if param.in < 0 {
+				outs[param.out] = unsafe.Pointer(args.Pointer())
+				continue
+			}
+			i := param.in
+			if param.length {
+				switch signature.Args[j] {
+				case dyncall.Int:
+					setLength(values[i], int(args.Int()))
+				case dyncall.Uint:
+					setLength(values[i], int(args.UnsignedInt()))
+				default:
+					setLength(values[i], int(args.LongLong()))
+				}
+				continue
+			}
+			switch signature.Args[j] {
 			case dyncall.Bool:
 				switch args.Bool() {
 				case 0:
@@ -150,16 +205,24 @@ func newCallback(signature dyncall.Signature, function reflect.Value) dyncall.Ca
 				ptr := args.Pointer()
 				switch values[i].Kind() {
 				case reflect.String:
-					values[i].SetString(C.GoString((*C.char)(ptr)))
+					values[i].SetString((*(*abi.String)(unsafe.Pointer(&ptr))).String())
 				case reflect.Struct:
 					values[i].Set(reflect.ValueOf(*(*abi.String)(unsafe.Pointer(&ptr))))
 				default:
 					panic("unsupported type " + values[i].Type().String())
 				}
 			case dyncall.Pointer:
+				ptr := unsafe.Pointer(args.Pointer())
+				if debug.escape {
+					checkEscape(ptr, "passed to a callback")
+				}
 				switch values[i].Kind() {
 				case reflect.UnsafePointer:
-					values[i].SetPointer(unsafe.Pointer(args.Pointer()))
+					values[i].SetPointer(ptr)
+				case reflect.Pointer, reflect.Struct, reflect.Slice:
+					// the length of a split slice or [abi.Buffer] is set
+					// after its pointer, see [setLength].
+					*(*unsafe.Pointer)(values[i].Addr().UnsafePointer()) = ptr
 				default:
 					settable, ok := values[i].Addr().Interface().(interface {
 						SetPointer(unsafe.Pointer)
@@ -167,13 +230,20 @@ func newCallback(signature dyncall.Signature, function reflect.Value) dyncall.Ca
 					if !ok {
 						panic("unsupported type " + values[i].Type().String())
 					}
-					settable.SetPointer(unsafe.Pointer(args.Pointer()))
+					settable.SetPointer(ptr)
 				}
+			case dyncall.Aggregate:
+				args.Aggr(values[i].Addr().UnsafePointer())
 			default:
-				panic("unsupported type " + string(signature.Args[i]))
+				panic("unsupported type " + string(signature.Args[j]))
+			}
+		}
+		results := callGo(function, values)
+		for i, ptr := range outs {
+			if ptr != nil {
+				writeOut(ptr, results[i])
 			}
 		}
-		results := function.Call(values)
 		switch signature.Returns {
 		case dyncall.Void:
 		case dyncall.Bool:
@@ -205,7 +275,15 @@ func newCallback(signature dyncall.Signature, function reflect.Value) dyncall.Ca
 		case dyncall.String:
 			*(*abi.String)(result) = abi.NewString(results[0].String()) // FIXME allocate in C memory?
 		case dyncall.Pointer:
-			*(*unsafe.Pointer)(result) = results[0].UnsafePointer()
+			if results[0].Kind() == reflect.Struct {
+				*(*unsafe.Pointer)(result) = pointerOf(results[0])
+			} else {
+				*(*unsafe.Pointer)(result) = results[0].UnsafePointer()
+			}
+		case dyncall.Aggregate:
+			value := reflect.New(results[0].Type())
+			value.Elem().Set(results[0])
+			args.ReturnAggr(result, value.UnsafePointer())
 		default:
 			panic("unsupported type " + results[0].Type().String())
 		}
@@ -213,194 +291,308 @@ func newCallback(signature dyncall.Signature, function reflect.Value) dyncall.Ca
 	}
 }
 
+// tag is a parsed `ffi` struct tag, made up of comma separated
+// symbol names, tried in order, and options.
+type tag struct {
+	symbols  []string
+	optional bool   // the symbol may be absent from the library.
+	keep     bool   // Go funcs passed to C are kept until Unlink, see [frame.thunk].
+	failure  string // the err= condition, see [failed].
+	out      string // the out= positions of the out-params, see [params].
+	length   string // the len= type of the length of split parameters, see [splits].
+	since    string // the version of the library that added the symbol, see [Versioned].
+}
+
+func parseTag(field reflect.StructField) tag {
+	name := field.Tag.Get("ffi")
+	if name == "" {
+		name = field.Name
+	}
+	var parsed tag
+	parsed.since = field.Tag.Get("since")
+	var positions bool // the parts are out= positions.
+	for _, part := range strings.Split(name, ",") {
+		part = strings.TrimSpace(part)
+		if positions && part != "" && strings.Trim(part, "0123456789") == "" {
+			parsed.out += "," + part
+			continue
+		}
+		positions = false
+		switch part {
+		case "":
+		case "optional":
+			parsed.optional = true
+		case "keep":
+			parsed.keep = true
+		default:
+			if cond, ok := strings.CutPrefix(part, "err="); ok {
+				parsed.failure = cond
+				continue
+			}
+			if out, ok := strings.CutPrefix(part, "out="); ok {
+				parsed.out, positions = out, true
+				continue
+			}
+			if length, ok := strings.CutPrefix(part, "len="); ok {
+				parsed.length = length
+				continue
+			}
+			parsed.symbols = append(parsed.symbols, part)
+		}
+	}
+	if len(parsed.symbols) == 0 {
+		parsed.symbols = []string{field.Name}
+	}
+	return parsed
+}
+
 // Set links the given library using the specified shared
-// library file name. The system linker will look for this
-// file in the system library paths.
+// library file name, or a comma separated list of names
+// to try in order. Each name is looked for in [SearchPath],
+// then in [SearchPathEnv] and finally in the system library
+// paths by the system linker. If none can be loaded, a
+// [*LoadError] is returned. If any symbols cannot be
+// resolved, a [*LinkError] is returned after the rest of
+// the library has been linked.
+//
+// Funcs whose last result is an error report failure through
+// the library's Error func() string field, if it has one, or
+// else as the [syscall.Errno] captured after the call. By
+// default a zero first result is a failure, the err= tag
+// option selects another condition, for example
+// `ffi:"remove,err=-1"`, `err=nil`, `err=nonzero` or `err=errno`.
+//
+// Any Go results after the first, other than an error, are passed
+// to C as pointers after the other parameters, as out-params. The
+// out= tag option lists C parameter positions instead, one for each
+// of the last results, in order, for example
+//
+//	Default func(iscapture abi.Int) (abi.Error, abi.String, AudioSpec) `ffi:"SDL_GetDefaultAudioInfo,out=0,1"`
+//
+// calls SDL_GetDefaultAudioInfo(&name, &spec, iscapture). If every
+// result, other than an error, is listed, the C function returns void.
+// A C string out-param may be returned as a Go string, which is copied
+// from C memory, or as an [abi.String] if the caller must free it. Go
+// funcs passed to C follow the same convention, with their out-params
+// after their parameters, or as given by the tag of an exported field,
+// see [Export].
+//
+// A Go slice is passed to C as a pointer to its first element, or nil
+// if it is empty, and an [abi.Buffer] as a pointer to its first byte,
+// followed by its length as a C int. The len= tag option names the C
+// type of the length, one of int, unsigned, long, size_t, int32, uint32,
+// int64 or uint64, and splits every slice into a pointer followed by its
+// number of elements, for example
+//
+//	Queue func(device AudioDevice, data []byte) abi.Error `ffi:"SDL_QueueAudio,len=uint32"`
+//
+// calls SDL_QueueAudio(device, &data[0], len(data)). A split parameter
+// counts as two C parameters for out= positions.
+//
+// Funcs whose first parameter is a [context.Context] and whose last
+// result is an error pass the rest of their arguments to C on a helper
+// thread, so that the caller can stop waiting for a blocking call. If
+// the context is done first, they return its error, whilst the C call
+// is left to return on the helper thread, which is then recycled, for
+// example
+//
+//	Wait func(ctx context.Context, event *Event) (abi.Int, error) `ffi:"SDL_WaitEvent"`
+//
+// Go memory passed to an abandoned call remains pinned, and may still
+// be written to by C, until C returns.
+//
+// If the [Library] field is tagged with `thread:"main"`, every
+// func is called on the main thread, see [LockMainThread] and [Main].
+//
+// Fields of type [Var], *T or [abi.Pointer] are bound to the data
+// symbols of C global variables, rather than functions.
+//
+// Calls are passed to the library's [Tracer], if it has one.
+//
+// If the [Library] field is tagged with `lazy:"true"`, each func field
+// is set to a stub that resolves its symbol on the first call, and then
+// atomically swaps itself out for the bound func, so that only the
+// symbols that are used are resolved. A missing symbol is reported by
+// the func, as if it were not linked, rather than by Set. Generated
+// bindings are not used for lazy funcs. See [Preload] to check every
+// symbol up front, for example in tests.
+//
+// A func field tagged with a since version, for example
+//
+//	Default func(iscapture abi.Int) (abi.Error, abi.String, AudioSpec) `ffi:"SDL_GetDefaultAudioInfo,out=0,1" since:"2.24"`
+//
+// is resolved lazily, once the version of the library, as reported by
+// its [Versioned] method, is known. If the library is older, the func
+// fails with [ErrUnavailable], rather than calling a symbol that is
+// missing, or that may not behave as expected. Such symbols are not
+// reported as missing by Set, see [Features].
+//
+// Go memory that is passed to C, such as a Go pointer, or the C string
+// that a Go string is converted to, is pinned for the duration of the
+// call. C must not keep a pointer to it after the call returns, unless
+// it has been retained, see [Retain].
+//
+// Likewise, a Go func passed to C is passed as a C function pointer
+// that is only valid for the duration of the call. The keep tag option
+// keeps it valid until the library is unlinked, for C functions that
+// keep the pointer, for example
+//
+//	OnExit func(func()) `ffi:"atexit,keep"`
+//
+// See [Callback] for Go funcs that C keeps for a shorter time.
 func Set(library Library, file string) error {
-	lib := dlopen(file)
-	if lib == nil {
-		return errors.New(dlerror())
+	// relinking a library releases its previous handle.
+	Unlink(library)
+
+	lazy := lazyTag(headerOf(library))
+	lib, file, err := load(file, lazy)
+	if err != nil {
+		return err
+	}
+	rec := &record{
+		file:   file,
+		handle: acquire(file, lib),
+		bound:  make(map[string]bool),
+		funcs:  make(map[string]binding),
 	}
 
 	rtype := reflect.TypeOf(library).Elem()
 	rvalue := reflect.ValueOf(library).Elem()
 
+	if thread := threadTag(headerOf(library)); thread != "" && thread != "main" {
+		panic("ffi: unsupported thread:\"" + thread + "\" tag on " + rtype.String())
+	}
+	tracer := tracerOf(library)
+
+	var missing []string
+
 	for i := 0; i < rtype.NumField(); i++ {
 		field := rtype.Field(i)
 		value := rvalue.Field(i)
 
-		if field.Type.Kind() != reflect.Func {
+		variable := isVariable(field.Type)
+		if field.Type.Kind() != reflect.Func && !variable {
 			continue
 		}
 
-		name := field.Tag.Get("ffi")
-		if name == "" {
-			name = field.Name
-		}
-		symbols := strings.Split(name, ",")
-		var symbol unsafe.Pointer
-		for _, name := range symbols {
-			symbol = dlsym(lib, name)
-			if symbol == nil {
-				continue
+		tag := parseTag(field)
+		if tag.since != "" {
+			if _, ok := library.(Versioned); !ok {
+				panic("ffi: " + field.Name + " has a since tag, but " + rtype.String() + " is not Versioned")
 			}
 		}
-		if symbol == nil {
-			log.Println(errors.New(dlerror()))
+		if (lazy || tag.since != "") && !variable {
+			rec.postpone(library, field, tag)
 			continue
 		}
-		getErr := rvalue.FieldByName("Error")
-
-		switch fn := value.Addr().Interface().(type) {
-		case *func(abi.Double) abi.Double:
-			*fn = func(a abi.Double) abi.Double {
-				vm := vm8.Get().(*dyncall.VM)
-				vm.Reset()
-				defer vm8.Put(vm)
-				vm.PushFloat64(float64(a))
-				return abi.Double(vm.CallFloat64(symbol))
+		symbol, resolved := lookup(lib, tag.symbols)
+		if symbol == nil {
+			name := strings.Join(tag.symbols, " or ")
+			if !tag.optional {
+				missing = append(missing, name)
 			}
-		case *func():
-			*fn = func() {
-				vm := dyncall.NewVM(0)
-				defer vm.Free()
-				vm.Call(symbol)
+			if variable {
+				bindVariable(value, nil, name)
+			} else {
+				value.Set(stub(field.Type, errors.New("ffi: "+name+" is not linked")))
 			}
-		default:
-			_ = fn
-			value.Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) []reflect.Value {
-				var vm = dyncall.NewVM(4096)
-				defer vm.Free()
-				push := func(value reflect.Value) {
-					switch value.Kind() {
-					case reflect.Bool:
-						vm.PushBool(value.Bool())
-					case reflect.Int8:
-						vm.PushInt8(int8(value.Int()))
-					case reflect.Int16:
-						vm.PushInt16(int16(value.Int()))
-					case reflect.Int32:
-						vm.PushInt32(int32(value.Int()))
-					case reflect.Int64:
-						vm.PushInt64(value.Int())
-					case reflect.Uint8:
-						u8 := uint8(value.Uint())
-						vm.PushInt8(*(*int8)(unsafe.Pointer(&u8)))
-					case reflect.Uint16:
-						u16 := uint16(value.Uint())
-						vm.PushInt16(*(*int16)(unsafe.Pointer(&u16)))
-					case reflect.Uint32:
-						u32 := uint32(value.Uint())
-						vm.PushInt32(*(*int32)(unsafe.Pointer(&u32)))
-					case reflect.Uint64:
-						u64 := uint64(value.Uint())
-						vm.PushInt64(*(*int64)(unsafe.Pointer(&u64)))
-					case reflect.Float32:
-						vm.PushFloat32(float32(value.Float()))
-					case reflect.Float64:
-						vm.PushFloat64(value.Float())
-					case reflect.Pointer, reflect.UnsafePointer:
-						vm.PushPointer(value.UnsafePointer())
-					case reflect.String:
-						s := abi.NewString(value.String())
-						vm.PushPointer(unsafe.Pointer(s.Pointer()))
-					case reflect.Struct:
-						if value.Type().Implements(reflect.TypeOf([0]abi.IsPointer{}).Elem()) {
-							vm.PushPointer(unsafe.Pointer(value.Interface().(abi.IsPointer).Pointer()))
-						} else {
-							panic("unsupported struct " + value.Type().String())
-						}
-					case reflect.Func:
-						signature := newSignature(value.Type())
-						ptr := dyncall.NewCallback(signature, newCallback(signature, value))
-						vm.PushPointer(unsafe.Pointer(ptr))
-					default:
-						panic("unsupported type " + value.Type().String())
-					}
-				}
-				for _, arg := range args {
-					push(arg)
-				}
-				var results = make([]reflect.Value, field.Type.NumOut())
-				for i := 0; i < field.Type.NumOut(); i++ {
-					results[i] = reflect.New(field.Type.Out(i)).Elem()
-				}
-				var returnsError bool
-				switch field.Type.NumOut() {
-				default:
-					if field.Type.NumOut() > 1 {
-						length := field.Type.NumOut()
-						if field.Type.Out(length-1) == reflect.TypeOf([0]error{}).Elem() {
-							returnsError = true
-							length--
-						}
-						for i := 1; i < length; i++ {
-							push(results[i].Addr())
-						}
-					}
-					fallthrough
-				case 1:
-					rtype := field.Type.Out(0)
-					switch field.Type.Out(0).Kind() {
-					case reflect.Bool:
-						results[0].SetBool(vm.CallBool(symbol))
-					case reflect.Int8:
-						results[0].SetInt(int64(vm.CallInt8(symbol)))
-					case reflect.Int16:
-						results[0].SetInt(int64(vm.CallInt16(symbol)))
-					case reflect.Int32:
-						results[0].SetInt(int64(vm.CallInt32(symbol)))
-					case reflect.Int64:
-						results[0].SetInt(int64(vm.CallInt64(symbol)))
-					case reflect.Uint8:
-						u8 := vm.CallInt8(symbol)
-						results[0].SetUint(uint64(*(*uint8)(unsafe.Pointer(&u8))))
-					case reflect.Uint16:
-						u16 := vm.CallInt16(symbol)
-						results[0].SetUint(uint64(*(*uint16)(unsafe.Pointer(&u16))))
-					case reflect.Uint32:
-						u32 := vm.CallInt32(symbol)
-						results[0].SetUint(uint64(*(*uint32)(unsafe.Pointer(&u32))))
-					case reflect.Uint64:
-						u64 := vm.CallInt64(symbol)
-						results[0].SetUint(uint64(*(*uint64)(unsafe.Pointer(&u64))))
-					case reflect.Float32:
-						results[0].SetFloat(float64(vm.CallFloat32(symbol)))
-					case reflect.Float64:
-						results[0].SetFloat(float64(vm.CallFloat64(symbol)))
-					case reflect.String:
-						results[0].SetString(C.GoString((*C.char)(vm.CallPointer(symbol))))
-					case reflect.UnsafePointer:
-						results[0].SetPointer(vm.CallPointer(symbol))
-					case reflect.Pointer:
-						results[0] = reflect.NewAt(field.Type.Out(0).Elem(), unsafe.Pointer(vm.CallPointer(symbol)))
-					case reflect.Struct:
-						if rtype.Implements(reflect.TypeOf([0]abi.IsPointer{}).Elem()) {
-							*(*unsafe.Pointer)(results[0].Addr().UnsafePointer()) = vm.CallPointer(symbol)
-						} else {
-							panic("unsupported struct " + field.Type.Out(0).String())
-						}
-					default:
-						panic("unsupported type " + field.Type.Out(0).String())
-					}
-				case 0:
-					vm.Call(symbol)
-				}
-				if returnsError {
-					if results[0].IsZero() {
-						if !getErr.IsValid() {
-							panic("an error occured")
-						}
-						switch fn := getErr.Interface().(type) {
-						case func() string:
-							results[len(results)-1] = reflect.ValueOf(errors.New(fn()))
-						}
-					}
-				}
-				return results
-			}))
+			continue
+		}
+		rec.bound[field.Name] = true
+
+		if variable {
+			bindVariable(value, symbol, resolved)
+			continue
 		}
+		rec.funcs[field.Name] = binding{symbol: symbol, name: resolved, tag: tag}
+		rec.bind(library, field, tracer)
 	}
 
+	mutex.Lock()
+	records[library] = rec
+	mutex.Unlock()
+
+	if len(missing) > 0 {
+		return &LinkError{Symbols: map[string][]string{file: missing}}
+	}
 	return nil
 }
+
+// lookup returns the first of the symbols that can be resolved
+// from the library, along with its name.
+func lookup(lib unsafe.Pointer, symbols []string) (unsafe.Pointer, string) {
+	for _, name := range symbols {
+		if symbol := dlsym(lib, name); symbol != nil {
+			return symbol, name
+		}
+	}
+	return nil, ""
+}
+
+// bind sets the func field of the library to call its symbol, through
+// the tracer, if it is not nil.
+func (rec *record) bind(library Library, field reflect.StructField, tracer Tracer) {
+	mutex.Lock()
+	b := rec.funcs[field.Name]
+	mutex.Unlock()
+	rvalue := reflect.ValueOf(library).Elem()
+	target := rvalue.FieldByIndex(field.Index)
+
+	// the funcs of a lazy library may already be called by other
+	// goroutines, so the func is built aside and then swapped in.
+	_, lazy := rec.pending[field.Name]
+	value := target
+	if lazy {
+		value = reflect.New(field.Type).Elem()
+	}
+
+	// funcs that take a context.Context call a func without
+	// the context on a helper thread, see [withContext].
+	outer, ctype := value, withoutContext(field.Type)
+	if ctype != nil {
+		if ctype.NumOut() == 0 || ctype.Out(ctype.NumOut()-1) != errorType {
+			panic("ffi: " + field.Name + " takes a context.Context, but does not return an error")
+		}
+		if threadTag(headerOf(library)) == "main" {
+			panic("ffi: " + field.Name + " takes a context.Context, but is called on the main thread")
+		}
+		field.Type = ctype
+		value = reflect.New(ctype).Elem()
+	}
+	if _, _, err := params(field.Type, b.tag.out, b.tag.length); err != nil {
+		panic("ffi: " + field.Name + ": " + err.Error())
+	}
+	c := call{
+		name:   b.name,
+		keep:   b.tag.keep,
+		fails:  failed(field, b.tag),
+		getErr: rvalue.FieldByName("Error"),
+	}
+
+	// generated bindings and common signatures are called
+	// without reflection, any other signature is compiled
+	// into a plan, once. Traced calls always go through a
+	// plan, so that there is no cost when tracing is off,
+	// as do calls checked by FFIDEBUG and calls with out=
+	// positions or len= lengths.
+	if tracer != nil {
+		c.trace = &site{tracer: tracer, library: library, file: rec.file, field: field.Name}
+		value.Set(planOf(field.Type, b.tag.out, b.tag.length).makeFunc(rec, b.symbol, c))
+	} else if debug.escape || b.tag.out != "" || b.tag.length != "" {
+		value.Set(planOf(field.Type, b.tag.out, b.tag.length).makeFunc(rec, b.symbol, c))
+	} else if bind, ok := staticOf(library)[field.Name]; ok && ctype == nil && !lazy {
+		bind(b.symbol)
+	} else if !fastpath(value.Addr().Interface(), b.symbol) {
+		value.Set(planOf(field.Type, "", "").makeFunc(rec, b.symbol, c))
+	}
+	if ctype != nil {
+		outer.Set(withContext(b.name, outer.Type(), value))
+		value = outer
+	}
+	if threadTag(headerOf(library)) == "main" {
+		value.Set(onMain(b.name, reflect.ValueOf(value.Interface())))
+	}
+	if lazy {
+		rec.swap(library, field, value)
+	}
+}
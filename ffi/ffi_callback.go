@@ -0,0 +1,169 @@
+package ffi
+
+import (
+	"reflect"
+	"unsafe"
+
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+// thunkKey identifies a Go func value, two func values share
+// a key when they refer to the same closure.
+type thunkKey struct {
+	ftype  reflect.Type
+	fn     unsafe.Pointer
+	out    string // the out= positions of its out-params, see [params].
+	length string // the len= type of its split parameters, see [splits].
+}
+
+// thunk is a C function pointer that calls a Go func, reference
+// counted so that it can be shared by every library it is
+// passed to and by any [Callback] that refers to it.
+type thunk struct {
+	cb    *dyncall.Callback
+	refs  int
+	owned int // number of references held by a [Callback].
+}
+
+var thunks = make(map[thunkKey]*thunk) // guarded by mutex.
+
+// keyOf returns the thunk key for the given Go func value.
+func keyOf(value reflect.Value) thunkKey {
+	fn := value.Interface()
+	return thunkKey{ftype: value.Type(), fn: (*[2]unsafe.Pointer)(unsafe.Pointer(&fn))[1]}
+}
+
+// retainThunk returns the thunk for the Go func, creating it if this
+// is the first reference to it. Must be called with the mutex.
+func retainThunk(key thunkKey, value reflect.Value) *dyncall.Callback {
+	if shared, ok := thunks[key]; ok {
+		shared.refs++
+		return shared.cb
+	}
+	order, returns, err := params(key.ftype, key.out, key.length)
+	if err != nil {
+		panic("ffi: " + key.ftype.String() + ": " + err.Error())
+	}
+	signature := newSignature(key.ftype, order, returns, key.length)
+	cb := dyncall.NewCallback(signature, newCallback(signature, order, value))
+	thunks[key] = &thunk{cb: cb, refs: 1}
+	return cb
+}
+
+// releaseThunk releases the thunk, freeing it once it is no longer
+// referenced. Must be called with the mutex.
+func releaseThunk(key thunkKey) {
+	shared := thunks[key]
+	shared.refs--
+	if shared.refs == 0 {
+		delete(thunks, key)
+		shared.cb.Free()
+	}
+}
+
+// thunk returns the C function pointer for a Go func that is passed
+// to a func of the library tagged keep, the same Go func value is
+// always passed as the same pointer, which remains valid until the
+// library is unlinked.
+func (rec *record) thunk(value reflect.Value) unsafe.Pointer {
+	if value.IsNil() {
+		return nil
+	}
+	key := keyOf(value)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if shared, ok := thunks[key]; ok && (shared.owned > 0 || rec.thunks[key]) {
+		return unsafe.Pointer(shared.cb) // its lifetime is managed elsewhere.
+	}
+	if rec.thunks == nil {
+		rec.thunks = make(map[thunkKey]bool)
+	}
+	rec.thunks[key] = true
+	return unsafe.Pointer(retainThunk(key, value))
+}
+
+// thunk returns the C function pointer for a Go func that is passed to
+// the function being called, which is valid until the call returns,
+// or until the library is unlinked if the func field is tagged keep.
+func (f *frame) thunk(value reflect.Value) unsafe.Pointer {
+	if f.keepFuncs {
+		return f.lib.thunk(value)
+	}
+	if value.IsNil() {
+		return nil
+	}
+	key := keyOf(value)
+	mutex.Lock()
+	defer mutex.Unlock()
+	f.thunks = append(f.thunks, key)
+	return unsafe.Pointer(retainThunk(key, value))
+}
+
+// release the thunks of the Go funcs that were passed to the call.
+func (f *frame) release() {
+	if len(f.thunks) == 0 {
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, key := range f.thunks {
+		releaseThunk(key)
+	}
+}
+
+// Callback is a C function pointer to a Go func of type F, that
+// remains valid until it is freed. Go funcs passed to a library
+// are otherwise only valid for the call, or are kept until the
+// library is unlinked, see [Set], so a Callback should be used for
+// funcs that C keeps after the call, but not for as long. Whilst a
+// Callback is live, passing its Func to a library does not extend
+// the lifetime of its C function pointer.
+//
+// C may call Go funcs from any thread, including threads that were
+// not created by Go, see [SetPanicHandler] and [Queued].
+type Callback[F any] struct {
+	fn  F
+	key thunkKey
+	ptr unsafe.Pointer
+}
+
+// NewCallback returns a new C function pointer to the given Go
+// func, F must be a func type.
+func NewCallback[F any](fn F) *Callback[F] {
+	value := reflect.ValueOf(&fn).Elem()
+	if value.Kind() != reflect.Func {
+		panic("ffi.NewCallback: " + value.Type().String() + " is not a func")
+	}
+	if value.IsNil() {
+		panic("ffi.NewCallback: nil func")
+	}
+	key := keyOf(value)
+	mutex.Lock()
+	defer mutex.Unlock()
+	ptr := unsafe.Pointer(retainThunk(key, value))
+	thunks[key].owned++
+	return &Callback[F]{fn: fn, key: key, ptr: ptr}
+}
+
+// Func returns the Go func, whenever it is passed to a library
+// function, it is passed as the callback's C function pointer.
+func (cb *Callback[F]) Func() F { return cb.fn }
+
+// Pointer returns the C function pointer, or nil if
+// the callback has been freed.
+func (cb *Callback[F]) Pointer() unsafe.Pointer { return cb.ptr }
+
+// Free releases the C function pointer, unless the Go func was also
+// passed to a linked library before the callback was created. Free
+// must not be called whilst C may still call the pointer. Calling Free
+// more than once has no effect.
+func (cb *Callback[F]) Free() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if cb.ptr == nil {
+		return
+	}
+	thunks[cb.key].owned--
+	releaseThunk(cb.key)
+	cb.ptr = nil
+}
@@ -0,0 +1,143 @@
+package ffi
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+var contextType = reflect.TypeOf([0]context.Context{}).Elem()
+
+// withoutContext returns the type of the func that calls the C function
+// of a func field that takes a [context.Context] as its first parameter,
+// which is the same func type without the context, or nil if ftype does
+// not take a context.
+func withoutContext(ftype reflect.Type) reflect.Type {
+	if ftype.NumIn() == 0 || ftype.In(0) != contextType {
+		return nil
+	}
+	ins := make([]reflect.Type, ftype.NumIn()-1)
+	for i := range ins {
+		ins[i] = ftype.In(i + 1)
+	}
+	outs := make([]reflect.Type, ftype.NumOut())
+	for i := range outs {
+		outs[i] = ftype.Out(i)
+	}
+	return reflect.FuncOf(ins, outs, ftype.IsVariadic())
+}
+
+// maxIdleHelpers is the number of helper threads kept for reuse,
+// any others exit once their call returns.
+const maxIdleHelpers = 4
+
+// helper is a goroutine locked to its own OS thread, that makes the C
+// calls of funcs that take a [context.Context], so that their callers
+// can stop waiting once the context is done.
+type helper struct {
+	calls chan helperCall
+}
+
+type helperCall struct {
+	fn   func()
+	done chan struct{}
+}
+
+var helpers struct {
+	mutex sync.Mutex
+	idle  []*helper
+}
+
+// getHelper returns an idle helper, or starts a new one.
+func getHelper() *helper {
+	helpers.mutex.Lock()
+	if n := len(helpers.idle); n > 0 {
+		h := helpers.idle[n-1]
+		helpers.idle = helpers.idle[:n-1]
+		helpers.mutex.Unlock()
+		return h
+	}
+	helpers.mutex.Unlock()
+	h := &helper{calls: make(chan helperCall)}
+	go h.run()
+	return h
+}
+
+// run the calls sent to the helper. A helper whose caller stopped
+// waiting is not reused until its call returns, as the thread is
+// still blocked in C, it is then recycled, or exits along with its
+// thread if enough helpers are idle.
+func (h *helper) run() {
+	runtime.LockOSThread() // never unlocked, so that the thread exits with the helper.
+	for call := range h.calls {
+		call.fn()
+		helpers.mutex.Lock()
+		idle := len(helpers.idle) < maxIdleHelpers
+		if idle {
+			helpers.idle = append(helpers.idle, h)
+		}
+		helpers.mutex.Unlock()
+		close(call.done)
+		if !idle {
+			return
+		}
+	}
+}
+
+// withContext returns a func of the given type, whose first parameter
+// is a [context.Context] and whose last result is an error, that calls
+// fn with the rest of its arguments on a helper thread. If the context
+// is done before fn returns, the func returns zero results along with
+// the error of the context, without waiting for fn. Contexts that are
+// never done, such as [context.Background], call fn directly.
+func withContext(symbol string, ftype reflect.Type, fn reflect.Value) reflect.Value {
+	call := fn.Call
+	if ftype.IsVariadic() {
+		call = fn.CallSlice
+	}
+	return reflect.MakeFunc(ftype, func(args []reflect.Value) []reflect.Value {
+		ctx, _ := args[0].Interface().(context.Context)
+		if ctx == nil {
+			panic("ffi: " + symbol + " called with a nil context.Context")
+		}
+		if ctx.Done() == nil {
+			return call(args[1:])
+		}
+		if err := ctx.Err(); err != nil {
+			return cancelled(ftype, err)
+		}
+		var (
+			results []reflect.Value
+			failure any
+			done    = make(chan struct{})
+		)
+		getHelper().calls <- helperCall{
+			fn: func() {
+				defer func() { failure = recover() }()
+				results = call(args[1:])
+			},
+			done: done,
+		}
+		select {
+		case <-done:
+			if failure != nil {
+				panic(failure)
+			}
+			return results
+		case <-ctx.Done():
+			return cancelled(ftype, ctx.Err())
+		}
+	})
+}
+
+// cancelled returns the results of a func of the given type
+// whose context is done.
+func cancelled(ftype reflect.Type, err error) []reflect.Value {
+	results := make([]reflect.Value, ftype.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(ftype.Out(i))
+	}
+	results[len(results)-1] = reflect.ValueOf(&err).Elem()
+	return results
+}
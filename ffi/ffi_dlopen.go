@@ -9,9 +9,12 @@ import (
 	"unsafe"
 )
 
-func dlopen(filename string) (handle unsafe.Pointer) {
+func dlopen(filename string, lazy bool) (handle unsafe.Pointer) {
 	s := C.CString(filename + "\x00")
 	defer C.free(unsafe.Pointer(s))
+	if lazy {
+		return C.dlopen(s, C.RTLD_LAZY)
+	}
 	return C.dlopen(s, C.RTLD_NOW)
 }
 
@@ -24,3 +27,7 @@ func dlsym(handle unsafe.Pointer, symbol string) unsafe.Pointer {
 	defer C.free(unsafe.Pointer(s))
 	return C.dlsym(handle, s)
 }
+
+func dlclose(handle unsafe.Pointer) {
+	C.dlclose(handle)
+}
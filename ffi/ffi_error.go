@@ -0,0 +1,92 @@
+package ffi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// LinkError reports every symbol that could not be resolved
+// whilst linking, keyed by shared library file name.
+type LinkError struct {
+	Symbols map[string][]string
+}
+
+// Error implements the error interface.
+func (err *LinkError) Error() string {
+	var files []string
+	for file := range err.Symbols {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var b strings.Builder
+	b.WriteString("ffi: unresolved symbols")
+	for i, file := range files {
+		if i > 0 {
+			b.WriteString(";")
+		}
+		b.WriteString(" in " + file + ": ")
+		b.WriteString(strings.Join(err.Symbols[file], ", "))
+	}
+	return b.String()
+}
+
+func (err *LinkError) merge(other *LinkError) {
+	if err.Symbols == nil {
+		err.Symbols = make(map[string][]string)
+	}
+	for file, symbols := range other.Symbols {
+		err.Symbols[file] = append(err.Symbols[file], symbols...)
+	}
+}
+
+// failed returns the failure condition of a func field whose last
+// result is an error, or nil if the field does not return an error.
+// The condition applies to the C return value and is one of:
+//
+//   - "" (the default), a zero return value is a failure.
+//   - "nil", a nil pointer return value is a failure.
+//   - "-1", a return value of -1 is a failure.
+//   - "nonzero", a non-zero return value is a failure.
+//   - "errno", the call failed if it set errno.
+//
+// A func without a C return value, such as one whose only result is
+// an error, fails if errno was set.
+func failed(field reflect.StructField, t tag) func(result reflect.Value, errno syscall.Errno) bool {
+	ftype, cond := field.Type, t.failure
+	if ftype.NumOut() == 0 || ftype.Out(ftype.NumOut()-1) != errorType {
+		return nil
+	}
+	_, returns, _ := params(ftype, t.out, t.length)
+	if !returns && cond != "" && cond != "errno" {
+		panic("ffi: " + field.Name + " has no C return value for err=" + cond)
+	}
+	switch cond {
+	case "":
+		if !returns {
+			return func(_ reflect.Value, errno syscall.Errno) bool { return errno != 0 }
+		}
+		return func(result reflect.Value, _ syscall.Errno) bool { return result.IsZero() }
+	case "nil":
+		switch ftype.Out(0).Kind() {
+		case reflect.Pointer, reflect.UnsafePointer, reflect.Struct:
+		default:
+			panic("ffi: " + field.Name + " does not return a pointer for err=nil")
+		}
+		return func(result reflect.Value, _ syscall.Errno) bool { return result.IsZero() }
+	case "-1":
+		switch ftype.Out(0).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		default:
+			panic("ffi: " + field.Name + " does not return a signed integer for err=-1")
+		}
+		return func(result reflect.Value, _ syscall.Errno) bool { return result.Int() == -1 }
+	case "nonzero":
+		return func(result reflect.Value, _ syscall.Errno) bool { return !result.IsZero() }
+	case "errno":
+		return func(_ reflect.Value, errno syscall.Errno) bool { return errno != 0 }
+	default:
+		panic("ffi: " + field.Name + " has an unknown err=" + cond + " condition")
+	}
+}
@@ -0,0 +1,70 @@
+//go:build ffidebug
+
+package ffi
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// FFIDEBUG=escape=1 looks up Go objects and checks Go pointers through
+// the runtime's internals, which are only linked with the ffidebug tag,
+// so that other builds do not depend on them.
+const escapeChecks = true
+
+// pinned counts the pins on each Go object, by its base address,
+// whilst FFIDEBUG=escape=1, so that escaped pointers can be found.
+var pinned struct {
+	mutex   sync.Mutex
+	objects map[uintptr]int
+}
+
+//go:linkname findObject runtime.findObject
+func findObject(p, refBase, refOff uintptr) (base uintptr, span unsafe.Pointer, index uintptr)
+
+//go:linkname cgoCheckPointer runtime.cgoCheckPointer
+func cgoCheckPointer(ptr any, arg any)
+
+// track adds delta pins to the Go object that ptr points into.
+func track(ptr unsafe.Pointer, delta int) {
+	base, _, _ := findObject(uintptr(ptr), 0, 0)
+	if base == 0 {
+		return // not in the Go heap.
+	}
+	pinned.mutex.Lock()
+	defer pinned.mutex.Unlock()
+	if pinned.objects == nil {
+		pinned.objects = make(map[uintptr]int)
+	}
+	pinned.objects[base] += delta
+	if pinned.objects[base] == 0 {
+		delete(pinned.objects, base)
+	}
+}
+
+// checkEscape panics if ptr points into a Go object that is not pinned,
+// as C must have kept it after the call that it was passed to returned.
+func checkEscape(ptr unsafe.Pointer, how string) {
+	base, _, _ := findObject(uintptr(ptr), 0, 0)
+	if base == 0 {
+		return
+	}
+	pinned.mutex.Lock()
+	defer pinned.mutex.Unlock()
+	if pinned.objects[base] == 0 {
+		panic(fmt.Sprintf("ffi: Go pointer %p escaped into C, it was %s after the call that it was passed to returned, see ffi.Retain", ptr, how))
+	}
+}
+
+// checkPointer panics if the Go memory that the pointer value
+// points to holds any unpinned Go pointers.
+func checkPointer(value reflect.Value, to string) {
+	defer func() {
+		if err := recover(); err != nil {
+			panic(fmt.Sprintf("ffi: %v passed to %s: %v", value.Type(), to, err))
+		}
+	}()
+	cgoCheckPointer(value.Interface(), true)
+}
@@ -0,0 +1,379 @@
+package ffi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"qlova.tech/abi"
+)
+
+// Exports is a table of C function pointers to the Go funcs of a
+// library, so that C code can call into Go, see [Export].
+type Exports struct {
+	table  []unsafe.Pointer
+	keys   []thunkKey // of the non-nil entries.
+	pinner runtime.Pinner
+}
+
+// Export returns a table of C function pointers to the func fields
+// of the library, in the order that they are declared, such that it
+// can be passed to C as a pointer to the struct written by
+// [ExportHeader]. Nil funcs are exported as NULL. The table remains
+// valid until it is freed, so it can be passed to the init function
+// of a native plugin, that has been linked with [Set], as the API of
+// the host program.
+func Export(library Library) (*Exports, error) {
+	rtype := reflect.TypeOf(library).Elem()
+	rvalue := reflect.ValueOf(library).Elem()
+
+	var h header
+	var unsupported []error
+	var fields []int
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		if field.Type.Kind() != reflect.Func {
+			continue
+		}
+		tag := parseTag(field)
+		if _, err := h.function(field.Type, field.Name, tag.out, tag.length); err != nil {
+			unsupported = append(unsupported, fmt.Errorf("ffi: cannot export %v: %w", field.Name, err))
+			continue
+		}
+		fields = append(fields, i)
+	}
+	if len(unsupported) > 0 {
+		return nil, errors.Join(unsupported...)
+	}
+
+	exports := &Exports{table: make([]unsafe.Pointer, len(fields))}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, index := range fields {
+		value := rvalue.Field(index)
+		if value.IsNil() {
+			continue
+		}
+		tag := parseTag(rtype.Field(index))
+		key := keyOf(value)
+		key.out, key.length = tag.out, tag.length
+		exports.table[i] = unsafe.Pointer(retainThunk(key, value))
+		thunks[key].owned++
+		exports.keys = append(exports.keys, key)
+	}
+	if len(exports.table) > 0 {
+		exports.pinner.Pin(&exports.table[0])
+	}
+	return exports, nil
+}
+
+// Pointer returns the C pointer to the table, or nil if the
+// table is empty or has been freed.
+func (e *Exports) Pointer() unsafe.Pointer {
+	if len(e.table) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&e.table[0])
+}
+
+// Free releases the table and its C function pointers, it must not
+// be called whilst C may still use them. Calling Free more than once
+// has no effect.
+func (e *Exports) Free() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, key := range e.keys {
+		thunks[key].owned--
+		releaseThunk(key)
+	}
+	e.pinner.Unpin()
+	e.table, e.keys = nil, nil
+}
+
+// ExportHeader writes a C header to w that declares the table returned
+// by [Export] for the library, as a struct with the given name, along
+// with any structs that its funcs pass by value. Each entry is named
+// after the first symbol in the `ffi` tag of its field, or else after
+// the field. Out-params are declared as pointers, see [Set]. For example,
+//
+//	type Host struct {
+//		ffi.Library
+//
+//		Log func(string)               `ffi:"log"`
+//		Add func(a, b abi.Int) abi.Int `ffi:"add"`
+//	}
+//
+// is declared as
+//
+//	struct host {
+//		void (*log)(const char *);
+//		int (*add)(int, int);
+//	};
+func ExportHeader(w io.Writer, name string, library Library) error {
+	rtype := reflect.TypeOf(library).Elem()
+
+	var h header
+	var entries []string
+	var unsupported []error
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		if field.Type.Kind() != reflect.Func {
+			continue
+		}
+		tag := parseTag(field)
+		decl, err := h.function(field.Type, tag.symbols[0], tag.out, tag.length)
+		if err != nil {
+			unsupported = append(unsupported, fmt.Errorf("ffi: cannot export %v: %w", field.Name, err))
+			continue
+		}
+		entries = append(entries, decl)
+	}
+	if len(unsupported) > 0 {
+		return errors.Join(unsupported...)
+	}
+
+	guard := strings.ToUpper(name) + "_H"
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by ffi.ExportHeader; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "#ifndef %s\n#define %[1]s\n\n", guard)
+	b.WriteString("#include <stdbool.h>\n#include <stddef.h>\n#include <stdint.h>\n\n")
+	for _, opaque := range h.opaque {
+		fmt.Fprintf(&b, "struct %s;\n", opaque)
+	}
+	if len(h.opaque) > 0 {
+		b.WriteString("\n")
+	}
+	for _, def := range h.structs {
+		b.WriteString(def)
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "struct %s {\n", name)
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\t%s;\n", entry)
+	}
+	fmt.Fprintf(&b, "};\n\n#endif // %s\n", guard)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// header collects the C declarations that the
+// entries of an exported table refer to.
+type header struct {
+	structs []string // definitions, in dependency order.
+	opaque  []string // forward declarations.
+	defined map[reflect.Type]bool
+}
+
+// ctypes are the C names of the [abi] types that do not
+// have the same name as their fixed width equivalent.
+var ctypes = map[reflect.Type]string{
+	reflect.TypeOf(abi.Bool(false)):         "bool",
+	reflect.TypeOf(abi.Char(0)):             "char",
+	reflect.TypeOf(abi.CharSigned(0)):       "signed char",
+	reflect.TypeOf(abi.CharUnsigned(0)):     "unsigned char",
+	reflect.TypeOf(abi.CharWide(0)):         "wchar_t",
+	reflect.TypeOf(abi.Short(0)):            "short",
+	reflect.TypeOf(abi.ShortUnsigned(0)):    "unsigned short",
+	reflect.TypeOf(abi.Int(0)):              "int",
+	reflect.TypeOf(abi.IntUnsigned(0)):      "unsigned int",
+	reflect.TypeOf(abi.Long(0)):             "long",
+	reflect.TypeOf(abi.LongUnsigned(0)):     "unsigned long",
+	reflect.TypeOf(abi.LongLong(0)):         "long long",
+	reflect.TypeOf(abi.LongLongUnsigned(0)): "unsigned long long",
+	reflect.TypeOf(abi.Float(0)):            "float",
+	reflect.TypeOf(abi.Double(0)):           "double",
+	reflect.TypeOf(abi.Size(0)):             "size_t",
+	reflect.TypeOf(abi.Ptrdiff(0)):          "ptrdiff_t",
+	reflect.TypeOf(abi.Intptr(0)):           "intptr_t",
+	reflect.TypeOf(abi.IntMax(0)):           "intmax_t",
+	reflect.TypeOf(abi.UIntMax(0)):          "uintmax_t",
+	reflect.TypeOf(abi.ComplexFloat{}):      "float _Complex",
+	reflect.TypeOf(abi.ComplexDouble{}):     "double _Complex",
+	reflect.TypeOf(abi.String{}):            "const char *",
+	reflect.TypeOf(abi.UnsafePointer(nil)):  "void *",
+	reflect.TypeOf(unsafe.Pointer(nil)):     "void *",
+}
+
+// decl returns the C declaration of name as the given Go type, name
+// is empty for an abstract declaration, such as a parameter.
+func (h *header) decl(t reflect.Type, name string) (string, error) {
+	if spec, ok := ctypes[t]; ok {
+		return join(spec, name), nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return join("bool", name), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return join("int"+strconv.Itoa(int(t.Size())*8)+"_t", name), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return join("uint"+strconv.Itoa(int(t.Size())*8)+"_t", name), nil
+	case reflect.Float32:
+		return join("float", name), nil
+	case reflect.Float64:
+		return join("double", name), nil
+	case reflect.String:
+		return join("const char *", name), nil
+	case reflect.UnsafePointer:
+		return join("void *", name), nil
+	case reflect.Pointer:
+		return h.pointer(t.Elem(), name)
+	case reflect.Func:
+		return "", errors.New("Go funcs cannot be passed to C, use an abi.Func")
+	case reflect.Array:
+		if isLongDouble(t) {
+			return "", errors.New("long double is not supported")
+		}
+		if isAggregate(t) {
+			return "", errors.New("arrays of " + t.Elem().String() + " cannot be passed by value")
+		}
+		return h.pointer(t.Elem(), name)
+	case reflect.Struct:
+		if isPointerLike(t) {
+			return h.pointerLike(t, name)
+		}
+		if t.Name() == "" || strings.Contains(t.Name(), "[") {
+			return "", errors.New("struct " + t.String() + " has no C name")
+		}
+		if err := h.define(t); err != nil {
+			return "", err
+		}
+		return join("struct "+t.Name(), name), nil
+	default:
+		return "", errors.New("unsupported type " + t.String())
+	}
+}
+
+// pointer returns the C declaration of name as a pointer to elem.
+func (h *header) pointer(elem reflect.Type, name string) (string, error) {
+	return h.decl(elem, "*"+name)
+}
+
+// pointerLike returns the C declaration of name as an [abi.Pointer],
+// [abi.Func] or [abi.Opaque], which refer to their element type
+// through their first field.
+func (h *header) pointerLike(t reflect.Type, name string) (string, error) {
+	if t.NumField() < 2 || t.Field(0).Type.Kind() != reflect.Array || t.Field(0).Type.Elem().Kind() != reflect.Pointer {
+		return join("void *", name), nil
+	}
+	elem := t.Field(0).Type.Elem().Elem()
+	switch {
+	case elem.Kind() == reflect.Func:
+		return h.function(elem, name, "", "")
+	case t.Field(1).Name != "opaque":
+		return h.pointer(elem, name)
+	}
+	if elem.Name() == "" {
+		return join("void *", name), nil
+	}
+	if !h.defined[elem] {
+		h.mark(elem)
+		h.opaque = append(h.opaque, elem.Name())
+	}
+	return join("struct "+elem.Name()+" *", name), nil
+}
+
+// function returns the C declaration of name as a pointer to a C
+// function of the given Go func type, with out-params at the given
+// positions and split parameters with lengths of the given type, see
+// [params].
+func (h *header) function(t reflect.Type, name, out, length string) (string, error) {
+	if t.IsVariadic() {
+		return "", errors.New("variadic funcs cannot be exported")
+	}
+	if t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType {
+		return "", errors.New("funcs that return an error cannot be exported")
+	}
+	if _, ok := lengths[strings.ToLower(length)]; !ok && length != "" {
+		return "", errors.New("unknown len=" + length + " type")
+	}
+	order, returns, err := params(t, out, length)
+	if err != nil {
+		return "", err
+	}
+	var decls []string
+	for _, param := range order {
+		var decl string
+		var err error
+		switch {
+		case param.in < 0:
+			decl, err = h.pointer(t.Out(param.out), "")
+		case param.length:
+			decl, err = h.decl(lengthType(length), "")
+		case t.In(param.in) == bufferType:
+			decl, err = h.pointer(typeOf[abi.Uint8](), "")
+		case splits(t, param.in, length):
+			decl, err = h.pointer(t.In(param.in).Elem(), "")
+		default:
+			decl, err = h.decl(t.In(param.in), "")
+		}
+		if err != nil {
+			return "", err
+		}
+		decls = append(decls, decl)
+	}
+	if len(decls) == 0 {
+		decls = append(decls, "void")
+	}
+	result := "void"
+	if returns {
+		out, err := h.decl(t.Out(0), "")
+		if err != nil {
+			return "", err
+		}
+		result = out
+	}
+	return join(result, "(*"+name+")("+strings.Join(decls, ", ")+")"), nil
+}
+
+// define adds the definition of the given Go struct as a C
+// struct, after the definitions of the structs it contains.
+func (h *header) define(t reflect.Type) error {
+	if h.defined[t] {
+		return nil
+	}
+	h.mark(t)
+	var b strings.Builder
+	fmt.Fprintf(&b, "struct %s {\n", t.Name())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Size() == 0 {
+			continue
+		}
+		if isLongDouble(field.Type) {
+			return errors.New("long double is not supported")
+		}
+		elem, length := field.Type, ""
+		if _, ok := ctypes[elem]; !ok && elem.Kind() == reflect.Array {
+			elem, length = elem.Elem(), "["+strconv.Itoa(elem.Len())+"]"
+		}
+		decl, err := h.decl(elem, field.Name+length)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "\t%s;\n", decl)
+	}
+	b.WriteString("};\n")
+	h.structs = append(h.structs, b.String())
+	return nil
+}
+
+func (h *header) mark(t reflect.Type) {
+	if h.defined == nil {
+		h.defined = make(map[reflect.Type]bool)
+	}
+	h.defined[t] = true
+}
+
+// join the C type specifier with the declarator, such that
+// pointers are written as "char *name", not "char * name".
+func join(spec, declarator string) string {
+	if declarator == "" || strings.HasSuffix(spec, "*") {
+		return spec + declarator
+	}
+	return spec + " " + declarator
+}
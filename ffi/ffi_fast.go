@@ -0,0 +1,646 @@
+package ffi
+
+import (
+	"reflect"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"qlova.tech/abi"
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+// fastpath sets fn (a pointer to a func field) to a function that
+// calls symbol without any reflection, if the func has one of the
+// common signatures below. Reports whether fn was set.
+func fastpath(fn any, symbol unsafe.Pointer) bool {
+	switch fn := fn.(type) {
+	case *func() abi.Int:
+		*fn = fn0[abi.Int](symbol)
+	case *func(abi.Int):
+		*fn = proc1[abi.Int](symbol)
+	case *func(abi.Int) abi.Int:
+		*fn = fn1[abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int):
+		*fn = proc2[abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int) abi.Int:
+		*fn = fn2[abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int):
+		*fn = proc3[abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int) abi.Int:
+		*fn = fn3[abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int, abi.Int):
+		*fn = proc4[abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int, abi.Int) abi.Int:
+		*fn = fn4[abi.Int, abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int, abi.Int, abi.Int):
+		*fn = proc5[abi.Int, abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int, abi.Int, abi.Int) abi.Int:
+		*fn = fn5[abi.Int, abi.Int, abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int, abi.Int, abi.Int, abi.Int):
+		*fn = proc6[abi.Int, abi.Int, abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func(abi.Int, abi.Int, abi.Int, abi.Int, abi.Int, abi.Int) abi.Int:
+		*fn = fn6[abi.Int, abi.Int, abi.Int, abi.Int, abi.Int, abi.Int, abi.Int](symbol)
+	case *func() abi.Long:
+		*fn = fn0[abi.Long](symbol)
+	case *func(abi.Long):
+		*fn = proc1[abi.Long](symbol)
+	case *func(abi.Long) abi.Long:
+		*fn = fn1[abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long):
+		*fn = proc2[abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long) abi.Long:
+		*fn = fn2[abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long):
+		*fn = proc3[abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long) abi.Long:
+		*fn = fn3[abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long, abi.Long):
+		*fn = proc4[abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long, abi.Long) abi.Long:
+		*fn = fn4[abi.Long, abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long, abi.Long, abi.Long):
+		*fn = proc5[abi.Long, abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long, abi.Long, abi.Long) abi.Long:
+		*fn = fn5[abi.Long, abi.Long, abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long, abi.Long, abi.Long, abi.Long):
+		*fn = proc6[abi.Long, abi.Long, abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func(abi.Long, abi.Long, abi.Long, abi.Long, abi.Long, abi.Long) abi.Long:
+		*fn = fn6[abi.Long, abi.Long, abi.Long, abi.Long, abi.Long, abi.Long, abi.Long](symbol)
+	case *func() abi.UnsafePointer:
+		*fn = fn0[abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer):
+		*fn = proc1[abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer) abi.UnsafePointer:
+		*fn = fn1[abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer):
+		*fn = proc2[abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer) abi.UnsafePointer:
+		*fn = fn2[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer):
+		*fn = proc3[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer) abi.UnsafePointer:
+		*fn = fn3[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer):
+		*fn = proc4[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer) abi.UnsafePointer:
+		*fn = fn4[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer):
+		*fn = proc5[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer) abi.UnsafePointer:
+		*fn = fn5[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer):
+		*fn = proc6[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer) abi.UnsafePointer:
+		*fn = fn6[abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer, abi.UnsafePointer](symbol)
+	case *func():
+		*fn = proc0(symbol)
+	case *func(abi.Double, abi.Double, abi.Double) abi.Double:
+		*fn = fn3[abi.Double, abi.Double, abi.Double, abi.Double](symbol)
+	case *func(abi.Double) abi.Int:
+		*fn = fn1[abi.Double, abi.Int](symbol)
+	case *func(abi.Double) abi.Long:
+		*fn = fn1[abi.Double, abi.Long](symbol)
+	case *func(abi.Double, abi.Int) abi.Double:
+		*fn = fn2[abi.Double, abi.Int, abi.Double](symbol)
+	case *func(abi.Int) abi.Double:
+		*fn = fn1[abi.Int, abi.Double](symbol)
+	case *func(abi.Float, abi.Float, abi.Float) abi.Float:
+		*fn = fn3[abi.Float, abi.Float, abi.Float, abi.Float](symbol)
+	case *func(abi.Float) abi.Int:
+		*fn = fn1[abi.Float, abi.Int](symbol)
+	case *func(abi.Float) abi.Long:
+		*fn = fn1[abi.Float, abi.Long](symbol)
+	case *func(abi.Float, abi.Int) abi.Float:
+		*fn = fn2[abi.Float, abi.Int, abi.Float](symbol)
+	case *func(abi.Int) abi.Float:
+		*fn = fn1[abi.Int, abi.Float](symbol)
+	case *func(abi.Double) abi.Double:
+		*fn = fn1[abi.Double, abi.Double](symbol)
+	case *func(abi.Double, abi.Double) abi.Double:
+		*fn = fn2[abi.Double, abi.Double, abi.Double](symbol)
+	case *func(abi.Float) abi.Float:
+		*fn = fn1[abi.Float, abi.Float](symbol)
+	case *func(abi.Float, abi.Float) abi.Float:
+		*fn = fn2[abi.Float, abi.Float, abi.Float](symbol)
+	case *func(abi.Size) abi.UnsafePointer:
+		*fn = fn1[abi.Size, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.Size) abi.UnsafePointer:
+		*fn = fn2[abi.UnsafePointer, abi.Size, abi.UnsafePointer](symbol)
+	case *func(abi.Size, abi.Size) abi.UnsafePointer:
+		*fn = fn2[abi.Size, abi.Size, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.UnsafePointer:
+		*fn = fn3[abi.UnsafePointer, abi.UnsafePointer, abi.Size, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.Int, abi.Size) abi.UnsafePointer:
+		*fn = fn3[abi.UnsafePointer, abi.Int, abi.Size, abi.UnsafePointer](symbol)
+	case *func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.Int:
+		*fn = fn3[abi.UnsafePointer, abi.UnsafePointer, abi.Size, abi.Int](symbol)
+	default:
+		return false
+	}
+	return true
+}
+
+// typeOf returns the reflect.Type of T.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf([0]T{}).Elem()
+}
+
+// scalarRune returns the dyncall signature kind of a scalar type.
+func scalarRune(t reflect.Type) rune {
+	switch t.Kind() {
+	case reflect.Bool:
+		return dyncall.Bool
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return intRune(t.Size(), true)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return intRune(t.Size(), false)
+	default:
+		return sigRune(t)
+	}
+}
+
+// word returns the raw little-endian bits of a scalar value.
+func word[T any](v T) (bits uint64) {
+	*(*T)(unsafe.Pointer(&bits)) = v
+	return
+}
+
+// scalar converts raw little-endian bits back into a scalar value.
+func scalar[T any](bits uint64) T {
+	return *(*T)(unsafe.Pointer(&bits))
+}
+
+func proc0(symbol unsafe.Pointer) func() {
+	call := wordCaller(dyncall.Void)
+	return func() {
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		call(vm, symbol)
+		vms.Put(vm)
+	}
+}
+
+func proc1[A any](symbol unsafe.Pointer) func(A) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pins := pointerWords(typeOf[A]())
+	call := wordCaller(dyncall.Void)
+	return func(a A) {
+		pinned := pinWords(pins, word(a))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		call(vm, symbol)
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+	}
+}
+
+func proc2[A, B any](symbol unsafe.Pointer) func(A, B) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pins := pointerWords(typeOf[A](), typeOf[B]())
+	call := wordCaller(dyncall.Void)
+	return func(a A, b B) {
+		pinned := pinWords(pins, word(a), word(b))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		call(vm, symbol)
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+	}
+}
+
+func proc3[A, B, C any](symbol unsafe.Pointer) func(A, B, C) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C]())
+	call := wordCaller(dyncall.Void)
+	return func(a A, b B, c C) {
+		pinned := pinWords(pins, word(a), word(b), word(c))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		call(vm, symbol)
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+	}
+}
+
+func proc4[A, B, C, D any](symbol unsafe.Pointer) func(A, B, C, D) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D]())
+	call := wordCaller(dyncall.Void)
+	return func(a A, b B, c C, d D) {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		call(vm, symbol)
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+	}
+}
+
+func proc5[A, B, C, D, E any](symbol unsafe.Pointer) func(A, B, C, D, E) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pushE := wordPusher(scalarRune(typeOf[E]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E]())
+	call := wordCaller(dyncall.Void)
+	return func(a A, b B, c C, d D, e E) {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d), word(e))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		pushE(vm, word(e))
+		call(vm, symbol)
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(e)
+	}
+}
+
+func proc6[A, B, C, D, E, F any](symbol unsafe.Pointer) func(A, B, C, D, E, F) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pushE := wordPusher(scalarRune(typeOf[E]()))
+	pushF := wordPusher(scalarRune(typeOf[F]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E](), typeOf[F]())
+	call := wordCaller(dyncall.Void)
+	return func(a A, b B, c C, d D, e E, f F) {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d), word(e), word(f))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		pushE(vm, word(e))
+		pushF(vm, word(f))
+		call(vm, symbol)
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(e)
+		runtime.KeepAlive(f)
+	}
+}
+
+func fn0[R any](symbol unsafe.Pointer) func() R {
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func() R {
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		return result
+	}
+}
+
+func fn1[A, R any](symbol unsafe.Pointer) func(A) R {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pins := pointerWords(typeOf[A]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A) R {
+		pinned := pinWords(pins, word(a))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		return result
+	}
+}
+
+func fn2[A, B, R any](symbol unsafe.Pointer) func(A, B) R {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pins := pointerWords(typeOf[A](), typeOf[B]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B) R {
+		pinned := pinWords(pins, word(a), word(b))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		return result
+	}
+}
+
+func fn3[A, B, C, R any](symbol unsafe.Pointer) func(A, B, C) R {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C) R {
+		pinned := pinWords(pins, word(a), word(b), word(c))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		return result
+	}
+}
+
+func fn4[A, B, C, D, R any](symbol unsafe.Pointer) func(A, B, C, D) R {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C, d D) R {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		return result
+	}
+}
+
+func fn5[A, B, C, D, E, R any](symbol unsafe.Pointer) func(A, B, C, D, E) R {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pushE := wordPusher(scalarRune(typeOf[E]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C, d D, e E) R {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d), word(e))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		pushE(vm, word(e))
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(e)
+		return result
+	}
+}
+
+func fn6[A, B, C, D, E, F, R any](symbol unsafe.Pointer) func(A, B, C, D, E, F) R {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pushE := wordPusher(scalarRune(typeOf[E]()))
+	pushF := wordPusher(scalarRune(typeOf[F]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E](), typeOf[F]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C, d D, e E, f F) R {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d), word(e), word(f))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		pushE(vm, word(e))
+		pushF(vm, word(f))
+		result := scalar[R](call(vm, symbol))
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(e)
+		runtime.KeepAlive(f)
+		return result
+	}
+}
+
+func fnErrno0[R any](symbol unsafe.Pointer) func() (R, syscall.Errno) {
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func() (R, syscall.Errno) {
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		return result, errno
+	}
+}
+
+func fnErrno1[A, R any](symbol unsafe.Pointer) func(A) (R, syscall.Errno) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pins := pointerWords(typeOf[A]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A) (R, syscall.Errno) {
+		pinned := pinWords(pins, word(a))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		return result, errno
+	}
+}
+
+func fnErrno2[A, B, R any](symbol unsafe.Pointer) func(A, B) (R, syscall.Errno) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pins := pointerWords(typeOf[A](), typeOf[B]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B) (R, syscall.Errno) {
+		pinned := pinWords(pins, word(a), word(b))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		return result, errno
+	}
+}
+
+func fnErrno3[A, B, C, R any](symbol unsafe.Pointer) func(A, B, C) (R, syscall.Errno) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C) (R, syscall.Errno) {
+		pinned := pinWords(pins, word(a), word(b), word(c))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		return result, errno
+	}
+}
+
+func fnErrno4[A, B, C, D, R any](symbol unsafe.Pointer) func(A, B, C, D) (R, syscall.Errno) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C, d D) (R, syscall.Errno) {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		return result, errno
+	}
+}
+
+func fnErrno5[A, B, C, D, E, R any](symbol unsafe.Pointer) func(A, B, C, D, E) (R, syscall.Errno) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pushE := wordPusher(scalarRune(typeOf[E]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C, d D, e E) (R, syscall.Errno) {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d), word(e))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		pushE(vm, word(e))
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(e)
+		return result, errno
+	}
+}
+
+func fnErrno6[A, B, C, D, E, F, R any](symbol unsafe.Pointer) func(A, B, C, D, E, F) (R, syscall.Errno) {
+	pushA := wordPusher(scalarRune(typeOf[A]()))
+	pushB := wordPusher(scalarRune(typeOf[B]()))
+	pushC := wordPusher(scalarRune(typeOf[C]()))
+	pushD := wordPusher(scalarRune(typeOf[D]()))
+	pushE := wordPusher(scalarRune(typeOf[E]()))
+	pushF := wordPusher(scalarRune(typeOf[F]()))
+	pins := pointerWords(typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E](), typeOf[F]())
+	call := wordCaller(scalarRune(typeOf[R]()))
+	return func(a A, b B, c C, d D, e E, f F) (R, syscall.Errno) {
+		pinned := pinWords(pins, word(a), word(b), word(c), word(d), word(e), word(f))
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		pushA(vm, word(a))
+		pushB(vm, word(b))
+		pushC(vm, word(c))
+		pushD(vm, word(d))
+		pushE(vm, word(e))
+		pushF(vm, word(f))
+		result := scalar[R](call(vm, symbol))
+		errno := vm.Errno()
+		vms.Put(vm)
+		unpinWords(pinned)
+		runtime.KeepAlive(a)
+		runtime.KeepAlive(b)
+		runtime.KeepAlive(c)
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(e)
+		runtime.KeepAlive(f)
+		return result, errno
+	}
+}
@@ -0,0 +1,67 @@
+package ffi
+
+import (
+	"errors"
+	"reflect"
+	"unsafe"
+
+	"qlova.tech/abi"
+)
+
+// pointers is the record of the C function pointers called through
+// [Func], Go funcs passed to them are kept for the life of the program.
+var pointers = &record{file: "abi.Func"}
+
+// Func returns a Go func that calls the C function pointer fn, such as
+// one returned by a C function, or read from a C struct. F must be a
+// func type, with the same signature as the C function, its parameters
+// and results are passed in the same way as those of a library's func
+// fields. If fn is nil, the Go func fails as if it were not linked.
+//
+// The abi package cannot call C, so this takes the place of a method
+// on [abi.Func]. Named func pointer types are converted first:
+//
+//	type Handler abi.Func[func(abi.Int) abi.Int]
+//
+//	ffi.Func(abi.Func[func(abi.Int) abi.Int](handler))(1)
+func Func[F any](fn abi.Func[F]) F {
+	var result F
+	value := reflect.ValueOf(&result).Elem()
+	if value.Kind() != reflect.Func {
+		panic("ffi.Func: " + value.Type().String() + " is not a func")
+	}
+	symbol := fn.UnsafePointer()
+	if symbol == nil {
+		value.Set(stub(value.Type(), errors.New("ffi: nil abi.Func")))
+		return result
+	}
+	if debug.escape || !fastpath(value.Addr().Interface(), symbol) {
+		field := reflect.StructField{Name: "abi.Func", Type: value.Type()}
+		value.Set(planOf(value.Type(), "", "").makeFunc(pointers, symbol, call{
+			name:  "abi.Func",
+			fails: failed(field, tag{}),
+		}))
+	}
+	return result
+}
+
+// NewFunc returns a C function pointer to the Go func fn, that can be
+// stored in C memory, such as in a C struct, and that remains valid for
+// the life of the program. F must be a func type. Use a [Callback] for
+// Go funcs that are created repeatedly, so that they can be freed.
+func NewFunc[F any](fn F) abi.Func[F] {
+	value := reflect.ValueOf(&fn).Elem()
+	if value.Kind() != reflect.Func {
+		panic("ffi.NewFunc: " + value.Type().String() + " is not a func")
+	}
+	if value.IsNil() {
+		panic("ffi.NewFunc: nil func")
+	}
+	key := keyOf(value)
+	mutex.Lock()
+	defer mutex.Unlock()
+	var ptr abi.Func[F]
+	ptr.SetPointer(unsafe.Pointer(retainThunk(key, value)))
+	thunks[key].owned++ // never released.
+	return ptr
+}
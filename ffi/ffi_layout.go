@@ -0,0 +1,176 @@
+package ffi
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"qlova.tech/abi"
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+var (
+	isPointer  = reflect.TypeOf([0]abi.IsPointer{}).Elem()
+	cString    = reflect.TypeOf(abi.String{})
+	longDouble = reflect.TypeOf([0]abi.DoubleLong{}).Elem()
+)
+
+// errLongDouble is the panic for C functions with long double
+// parameters or results, unless long double is a double.
+const errLongDouble = "ffi: long double is not supported"
+
+// layouts caches the dyncall aggregate for each Go type
+// that is passed to, or returned from C by value.
+var layouts sync.Map // map[reflect.Type]*dyncall.Aggr
+
+// isPointerLike reports whether values of the given type are
+// represented as a single C pointer, ie. [abi.String],
+// [abi.Pointer] and [abi.Opaque].
+func isPointerLike(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && (t == cString || t.Implements(isPointer))
+}
+
+// isLongDouble reports whether the given type is, or contains, a
+// C long double that is represented as an array of bytes, such as
+// [abi.DoubleLong] on x86. It is not passed as a struct of bytes,
+// nor as a pointer, so it cannot be passed by dyncall at all.
+func isLongDouble(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t == longDouble || isLongDouble(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if isLongDouble(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAggregate reports whether values of the given type are
+// passed to C by value, as a struct.
+func isAggregate(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return !isPointerLike(t)
+	case reflect.Array:
+		// complex numbers are represented as arrays of floating
+		// point numbers and are passed as a struct, any other
+		// array, other than a long double, decays into a pointer
+		// to its first element, as it does in C.
+		switch t.Elem().Kind() {
+		case reflect.Float32, reflect.Float64:
+			return true
+		}
+	}
+	return false
+}
+
+// pointerOf returns the C pointer held by a pointer-like value.
+func pointerOf(value reflect.Value) unsafe.Pointer {
+	switch v := value.Interface().(type) {
+	case abi.String:
+		return v.Pointer()
+	case abi.IsPointer:
+		ptr := v.Pointer()
+		return *(*unsafe.Pointer)(unsafe.Pointer(&ptr))
+	default:
+		panic("unsupported pointer " + value.Type().String())
+	}
+}
+
+// layoutOf returns the dyncall aggregate that describes the
+// C struct layout of the given Go struct or array type.
+func layoutOf(t reflect.Type) *dyncall.Aggr {
+	if cached, ok := layouts.Load(t); ok {
+		return cached.(*dyncall.Aggr)
+	}
+	if isLongDouble(t) {
+		panic(errLongDouble + ", " + t.String())
+	}
+	var ag *dyncall.Aggr
+	switch t.Kind() {
+	case reflect.Array:
+		ag = dyncall.NewAggr(1, int(t.Size()))
+		kind, sub := fieldRune(t.Elem())
+		ag.Field(kind, 0, t.Len(), sub)
+	case reflect.Struct:
+		ag = dyncall.NewAggr(t.NumField(), int(t.Size()))
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Type.Size() == 0 {
+				continue
+			}
+			elem, length := field.Type, 1
+			if elem.Kind() == reflect.Array {
+				elem, length = elem.Elem(), elem.Len()
+			}
+			kind, sub := fieldRune(elem)
+			ag.Field(kind, int(field.Offset), length, sub)
+		}
+	default:
+		panic("unsupported struct " + t.String())
+	}
+	ag.Close()
+	actual, loaded := layouts.LoadOrStore(t, ag)
+	if loaded {
+		ag.Free()
+	}
+	return actual.(*dyncall.Aggr)
+}
+
+// fieldRune returns the dyncall signature kind of a struct field
+// of the given type, along with its aggregate, if it has one.
+func fieldRune(t reflect.Type) (rune, *dyncall.Aggr) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return dyncall.UnsignedChar, nil // dyncall's bool is an int.
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return intRune(t.Size(), true), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return intRune(t.Size(), false), nil
+	case reflect.Float32:
+		return dyncall.Float, nil
+	case reflect.Float64:
+		return dyncall.Double, nil
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Func:
+		return dyncall.Pointer, nil
+	case reflect.Array:
+		return dyncall.Aggregate, layoutOf(t)
+	case reflect.Struct:
+		if isPointerLike(t) {
+			return dyncall.Pointer, nil
+		}
+		return dyncall.Aggregate, layoutOf(t)
+	default:
+		panic("unsupported struct field " + t.String())
+	}
+}
+
+// intRune returns the dyncall signature kind for an
+// integer of the given size in bytes.
+func intRune(size uintptr, signed bool) rune {
+	switch size {
+	case 1:
+		if signed {
+			return dyncall.Char
+		}
+		return dyncall.UnsignedChar
+	case 2:
+		if signed {
+			return dyncall.Short
+		}
+		return dyncall.UnsignedShort
+	case 4:
+		if signed {
+			return dyncall.Int
+		}
+		return dyncall.Uint
+	default:
+		if signed {
+			return dyncall.LongLong
+		}
+		return dyncall.UnsignedLongLong
+	}
+}
@@ -0,0 +1,146 @@
+package ffi
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// lazyTag reports whether the [Library] field is tagged with
+// `lazy:"true"`, see [Set].
+func lazyTag(header reflect.StructField) bool {
+	lazy, _ := strconv.ParseBool(header.Tag.Get("lazy"))
+	return lazy
+}
+
+// pending func field of a lazy library, or a version-gated
+// func field, that is resolved on its first call.
+type pending struct {
+	mutex sync.Mutex // held while resolving.
+	field reflect.StructField
+	tag   tag
+	fn    atomic.Pointer[reflect.Value] // the field was swapped to, once resolved.
+
+	unavailable bool // the library is older than the since tag.
+}
+
+// postpone sets the func field of a lazy library, or a version-gated
+// func field, to a stub that resolves its symbol on the first call.
+func (rec *record) postpone(library Library, field reflect.StructField, t tag) {
+	p := &pending{field: field, tag: t}
+	if rec.pending == nil {
+		rec.pending = make(map[string]*pending)
+	}
+	rec.pending[field.Name] = p
+	ftype := field.Type
+	value := reflect.ValueOf(library).Elem().FieldByIndex(field.Index)
+	value.Set(reflect.MakeFunc(ftype, func(args []reflect.Value) []reflect.Value {
+		fn := rec.resolve(library, p)
+		if ftype.IsVariadic() {
+			return fn.CallSlice(args)
+		}
+		return fn.Call(args)
+	}))
+}
+
+// resolve the symbol of the pending field and swap the field to the
+// bound func, or to a stub that fails if the symbol is missing. Returns
+// the func that the field was swapped to. If resolving panics, such as
+// when LibraryVersion panics or a main thread library is resolved off
+// the main thread without [Main], the panic is passed on to the caller
+// and the field stays pending, so that the next call tries again.
+func (rec *record) resolve(library Library, p *pending) reflect.Value {
+	if fn := p.fn.Load(); fn != nil {
+		return *fn
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if fn := p.fn.Load(); fn != nil {
+		return *fn
+	}
+	mutex.Lock()
+	linked := records[library] == rec
+	mutex.Unlock()
+	if !linked {
+		// the field has already been reset by Unlink.
+		fn := stub(p.field.Type, ErrUnlinked)
+		p.fn.Store(&fn)
+		return fn
+	}
+	if p.tag.since != "" && !rec.supports(library, p.tag.since) {
+		p.unavailable = true
+		rec.swap(library, p.field, stub(p.field.Type, ErrUnavailable))
+		return *p.fn.Load()
+	}
+	symbol, resolved := lookup(rec.handle.ptr, p.tag.symbols)
+	if symbol == nil {
+		rec.swap(library, p.field, stub(p.field.Type, errors.New("ffi: "+strings.Join(p.tag.symbols, " or ")+" is not linked")))
+		return *p.fn.Load()
+	}
+	mutex.Lock()
+	rec.bound[p.field.Name] = true
+	rec.funcs[p.field.Name] = binding{symbol: symbol, name: resolved, tag: p.tag}
+	mutex.Unlock()
+	rec.bind(library, p.field, tracerOf(library))
+	return *p.fn.Load()
+}
+
+// swap atomically sets a pending func field to fn, as
+// the field may be called concurrently by other goroutines. Under
+// the race detector, which would report the plain loads of the field
+// by its callers, the field is left as is, and its stub calls fn.
+func (rec *record) swap(library Library, field reflect.StructField, fn reflect.Value) {
+	rec.pending[field.Name].fn.Store(&fn)
+	if raceEnabled {
+		return
+	}
+	ptr := reflect.New(fn.Type())
+	ptr.Elem().Set(fn)
+	target := reflect.ValueOf(library).Elem().FieldByIndex(field.Index)
+	atomic.StorePointer((*unsafe.Pointer)(target.Addr().UnsafePointer()), *(*unsafe.Pointer)(ptr.UnsafePointer()))
+}
+
+// Preload resolves every func field of the given lazy libraries, as
+// [Link] would without the lazy tag, so that missing symbols are
+// reported up front in a single [*LinkError], rather than by each
+// func on its first call. Version-gated funcs are resolved too, but
+// are not missing if the library is too old for them. Other funcs are
+// already resolved, Preload returns [ErrUnlinked] if a library is not
+// linked.
+func Preload(libraries ...Library) error {
+	var unresolved LinkError
+	for _, library := range libraries {
+		mutex.Lock()
+		rec, ok := records[library]
+		mutex.Unlock()
+		if !ok {
+			return ErrUnlinked
+		}
+		var missing []string
+		rtype := reflect.TypeOf(library).Elem()
+		for i := 0; i < rtype.NumField(); i++ {
+			p, ok := rec.pending[rtype.Field(i).Name]
+			if !ok {
+				continue
+			}
+			rec.resolve(library, p)
+			mutex.Lock()
+			bound := rec.bound[p.field.Name]
+			mutex.Unlock()
+			if !bound && !p.tag.optional && !p.unavailable {
+				missing = append(missing, strings.Join(p.tag.symbols, " or "))
+			}
+		}
+		if len(missing) > 0 {
+			unresolved.merge(&LinkError{Symbols: map[string][]string{rec.file: missing}})
+		}
+	}
+	if len(unresolved.Symbols) > 0 {
+		return &unresolved
+	}
+	return nil
+}
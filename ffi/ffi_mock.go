@@ -0,0 +1,148 @@
+package ffi
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Recording of the calls made to a mocked library, see [Mock].
+type Recording struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made so far to the func field with the given
+// name, or to every field if name is empty, in the order they were made.
+func (r *Recording) Calls(name string) []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var calls []Call
+	for _, call := range r.calls {
+		if name == "" || call.Field == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls recorded so far.
+func (r *Recording) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = nil
+}
+
+func (r *Recording) add(call Call) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// Mock binds the func fields of the library to the methods, or the non-nil
+// func fields, of impl with the same name, instead of to the symbols of a
+// shared library, so that code that depends on the library can be tested
+// without it. Func fields that impl does not implement are set to a stub
+// that fails, as if the symbol was missing. Every call is recorded in the
+// returned [Recording]. If any implementation has a different signature to
+// its field, they are all reported in the returned error, after the rest
+// of the library has been mocked. The library is unmocked by [Unlink].
+func Mock(library Library, impl any) (*Recording, error) {
+	Unlink(library)
+
+	rec := &record{file: "mock", bound: make(map[string]bool)}
+	recording := new(Recording)
+
+	rtype := reflect.TypeOf(library).Elem()
+	rvalue := reflect.ValueOf(library).Elem()
+	mock := reflect.ValueOf(impl)
+
+	var mismatched []error
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		value := rvalue.Field(i)
+		if field.Type.Kind() != reflect.Func {
+			if isVariable(field.Type) {
+				bindVariable(value, nil, field.Name)
+			}
+			continue
+		}
+		fn := implementation(mock, field.Name)
+		if !fn.IsValid() {
+			value.Set(stub(field.Type, errors.New("ffi: "+field.Name+" is not mocked")))
+			continue
+		}
+		if !fn.Type().ConvertibleTo(field.Type) {
+			mismatched = append(mismatched, errors.New("ffi: mock "+field.Name+" is a "+fn.Type().String()+", not a "+field.Type.String()))
+			value.Set(stub(field.Type, errors.New("ffi: "+field.Name+" is not mocked")))
+			continue
+		}
+		rec.bound[field.Name] = true
+		value.Set(recorded(recording, library, field, fn.Convert(field.Type)))
+	}
+
+	mutex.Lock()
+	records[library] = rec
+	mutex.Unlock()
+
+	return recording, errors.Join(mismatched...)
+}
+
+// implementation returns the method, or non-nil func field, of impl
+// with the given name, if there is one.
+func implementation(impl reflect.Value, name string) reflect.Value {
+	if !impl.IsValid() {
+		return reflect.Value{}
+	}
+	if method := impl.MethodByName(name); method.IsValid() {
+		return method
+	}
+	for impl.Kind() == reflect.Pointer && !impl.IsNil() {
+		impl = impl.Elem()
+	}
+	if impl.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	field, ok := impl.Type().FieldByName(name)
+	if !ok || !field.IsExported() || field.Type.Kind() != reflect.Func {
+		return reflect.Value{}
+	}
+	if fn := impl.FieldByIndex(field.Index); !fn.IsNil() {
+		return fn
+	}
+	return reflect.Value{}
+}
+
+// recorded returns a func of the field's type that calls fn and
+// records the call.
+func recorded(recording *Recording, library Library, field reflect.StructField, fn reflect.Value) reflect.Value {
+	symbol := parseTag(field).symbols[0]
+	return reflect.MakeFunc(field.Type, func(args []reflect.Value) []reflect.Value {
+		call := Call{
+			Library:   library,
+			File:      "mock",
+			Field:     field.Name,
+			Symbol:    symbol,
+			Args:      make([]any, len(args)),
+			Start:     time.Now(),
+			Goroutine: goroutineID(),
+		}
+		for i, arg := range args {
+			call.Args[i] = arg.Interface()
+		}
+		var results []reflect.Value
+		if field.Type.IsVariadic() {
+			results = fn.CallSlice(args)
+		} else {
+			results = fn.Call(args)
+		}
+		call.Duration = time.Since(call.Start)
+		call.Results = make([]any, len(results))
+		for i, result := range results {
+			call.Results[i] = result.Interface()
+		}
+		recording.add(call)
+		return results
+	})
+}
@@ -0,0 +1,93 @@
+//go:build !cgo && linux && amd64
+
+package ffi
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"qlova.tech/abi"
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+// Without cgo, the dynamic linker is called through dyncall,
+// see qlova.tech/ffi/internal/fakecgo.
+
+//go:cgo_import_dynamic ffi_dlopen dlopen "libdl.so.2"
+//go:cgo_import_dynamic ffi_dlerror dlerror "libdl.so.2"
+//go:cgo_import_dynamic ffi_dlsym dlsym "libdl.so.2"
+//go:cgo_import_dynamic ffi_dlclose dlclose "libdl.so.2"
+//go:cgo_import_dynamic _ _ "libdl.so.2"
+
+// set by assembly.
+var dlopenABI0, dlerrorABI0, dlsymABI0, dlcloseABI0 uintptr
+
+const (
+	rtldLazy = 1
+	rtldNow  = 2
+)
+
+// libc calls the C function at the address held by fn, with
+// the arguments pushed by push.
+func libc(fn *uintptr, push func(vm *dyncall.VM)) unsafe.Pointer {
+	vm := vms.Get().(*dyncall.VM)
+	defer vms.Put(vm)
+	vm.Reset()
+	push(vm)
+	return vm.CallPointer(*(*unsafe.Pointer)(unsafe.Pointer(fn)))
+}
+
+// cstring returns s as a pointer to a null-terminated C string,
+// allocated by Go, which the caller must keep alive until the C
+// function it is passed to returns.
+func cstring(s string) unsafe.Pointer {
+	str := abi.NewString(s)
+	return *(*unsafe.Pointer)(unsafe.Pointer(&str))
+}
+
+func dlopen(filename string, lazy bool) (handle unsafe.Pointer) {
+	mode := int32(rtldNow)
+	if lazy {
+		mode = rtldLazy
+	}
+	name := cstring(filename)
+	handle = libc(&dlopenABI0, func(vm *dyncall.VM) {
+		vm.PushPointer(name)
+		vm.PushInt32(mode)
+	})
+	runtime.KeepAlive(name)
+	return handle
+}
+
+func dlerror() string {
+	ptr := libc(&dlerrorABI0, func(vm *dyncall.VM) {})
+	return (*(*abi.String)(unsafe.Pointer(&ptr))).String()
+}
+
+func dlsym(handle unsafe.Pointer, symbol string) unsafe.Pointer {
+	name := cstring(symbol)
+	ptr := libc(&dlsymABI0, func(vm *dyncall.VM) {
+		vm.PushPointer(handle)
+		vm.PushPointer(name)
+	})
+	runtime.KeepAlive(name)
+	return ptr
+}
+
+func dlclose(handle unsafe.Pointer) {
+	libc(&dlcloseABI0, func(vm *dyncall.VM) {
+		vm.PushPointer(handle)
+	})
+}
+
+// thread identifies an OS thread.
+type thread = int
+
+func currentThread() thread {
+	return syscall.Gettid()
+}
+
+func sameThread(a, b thread) bool {
+	return a == b
+}
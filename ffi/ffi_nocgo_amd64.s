@@ -0,0 +1,30 @@
+//go:build !cgo && linux
+
+#include "textflag.h"
+
+// the linker cannot relocate data to dynamic symbols,
+// so their addresses are taken through trampolines.
+
+TEXT dlopen_trampoline<>(SB), NOSPLIT|NOFRAME, $0-0
+	JMP	ffi_dlopen(SB)
+
+TEXT dlerror_trampoline<>(SB), NOSPLIT|NOFRAME, $0-0
+	JMP	ffi_dlerror(SB)
+
+TEXT dlsym_trampoline<>(SB), NOSPLIT|NOFRAME, $0-0
+	JMP	ffi_dlsym(SB)
+
+TEXT dlclose_trampoline<>(SB), NOSPLIT|NOFRAME, $0-0
+	JMP	ffi_dlclose(SB)
+
+GLOBL ·dlopenABI0(SB), NOPTR|RODATA, $8
+DATA ·dlopenABI0(SB)/8, $dlopen_trampoline<>(SB)
+
+GLOBL ·dlerrorABI0(SB), NOPTR|RODATA, $8
+DATA ·dlerrorABI0(SB)/8, $dlerror_trampoline<>(SB)
+
+GLOBL ·dlsymABI0(SB), NOPTR|RODATA, $8
+DATA ·dlsymABI0(SB)/8, $dlsym_trampoline<>(SB)
+
+GLOBL ·dlcloseABI0(SB), NOPTR|RODATA, $8
+DATA ·dlcloseABI0(SB)/8, $dlclose_trampoline<>(SB)
@@ -0,0 +1,17 @@
+//go:build !ffidebug
+
+package ffi
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// FFIDEBUG=escape=1 is only supported with the ffidebug tag.
+const escapeChecks = false
+
+func track(ptr unsafe.Pointer, delta int) {}
+
+func checkEscape(ptr unsafe.Pointer, how string) {}
+
+func checkPointer(value reflect.Value, to string) {}
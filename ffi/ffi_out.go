@@ -0,0 +1,136 @@
+package ffi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"qlova.tech/abi"
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+// param of a C function, either a Go parameter, or a pointer to a Go
+// result that the C function writes to, known as an out-param, or the
+// length of a Go parameter that is split, see [splits].
+type param struct {
+	in     int  // index of the Go parameter, or -1 for an out-param.
+	out    int  // index of the Go result, for an out-param.
+	length bool // the length of Go parameter in, after its pointer.
+}
+
+// params returns the C parameters of the Go func type, in order, and
+// whether its first Go result is the C return value. The Go results
+// after the C return value, other than an error, are out-params. They
+// are passed after the Go parameters, unless out is the value of an
+// out= tag option, such as "0,2", which lists the position of each
+// out-param amongst the C parameters. In that case, every Go result
+// that is not listed is the C return value, so that there must be at
+// most one. Go parameters that are split, as given by the value of a
+// len= tag option, see [splits], are followed by their length and so
+// they take up two C parameters.
+func params(ftype reflect.Type, out, length string) (order []param, returns bool, err error) {
+	results := ftype.NumOut()
+	if results > 0 && ftype.Out(results-1) == errorType {
+		results--
+	}
+	var in []param // the C parameters of the Go parameters, in order.
+	for i := 0; i < ftype.NumIn(); i++ {
+		in = append(in, param{in: i})
+		if splits(ftype, i, length) {
+			in = append(in, param{in: i, length: true})
+		}
+	}
+	if out == "" {
+		order = in
+		for i := 1; i < results; i++ {
+			order = append(order, param{in: -1, out: i})
+		}
+		return order, results > 0, nil
+	}
+	var positions []int
+	for _, position := range strings.Split(out, ",") {
+		n, err := strconv.Atoi(position)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid out=%v position", position)
+		}
+		positions = append(positions, n)
+	}
+	if len(positions) > results {
+		return nil, false, fmt.Errorf("%d out= positions for %d results", len(positions), results)
+	}
+	if results-len(positions) > 1 {
+		return nil, false, errors.New("more than one result is not an out-param")
+	}
+	returns = results > len(positions)
+	order = make([]param, len(in)+len(positions))
+	for i := range order {
+		order[i].in = -2 // not yet placed.
+	}
+	first := results - len(positions)
+	for i, position := range positions {
+		if position < 0 || position >= len(order) {
+			return nil, false, fmt.Errorf("out=%d is beyond the %d C parameters", position, len(order))
+		}
+		if order[position].in == -1 {
+			return nil, false, fmt.Errorf("more than one out-param at out=%d", position)
+		}
+		order[position] = param{in: -1, out: first + i}
+	}
+	next := 0
+	for i := range order {
+		if order[i].in == -2 {
+			order[i] = in[next]
+			next++
+		}
+	}
+	if ftype.IsVariadic() && order[len(order)-1].in != ftype.NumIn()-1 {
+		return nil, false, errors.New("out-param after the variadic parameter")
+	}
+	return order, returns, nil
+}
+
+// outStep pushes a pointer to the Go result of an out-param onto the VM,
+// the func it returns, if not nil, copies the value that C wrote to the
+// out-param into the Go result, once the call has returned.
+type outStep func(vm *dyncall.VM, result reflect.Value, f *frame) func()
+
+// newOutStep compiles an out step for the given Go type. C strings
+// are copied into Go strings, as C may not write a Go string, the C
+// string is not freed, so an [abi.String] should be used instead if
+// the caller is responsible for freeing it.
+func newOutStep(t reflect.Type) outStep {
+	switch t.Kind() {
+	case reflect.String:
+		return func(vm *dyncall.VM, result reflect.Value, f *frame) func() {
+			ptr := new(unsafe.Pointer)
+			f.pin(unsafe.Pointer(ptr))
+			vm.PushPointer(unsafe.Pointer(ptr))
+			return func() { result.SetString(goString(*ptr)) }
+		}
+	case reflect.Func, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan:
+		return func(vm *dyncall.VM, result reflect.Value, f *frame) func() {
+			panic("unsupported out-param type " + t.String())
+		}
+	}
+	return func(vm *dyncall.VM, result reflect.Value, f *frame) func() {
+		f.pin(result.Addr().UnsafePointer())
+		vm.PushPointer(result.Addr().UnsafePointer())
+		return nil
+	}
+}
+
+// writeOut writes the result of a Go callback to the out-param
+// that C passed to it.
+func writeOut(ptr unsafe.Pointer, result reflect.Value) {
+	switch result.Kind() {
+	case reflect.String:
+		*(*abi.String)(ptr) = abi.NewString(result.String())
+	case reflect.Func, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan:
+		panic("unsupported out-param type " + result.Type().String())
+	default:
+		reflect.NewAt(result.Type(), ptr).Elem().Set(result)
+	}
+}
@@ -0,0 +1,81 @@
+package ffi
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+)
+
+// CallbackPanic describes a panic in a Go func called by C, see
+// [SetPanicHandler].
+type CallbackPanic struct {
+	Func  reflect.Type // of the Go func.
+	Value any          // that the func panicked with.
+	Stack []byte       // of the goroutine that panicked.
+}
+
+// Error implements the error interface.
+func (p *CallbackPanic) Error() string {
+	return fmt.Sprintf("ffi: panic in Go callback %v called from C: %v", p.Func, p.Value)
+}
+
+// Unwrap returns the value that the func panicked with, if it is an error.
+func (p *CallbackPanic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+var panicHandler atomic.Pointer[func(*CallbackPanic)]
+
+// SetPanicHandler sets the handler of panics in Go funcs that are called
+// by C, such as callbacks, as a panic cannot unwind through C. Once the
+// handler returns, the Go func returns zero values to C. The handler is
+// called on the thread that C called the Go func on, which may not have
+// been created by Go. A nil handler restores the default, which writes
+// the panic and its stack trace to standard error and then exits the
+// program with status 2, as for any other unrecovered panic.
+func SetPanicHandler(handler func(*CallbackPanic)) {
+	if handler == nil {
+		panicHandler.Store(nil)
+		return
+	}
+	panicHandler.Store(&handler)
+}
+
+// callGo calls the Go func of a callback from C, if it panics, the panic
+// is passed to the panic handler and zero values are returned instead.
+func callGo(fn reflect.Value, args []reflect.Value) (results []reflect.Value) {
+	defer func() {
+		if value := recover(); value != nil {
+			failure, ok := value.(*CallbackPanic)
+			if !ok {
+				failure = &CallbackPanic{Func: fn.Type(), Value: value, Stack: stack()}
+			}
+			if handler := panicHandler.Load(); handler != nil {
+				(*handler)(failure)
+			} else {
+				fmt.Fprintf(os.Stderr, "%v\n\n%s", failure, failure.Stack)
+				os.Exit(2)
+			}
+			results = make([]reflect.Value, fn.Type().NumOut())
+			for i := range results {
+				results[i] = reflect.Zero(fn.Type().Out(i))
+			}
+		}
+	}()
+	return fn.Call(args)
+}
+
+// stack returns the stack trace of the calling goroutine.
+func stack() []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,184 @@
+package ffi
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// DebugEnv names the environment variable that holds the debug settings
+// of the package, as a comma separated list of name=value pairs, in the
+// same format as GODEBUG. It is read once, when the program starts.
+//
+// FFIDEBUG=escape=1 checks that Go pointers do not escape into C. When
+// a Go pointer is passed to C, the Go memory it points to must not hold
+// any unpinned Go pointers. When C returns a Go pointer, or passes one
+// to a Go callback, the Go memory it points to must be pinned by a call
+// that is in progress, or by [Retain]. Otherwise, the call panics. The
+// checks are expensive, so every call is made through reflection, and
+// they rely on runtime internals, so they are only built into programs
+// built with the ffidebug build tag:
+//
+//	FFIDEBUG=escape=1 go test -tags ffidebug
+const DebugEnv = "FFIDEBUG"
+
+var debug struct {
+	escape bool // FFIDEBUG=escape=1
+}
+
+func init() {
+	for _, setting := range strings.Split(os.Getenv(DebugEnv), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(setting), "=")
+		switch name {
+		case "escape":
+			debug.escape = value == "1"
+			if debug.escape && !escapeChecks {
+				fmt.Fprintln(os.Stderr, "ffi: "+DebugEnv+"=escape=1 is ignored, as the program was not built with -tags ffidebug")
+				debug.escape = false
+			}
+		}
+	}
+}
+
+// resultPointer returns the pointer held by a result, if it is one.
+func resultPointer(value reflect.Value) (unsafe.Pointer, bool) {
+	switch {
+	case value.Kind() == reflect.Pointer, value.Kind() == reflect.UnsafePointer:
+		return value.UnsafePointer(), true
+	case isPointerLike(value.Type()):
+		return pointerOf(value), true
+	}
+	return nil, false
+}
+
+// pin the Go memory that ptr points to, until the call returns.
+func (f *frame) pin(ptr unsafe.Pointer) {
+	if ptr == nil {
+		return
+	}
+	f.pinner.Pin(ptr)
+	if debug.escape {
+		track(ptr, 1)
+		f.pinned = append(f.pinned, ptr)
+	}
+}
+
+// unpin the Go memory pinned for the call.
+func (f *frame) unpin() {
+	f.pinner.Unpin()
+	for _, ptr := range f.pinned {
+		track(ptr, -1)
+	}
+}
+
+// pointerWords returns a mask of the given fast call parameter
+// types that are pointers, as their Go memory is pinned.
+func pointerWords(types ...reflect.Type) (mask uint8) {
+	for i, t := range types {
+		if t.Kind() == reflect.UnsafePointer || t.Kind() == reflect.Pointer {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+// pinWords pins the Go memory that the masked words point to, it
+// returns nil without pinning anything if the mask is empty.
+func pinWords(mask uint8, words ...uint64) *runtime.Pinner {
+	if mask == 0 {
+		return nil
+	}
+	pinner := new(runtime.Pinner)
+	for i := range words {
+		if mask&(1<<i) != 0 {
+			if ptr := *(*unsafe.Pointer)(unsafe.Pointer(&words[i])); ptr != nil {
+				pinner.Pin(ptr)
+			}
+		}
+	}
+	return pinner
+}
+
+// unpinWords unpins the Go memory pinned by [pinWords].
+func unpinWords(pinner *runtime.Pinner) {
+	if pinner != nil {
+		pinner.Unpin()
+	}
+}
+
+// retention of Go memory by C, see [Retain].
+type retention struct {
+	pinner runtime.Pinner
+	refs   int
+}
+
+var retained = make(map[unsafe.Pointer]*retention) // guarded by mutex.
+
+// Retain pins the Go memory that pointer refers to until it is released,
+// so that C can keep the pointer after the call that it is passed to has
+// returned, as it does with the buffer passed to setvbuf. The pointer can
+// be any Go pointer, an [unsafe.Pointer], a slice, or a pointer-like abi
+// value, such as an [abi.String]. Each Retain must be matched by a call
+// to [Release] with the same pointer.
+func Retain(pointer any) {
+	ptr := addressOf("ffi.Retain", pointer)
+	if ptr == nil {
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	r, ok := retained[ptr]
+	if !ok {
+		r = new(retention)
+		r.pinner.Pin(ptr)
+		retained[ptr] = r
+		if debug.escape {
+			track(ptr, 1)
+		}
+	}
+	r.refs++
+}
+
+// Release unpins Go memory that was retained by [Retain], once it has
+// been released as many times as it was retained. C must not use the
+// pointer after it has been released. Release panics if the pointer is
+// not retained.
+func Release(pointer any) {
+	ptr := addressOf("ffi.Release", pointer)
+	if ptr == nil {
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	r, ok := retained[ptr]
+	if !ok {
+		panic(fmt.Sprintf("ffi.Release: %p is not retained", ptr))
+	}
+	r.refs--
+	if r.refs == 0 {
+		r.pinner.Unpin()
+		delete(retained, ptr)
+		if debug.escape {
+			track(ptr, -1)
+		}
+	}
+}
+
+// addressOf returns the address that the pointer refers to.
+func addressOf(caller string, pointer any) unsafe.Pointer {
+	value := reflect.ValueOf(pointer)
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Slice:
+		return value.UnsafePointer()
+	case reflect.Struct:
+		if isPointerLike(value.Type()) {
+			return pointerOf(value)
+		}
+	}
+	panic(caller + ": " + value.Type().String() + " is not a pointer")
+}
@@ -0,0 +1,499 @@
+package ffi
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"qlova.tech/abi"
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+// vms is a pool of dyncall VMs, sync.Pool keeps a cache per P, so
+// each running goroutine can reuse a VM without contention or
+// allocating a new one on each call.
+var vms = sync.Pool{
+	New: func() any {
+		return dyncall.NewVM(4096)
+	},
+}
+
+var errorType = reflect.TypeOf([0]error{}).Elem()
+
+// plans caches the compiled plan for each func type, as the same
+// signature is often shared by many fields.
+var plans sync.Map // map[planKey]*plan
+
+// planKey identifies a plan by its func type, the positions
+// of its out-params and the type of its lengths, see [params].
+type planKey struct {
+	ftype  reflect.Type
+	out    string
+	length string
+}
+
+// plan is a precompiled description of how to call a C function
+// with a particular Go func signature, such that the kind of each
+// value is only inspected once, when the library is linked.
+type plan struct {
+	ftype reflect.Type
+
+	params []param    // of the C function, in order.
+	args   []pushStep // one for each Go parameter.
+	length pushStep   // pushes the length of a split Go parameter.
+	outs   []outStep  // one for each Go result, nil unless it is an out-param.
+	call   callStep   // writes the C return value into the first Go result, nil if void.
+
+	returnsError bool // the last Go result is an error.
+	variadic     bool // the last Go parameter is C variadic.
+}
+
+// frame holds the state of a single call through a plan.
+type frame struct {
+	keep []any   // Go memory that must remain valid for the duration of the call.
+	lib  *record // the library being called.
+	name string  // of the symbol being called.
+
+	keepFuncs bool       // passed to C until Unlink, see [frame.thunk].
+	thunks    []thunkKey // released once the call has returned.
+
+	pinner runtime.Pinner
+	pinned []unsafe.Pointer // tracked whilst FFIDEBUG=escape=1.
+}
+
+// pushStep pushes a Go value onto the VM.
+type pushStep func(vm *dyncall.VM, value reflect.Value, f *frame)
+
+// callStep calls the symbol and writes its return value into result.
+type callStep func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value)
+
+// planOf returns the plan for the given func type, with out-params
+// at the given positions and lengths of the given type, see [params].
+func planOf(ftype reflect.Type, out, length string) *plan {
+	key := planKey{ftype, out, length}
+	if cached, ok := plans.Load(key); ok {
+		return cached.(*plan)
+	}
+	order, returns, err := params(ftype, out, length)
+	if err != nil {
+		panic("ffi: " + ftype.String() + ": " + err.Error())
+	}
+	p := &plan{ftype: ftype, params: order, variadic: ftype.IsVariadic()}
+	p.length = newLengthStep(length)
+	for i := 0; i < ftype.NumIn(); i++ {
+		if p.variadic && i == ftype.NumIn()-1 {
+			p.args = append(p.args, newVariadicStep(ftype.In(i).Elem()))
+			break
+		}
+		p.args = append(p.args, newPushStep(ftype.In(i)))
+	}
+	results := ftype.NumOut()
+	if results > 0 && ftype.Out(results-1) == errorType {
+		p.returnsError = true
+	}
+	p.outs = make([]outStep, results)
+	for _, param := range order {
+		if param.in < 0 {
+			p.outs[param.out] = newOutStep(ftype.Out(param.out))
+		}
+	}
+	if returns {
+		p.call = newCallStep(ftype.Out(0))
+	}
+	actual, _ := plans.LoadOrStore(key, p)
+	return actual.(*plan)
+}
+
+// newPushStep compiles a push step for the given Go type.
+func newPushStep(t reflect.Type) pushStep {
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushBool(value.Bool())
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		push := wordPusher(intRune(t.Size(), true))
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			push(vm, uint64(value.Int()))
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		push := wordPusher(intRune(t.Size(), false))
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			push(vm, value.Uint())
+		}
+	case reflect.Float32:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushFloat32(float32(value.Float()))
+		}
+	case reflect.Float64:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushFloat64(value.Float())
+		}
+	case reflect.Pointer, reflect.UnsafePointer:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			if debug.escape {
+				checkPointer(value, f.name)
+			}
+			f.pin(value.UnsafePointer())
+			vm.PushPointer(value.UnsafePointer())
+		}
+	case reflect.Slice:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			if debug.escape {
+				checkPointer(value, f.name)
+			}
+			f.pin(value.UnsafePointer())
+			vm.PushPointer(value.UnsafePointer())
+		}
+	case reflect.String:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			s := abi.NewString(value.String())
+			f.keep = append(f.keep, s)
+			f.pin(s.Pointer())
+			vm.PushPointer(s.Pointer())
+		}
+	case reflect.Struct, reflect.Array:
+		switch {
+		case t == bufferType:
+			return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+				ptr := value.Interface().(abi.Buffer).UnsafePointer()
+				f.pin(ptr)
+				vm.PushPointer(ptr)
+			}
+		case isPointerLike(t):
+			return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+				f.pin(pointerOf(value))
+				vm.PushPointer(pointerOf(value))
+			}
+		case isLongDouble(t):
+			return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+				panic(errLongDouble + ", " + t.String())
+			}
+		case isAggregate(t):
+			layout := layoutOf(t)
+			return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+				copy := reflect.New(t)
+				copy.Elem().Set(value)
+				f.keep = append(f.keep, copy)
+				f.pin(copy.UnsafePointer())
+				vm.PushAggr(layout, copy.UnsafePointer())
+			}
+		case t.Kind() == reflect.Array:
+			return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+				copy := reflect.New(t)
+				copy.Elem().Set(value)
+				f.keep = append(f.keep, copy)
+				f.pin(copy.UnsafePointer())
+				vm.PushPointer(copy.UnsafePointer())
+			}
+		}
+	case reflect.Func:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushPointer(f.thunk(value))
+		}
+	}
+	// unsupported types only panic once they are called,
+	// so that the rest of the library can still be used.
+	return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+		panic("unsupported type " + t.String())
+	}
+}
+
+// newVariadicStep compiles a push step for the variadic slice of a
+// C variadic function, each element is pushed individually, after
+// the default argument promotions. Elements of interface type are
+// pushed according to their dynamic type.
+func newVariadicStep(elem reflect.Type) pushStep {
+	if elem.Kind() == reflect.Interface {
+		var steps sync.Map // map[reflect.Type]pushStep
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			for i := 0; i < value.Len(); i++ {
+				arg := value.Index(i).Elem()
+				if !arg.IsValid() {
+					vm.PushPointer(nil)
+					continue
+				}
+				step, ok := steps.Load(arg.Type())
+				if !ok {
+					step, _ = steps.LoadOrStore(arg.Type(), newPromotedStep(arg.Type()))
+				}
+				step.(pushStep)(vm, arg, f)
+			}
+		}
+	}
+	push := newPromotedStep(elem)
+	return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+		for i := 0; i < value.Len(); i++ {
+			push(vm, value.Index(i), f)
+		}
+	}
+}
+
+// newPromotedStep compiles a push step for a variadic argument of the
+// given type, applying C's default argument promotions, such that
+// floats are passed as doubles and small integers are passed as ints.
+func newPromotedStep(t reflect.Type) pushStep {
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			if value.Bool() {
+				vm.PushInt32(1)
+			} else {
+				vm.PushInt32(0)
+			}
+		}
+	case reflect.Int8, reflect.Int16:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushInt32(int32(value.Int()))
+		}
+	case reflect.Uint8, reflect.Uint16:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushInt32(int32(value.Uint()))
+		}
+	case reflect.Float32:
+		return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+			vm.PushFloat64(value.Float())
+		}
+	case reflect.Struct:
+		if !isPointerLike(t) {
+			return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+				panic("ffi: structs cannot be passed as C variadic arguments")
+			}
+		}
+	}
+	return newPushStep(t)
+}
+
+// newCallStep compiles a call step that returns the given Go type.
+func newCallStep(t reflect.Type) callStep {
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			result.SetBool(vm.CallBool(symbol))
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		call := wordCaller(intRune(t.Size(), true))
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			result.SetInt(signExtend(call(vm, symbol), t.Size()))
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		call := wordCaller(intRune(t.Size(), false))
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			result.SetUint(call(vm, symbol))
+		}
+	case reflect.Float32:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			result.SetFloat(float64(vm.CallFloat32(symbol)))
+		}
+	case reflect.Float64:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			result.SetFloat(vm.CallFloat64(symbol))
+		}
+	case reflect.String:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			result.SetString(goString(vm.CallPointer(symbol)))
+		}
+	case reflect.UnsafePointer, reflect.Pointer:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+			*(*unsafe.Pointer)(result.Addr().UnsafePointer()) = vm.CallPointer(symbol)
+		}
+	case reflect.Struct, reflect.Array:
+		switch {
+		case isPointerLike(t):
+			return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+				*(*unsafe.Pointer)(result.Addr().UnsafePointer()) = vm.CallPointer(symbol)
+			}
+		case isLongDouble(t):
+			return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+				panic(errLongDouble + ", " + t.String())
+			}
+		case isAggregate(t):
+			layout := layoutOf(t)
+			return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+				vm.CallAggr(symbol, layout, result.Addr().UnsafePointer())
+			}
+		}
+	}
+	return func(vm *dyncall.VM, symbol unsafe.Pointer, result reflect.Value) {
+		panic("unsupported type " + t.String())
+	}
+}
+
+// signExtend sign extends the low size bytes of the given word.
+func signExtend(word uint64, size uintptr) int64 {
+	shift := 64 - 8*size
+	return int64(word<<shift) >> shift
+}
+
+// goString copies the null-terminated C string at ptr into Go memory.
+func goString(ptr unsafe.Pointer) string {
+	if ptr == nil {
+		return ""
+	}
+	return (*abi.String)(unsafe.Pointer(&ptr)).String()
+}
+
+// wordPusher returns a function that pushes a scalar of the given
+// signature kind, represented by its raw little-endian bits.
+func wordPusher(kind rune) func(*dyncall.VM, uint64) {
+	switch kind {
+	case dyncall.Bool:
+		return func(vm *dyncall.VM, word uint64) { vm.PushBool(word&0xFF != 0) }
+	case dyncall.Char, dyncall.UnsignedChar:
+		return func(vm *dyncall.VM, word uint64) { vm.PushInt8(int8(word)) }
+	case dyncall.Short, dyncall.UnsignedShort:
+		return func(vm *dyncall.VM, word uint64) { vm.PushInt16(int16(word)) }
+	case dyncall.Int, dyncall.Uint:
+		return func(vm *dyncall.VM, word uint64) { vm.PushInt32(int32(word)) }
+	case dyncall.LongLong, dyncall.UnsignedLongLong:
+		return func(vm *dyncall.VM, word uint64) { vm.PushInt64(int64(word)) }
+	case dyncall.Float:
+		return func(vm *dyncall.VM, word uint64) { vm.PushFloat32(math.Float32frombits(uint32(word))) }
+	case dyncall.Double:
+		return func(vm *dyncall.VM, word uint64) { vm.PushFloat64(math.Float64frombits(word)) }
+	case dyncall.Pointer:
+		return func(vm *dyncall.VM, word uint64) { vm.PushPointer(*(*unsafe.Pointer)(unsafe.Pointer(&word))) }
+	default:
+		panic("unsupported scalar " + string(kind))
+	}
+}
+
+// wordCaller returns a function that calls a symbol returning a
+// scalar of the given signature kind, as its raw little-endian bits.
+func wordCaller(kind rune) func(*dyncall.VM, unsafe.Pointer) uint64 {
+	switch kind {
+	case dyncall.Void:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 { vm.Call(symbol); return 0 }
+	case dyncall.Bool:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 {
+			if vm.CallBool(symbol) {
+				return 1
+			}
+			return 0
+		}
+	case dyncall.Char, dyncall.UnsignedChar:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 { return uint64(uint8(vm.CallInt8(symbol))) }
+	case dyncall.Short, dyncall.UnsignedShort:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 { return uint64(uint16(vm.CallInt16(symbol))) }
+	case dyncall.Int, dyncall.Uint:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 { return uint64(uint32(vm.CallInt32(symbol))) }
+	case dyncall.LongLong, dyncall.UnsignedLongLong:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 { return uint64(vm.CallInt64(symbol)) }
+	case dyncall.Float:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 {
+			return uint64(math.Float32bits(vm.CallFloat32(symbol)))
+		}
+	case dyncall.Double:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 { return math.Float64bits(vm.CallFloat64(symbol)) }
+	case dyncall.Pointer:
+		return func(vm *dyncall.VM, symbol unsafe.Pointer) uint64 {
+			ptr := vm.CallPointer(symbol)
+			return *(*uint64)(unsafe.Pointer(&ptr))
+		}
+	default:
+		panic("unsupported scalar " + string(kind))
+	}
+}
+
+// call describes how a symbol bound to a func field reports errors.
+type call struct {
+	name string // of the symbol.
+	keep bool   // the Go funcs passed to the symbol, see [frame.thunk].
+
+	// fails reports whether the call failed, given the first
+	// result and errno, nil if the func does not return an error.
+	fails func(result reflect.Value, errno syscall.Errno) bool
+
+	// getErr is the library's Error field, if it is a
+	// valid func() string value, it describes failures,
+	// otherwise errno does.
+	getErr reflect.Value
+
+	trace *site // nil unless the call is traced.
+}
+
+// makeFunc returns a Go func value of the plan's type that calls
+// the given symbol of lib.
+func (p *plan) makeFunc(lib *record, symbol unsafe.Pointer, c call) reflect.Value {
+	return reflect.MakeFunc(p.ftype, func(args []reflect.Value) []reflect.Value {
+		vm := vms.Get().(*dyncall.VM)
+		vm.Reset()
+		defer vms.Put(vm)
+
+		var results []reflect.Value
+		if c.trace != nil {
+			traced := c.trace.begin(c.name, args)
+			// ended even if the call panics, so that
+			// the tracer is not left with an open call.
+			defer func() { c.trace.end(traced, results, vm.Errno()) }()
+		}
+
+		// any Go memory referenced by the arguments is kept
+		// alive and pinned until the call has returned.
+		var f = frame{lib: lib, name: c.name, keepFuncs: c.keep}
+		defer runtime.KeepAlive(&f)
+		defer f.unpin()
+		defer f.release()
+
+		results = make([]reflect.Value, p.ftype.NumOut())
+		for i := range results {
+			results[i] = reflect.New(p.ftype.Out(i)).Elem()
+		}
+		if p.variadic {
+			vm.Mode(dyncall.ModeEllipsis)
+		}
+		var copies []func() // of out-params, once the call returns.
+		for _, param := range p.params {
+			if param.in < 0 {
+				if copy := p.outs[param.out](vm, results[param.out], &f); copy != nil {
+					copies = append(copies, copy)
+				}
+				continue
+			}
+			if param.length {
+				p.length(vm, args[param.in], &f)
+				continue
+			}
+			if p.variadic && param.in == len(args)-1 {
+				vm.Mode(dyncall.ModeEllipsisVarargs)
+			}
+			p.args[param.in](vm, args[param.in], &f)
+		}
+		if p.call != nil {
+			p.call(vm, symbol, results[0])
+			if debug.escape {
+				if ptr, ok := resultPointer(results[0]); ok {
+					checkEscape(ptr, "returned by "+c.name)
+				}
+			}
+		} else {
+			vm.Call(symbol)
+		}
+		for _, copy := range copies {
+			copy()
+		}
+		// errno is captured on the thread that made the call.
+		if p.returnsError && c.fails(results[0], vm.Errno()) {
+			err := c.err(vm.Errno())
+			results[len(results)-1] = reflect.ValueOf(&err).Elem()
+		}
+		return results
+	})
+}
+
+// err returns the error for a failed call.
+func (c call) err(errno syscall.Errno) error {
+	if c.getErr.IsValid() {
+		if fn, ok := c.getErr.Interface().(func() string); ok && fn != nil {
+			return errors.New(fn())
+		}
+	}
+	if errno != 0 {
+		return errno
+	}
+	return errors.New("ffi: " + c.name + " failed")
+}
@@ -0,0 +1,17 @@
+package ffi
+
+/*
+#include <pthread.h>
+*/
+import "C"
+
+// thread identifies an OS thread.
+type thread = C.pthread_t
+
+func currentThread() thread {
+	return C.pthread_self()
+}
+
+func sameThread(a, b thread) bool {
+	return C.pthread_equal(a, b) != 0
+}
@@ -0,0 +1,90 @@
+package ffi
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+)
+
+// Queue of calls to Go funcs, that are run on the goroutine that runs
+// the queue, rather than on the thread that C calls them on, see
+// [Queued]. This suits callbacks that C makes from its own threads,
+// such as audio callbacks, that need to share state with a goroutine.
+type Queue struct {
+	calls  chan func()
+	runner atomic.Pointer[thread] // locked to the goroutine running the queue.
+}
+
+// NewQueue returns a new queue, that is run by [Queue.Run].
+func NewQueue() *Queue {
+	return &Queue{calls: make(chan func())}
+}
+
+// Run runs the queued calls on the calling goroutine, until stop is
+// closed. Queued funcs that are called whilst the queue is not being
+// run wait until it is. The goroutine is locked to its thread whilst
+// it runs the queue, so that queued funcs can tell whether they are
+// called by it from the thread that they are called on.
+func (q *Queue) Run(stop <-chan struct{}) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	runner := currentThread()
+	if !q.runner.CompareAndSwap(nil, &runner) {
+		panic("ffi: Queue is already running")
+	}
+	defer q.runner.Store(nil)
+	for {
+		select {
+		case call := <-q.calls:
+			call()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Queued returns a func of the same type as fn, that calls fn on the
+// goroutine that runs the queue and waits for it to return, unless it
+// is called by that goroutine, in which case fn is called directly.
+// F must be a func type. The returned func can be passed to C as a
+// callback, a panic in fn is raised in the caller, such that it is
+// handled as any other panic in a callback, see [SetPanicHandler].
+func Queued[F any](q *Queue, fn F) F {
+	value := reflect.ValueOf(&fn).Elem()
+	if value.Kind() != reflect.Func {
+		panic("ffi.Queued: " + value.Type().String() + " is not a func")
+	}
+	if value.IsNil() {
+		panic("ffi.Queued: nil func")
+	}
+	call := value.Call
+	if value.Type().IsVariadic() {
+		call = value.CallSlice
+	}
+	var queued F
+	reflect.ValueOf(&queued).Elem().Set(reflect.MakeFunc(value.Type(), func(args []reflect.Value) []reflect.Value {
+		if runner := q.runner.Load(); runner != nil && sameThread(*runner, currentThread()) {
+			return call(args)
+		}
+		var (
+			results []reflect.Value
+			failure *CallbackPanic
+			done    = make(chan struct{})
+		)
+		q.calls <- func() {
+			defer close(done)
+			defer func() {
+				if r := recover(); r != nil {
+					failure = &CallbackPanic{Func: value.Type(), Value: r, Stack: stack()}
+				}
+			}()
+			results = call(args)
+		}
+		<-done
+		if failure != nil {
+			panic(failure)
+		}
+		return results
+	}))
+	return queued
+}
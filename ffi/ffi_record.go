@@ -0,0 +1,141 @@
+package ffi
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// ErrUnlinked is returned (or panicked with, for funcs that do
+// not return an error) by the funcs of an unlinked library.
+var ErrUnlinked = errors.New("ffi: library unlinked")
+
+// handle to a loaded shared library, reference counted
+// so that it can be shared by many [Library] structs.
+type handle struct {
+	ptr  unsafe.Pointer
+	path string
+	refs int
+}
+
+// record of a linked library.
+type record struct {
+	file    string
+	handle  *handle             // nil if the library is mocked.
+	bound   map[string]bool     // fields that were bound to a symbol.
+	funcs   map[string]binding  // func fields, by name.
+	pending map[string]*pending // func fields resolved on their first call, by name.
+	thunks  map[thunkKey]bool   // Go funcs passed to its keep funcs.
+
+	versioning sync.Mutex // held while querying the version.
+	versioned  bool       // the version has been queried.
+	version    string     // of the library, see [Versioned].
+}
+
+// binding of a func field to a symbol.
+type binding struct {
+	symbol unsafe.Pointer
+	name   string // of the symbol.
+	tag    tag
+}
+
+var (
+	mutex   sync.Mutex
+	handles = make(map[string]*handle)  // by path.
+	records = make(map[Library]*record) // by library.
+)
+
+// acquire returns the shared handle for the library loaded from path.
+func acquire(path string, ptr unsafe.Pointer) *handle {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if shared, ok := handles[path]; ok {
+		dlclose(ptr) // balance the reference held by dlopen.
+		shared.refs++
+		return shared
+	}
+	shared := &handle{ptr: ptr, path: path, refs: 1}
+	handles[path] = shared
+	return shared
+}
+
+// release the handle, closing it once it is no longer referenced.
+func (h *handle) release() {
+	h.refs--
+	if h.refs == 0 {
+		delete(handles, h.path)
+		dlclose(h.ptr)
+	}
+}
+
+// Bound reports whether the func or variable field with the given
+// name has been bound to a symbol of the library, this is useful to
+// check whether an optional symbol was available. The func fields of
+// a lazy library are resolved first.
+func Bound(library Library, field string) bool {
+	mutex.Lock()
+	rec, ok := records[library]
+	mutex.Unlock()
+	if !ok {
+		return false
+	}
+	if p, ok := rec.pending[field]; ok {
+		rec.resolve(library, p)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	return rec.bound[field]
+}
+
+// Unlink unlinks the given library, every func field is reset to
+// a stub that fails with [ErrUnlinked], variables are reset to nil
+// and the C function pointers of Go funcs passed to the library are
+// released. The shared library is closed once no other linked library
+// refers to it. Unlink must not be called whilst any of the library's
+// funcs are being called.
+func Unlink(library Library) {
+	mutex.Lock()
+	rec, ok := records[library]
+	if !ok {
+		mutex.Unlock()
+		return
+	}
+	delete(records, library)
+	if rec.handle != nil {
+		rec.handle.release()
+	}
+	for key := range rec.thunks {
+		releaseThunk(key)
+	}
+	rec.thunks = nil
+	mutex.Unlock()
+
+	rvalue := reflect.ValueOf(library).Elem()
+	rtype := rvalue.Type()
+	for i := 0; i < rvalue.NumField(); i++ {
+		switch value := rvalue.Field(i); {
+		case value.Kind() == reflect.Func:
+			value.Set(stub(value.Type(), ErrUnlinked))
+		case isVariable(value.Type()):
+			bindVariable(value, nil, rtype.Field(i).Name)
+		}
+	}
+}
+
+// stub returns a func of the given type that returns err, if its
+// last result is an error, otherwise it panics with err.
+func stub(ftype reflect.Type, err error) reflect.Value {
+	returnsError := ftype.NumOut() > 0 && ftype.Out(ftype.NumOut()-1) == errorType
+	return reflect.MakeFunc(ftype, func([]reflect.Value) []reflect.Value {
+		if !returnsError {
+			panic(err)
+		}
+		results := make([]reflect.Value, ftype.NumOut())
+		for i := range results {
+			results[i] = reflect.Zero(ftype.Out(i))
+		}
+		results[len(results)-1] = reflect.ValueOf(&err).Elem()
+		return results
+	})
+}
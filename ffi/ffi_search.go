@@ -0,0 +1,135 @@
+package ffi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// SearchPath is a list of directories that are searched for shared
+// libraries before the system linker is consulted, directories may
+// begin with $ORIGIN, which is replaced with the directory of the
+// executable. SearchPath must not be modified whilst linking.
+var SearchPath []string
+
+// SearchPathEnv names the environment variable holding a list of
+// directories, separated by [os.PathListSeparator], that are
+// searched after [SearchPath] and before the system linker.
+const SearchPathEnv = "FFI_LIBRARY_PATH"
+
+// LoadError reports every path that was tried when
+// loading a shared library, along with the reason
+// that each path could not be loaded.
+type LoadError struct {
+	Tried   []string
+	Reasons []string
+}
+
+// Error implements the error interface.
+func (err *LoadError) Error() string {
+	var b strings.Builder
+	b.WriteString("ffi: could not load library, tried ")
+	for i, path := range err.Tried {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(path)
+		if reason := err.Reasons[i]; reason != "" {
+			b.WriteString(" (" + reason + ")")
+		}
+	}
+	return b.String()
+}
+
+// libraryTag returns the library names from the struct tag of a
+// [Library] field, tags for the current GOOS_GOARCH take
+// precedence over tags for the current GOOS.
+func libraryTag(header reflect.StructField) string {
+	if names, ok := header.Tag.Lookup(runtime.GOOS + "_" + runtime.GOARCH); ok {
+		return names
+	}
+	return header.Tag.Get(runtime.GOOS)
+}
+
+// origin expands a leading $ORIGIN in path to the
+// directory containing the executable.
+func origin(path string) string {
+	rest, ok := strings.CutPrefix(path, "$ORIGIN")
+	if !ok {
+		return path
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	return filepath.Dir(executable) + rest
+}
+
+// load opens the first shared library that can be found from the
+// comma separated list of candidate names, returning its handle
+// and the path that was loaded. Lazy libraries are opened with
+// RTLD_LAZY, rather than RTLD_NOW.
+func load(names string, lazy bool) (unsafe.Pointer, string, error) {
+	var candidates []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			candidates = append(candidates, name)
+		}
+	}
+	var dirs = append([]string(nil), SearchPath...)
+	if env := os.Getenv(SearchPathEnv); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	// dlerror is thread-local.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var tried LoadError
+	try := func(path string) unsafe.Pointer {
+		handle := dlopen(path, lazy)
+		if handle == nil {
+			tried.Tried = append(tried.Tried, path)
+			tried.Reasons = append(tried.Reasons, dlerror())
+		}
+		return handle
+	}
+	// explicit paths are only tried as they are.
+	var bare []string
+	for _, name := range candidates {
+		if strings.ContainsRune(name, '/') {
+			path := origin(name)
+			if handle := try(path); handle != nil {
+				return handle, path, nil
+			}
+			continue
+		}
+		bare = append(bare, name)
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, name := range bare {
+			path := filepath.Join(origin(dir), name)
+			if handle := try(path); handle != nil {
+				return handle, path, nil
+			}
+		}
+	}
+	for _, name := range bare {
+		if handle := try(name); handle != nil {
+			return handle, name, nil
+		}
+	}
+	if len(tried.Tried) == 0 {
+		tried.Tried = append(tried.Tried, names)
+		tried.Reasons = append(tried.Reasons, "no library name")
+	}
+	return nil, "", &tried
+}
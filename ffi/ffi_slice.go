@@ -0,0 +1,86 @@
+package ffi
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"qlova.tech/abi"
+	"qlova.tech/ffi/internal/dyncall"
+)
+
+var bufferType = reflect.TypeOf(abi.Buffer{})
+
+// buffer has the same layout as [abi.Buffer].
+type buffer struct {
+	ptr unsafe.Pointer
+	len abi.Int
+}
+
+// lengths are the C types of the length of a split parameter, by
+// the name given to the len= tag option, in lower case.
+var lengths = map[string]reflect.Type{
+	"int":      typeOf[abi.Int](),
+	"unsigned": typeOf[abi.IntUnsigned](),
+	"long":     typeOf[abi.Long](),
+	"size_t":   typeOf[abi.Size](),
+	"int32":    typeOf[abi.Int32](),
+	"uint32":   typeOf[abi.Uint32](),
+	"int64":    typeOf[abi.Int64](),
+	"uint64":   typeOf[abi.Uint64](),
+}
+
+// splits reports whether Go parameter i of the func type is passed to
+// C as a pointer to its first element, followed by its length, given
+// the value of a len= tag option. [abi.Buffer] parameters are always
+// split, other slices are only split if there is a len= option.
+func splits(ftype reflect.Type, i int, length string) bool {
+	if ftype.IsVariadic() && i == ftype.NumIn()-1 {
+		return false
+	}
+	t := ftype.In(i)
+	return t == bufferType || (length != "" && t.Kind() == reflect.Slice)
+}
+
+// lengthType returns the C type of the length of a split parameter,
+// for the value of a len= tag option, the length of an [abi.Buffer]
+// is an int by default.
+func lengthType(length string) reflect.Type {
+	if length == "" {
+		return lengths["int"]
+	}
+	t, ok := lengths[strings.ToLower(length)]
+	if !ok {
+		panic("ffi: unknown len=" + length + " type")
+	}
+	return t
+}
+
+// newLengthStep compiles a push step for the length of a split
+// parameter, as the given C type. Buffers have a length in bytes,
+// slices have a length in elements.
+func newLengthStep(length string) pushStep {
+	t := lengthType(length)
+	push := wordPusher(scalarRune(t))
+	return func(vm *dyncall.VM, value reflect.Value, f *frame) {
+		if value.Type() == bufferType {
+			push(vm, uint64(value.Interface().(abi.Buffer).Len()))
+			return
+		}
+		push(vm, uint64(value.Len()))
+	}
+}
+
+// setLength sets the length of a split parameter that is passed to
+// a Go callback, its pointer has already been set.
+func setLength(value reflect.Value, n int) {
+	if value.Type() == bufferType {
+		(*buffer)(value.Addr().UnsafePointer()).len = abi.Int(n)
+		return
+	}
+	ptr := value.UnsafePointer()
+	if ptr == nil {
+		return
+	}
+	value.Set(reflect.NewAt(reflect.ArrayOf(n, value.Type().Elem()), ptr).Elem().Slice(0, n))
+}
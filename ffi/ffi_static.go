@@ -0,0 +1,147 @@
+package ffi
+
+import (
+	"reflect"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Word constrains the types that are passed to, or returned from
+// C as a single scalar value, by the statically typed [Func0] to
+// [Func6], [FuncErrno0] to [FuncErrno6] and [Proc0] to [Proc6].
+type Word interface {
+	~bool |
+		~int8 | ~int16 | ~int32 | ~int64 | ~int |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint | ~uintptr |
+		~float32 | ~float64 |
+		~unsafe.Pointer
+}
+
+// statics holds the static bindings of each library, registered
+// by code generated with cmd/ffigen.
+var statics sync.Map // map[Library]map[string]func(unsafe.Pointer)
+
+// Static registers static bindings for the func fields of the given
+// library, keyed by field name, each binding sets its field to a
+// func that calls the given symbol. When the library is linked, the
+// bindings are used instead of reflection. Static is called by code
+// generated with cmd/ffigen and should not be called directly.
+func Static(library Library, fields map[string]func(symbol unsafe.Pointer)) {
+	statics.Store(library, fields)
+}
+
+// staticOf returns the static bindings of the library, if any.
+func staticOf(library Library) map[string]func(unsafe.Pointer) {
+	fields, _ := statics.Load(library)
+	bindings, _ := fields.(map[string]func(unsafe.Pointer))
+	return bindings
+}
+
+// Func0 returns a func that calls the C function at symbol.
+func Func0[R Word](symbol unsafe.Pointer) func() R { return fn0[R](symbol) }
+
+// Func1 returns a func that calls the C function at symbol.
+func Func1[A, R Word](symbol unsafe.Pointer) func(A) R { return fn1[A, R](symbol) }
+
+// Func2 returns a func that calls the C function at symbol.
+func Func2[A, B, R Word](symbol unsafe.Pointer) func(A, B) R { return fn2[A, B, R](symbol) }
+
+// Func3 returns a func that calls the C function at symbol.
+func Func3[A, B, C, R Word](symbol unsafe.Pointer) func(A, B, C) R {
+	return fn3[A, B, C, R](symbol)
+}
+
+// Func4 returns a func that calls the C function at symbol.
+func Func4[A, B, C, D, R Word](symbol unsafe.Pointer) func(A, B, C, D) R {
+	return fn4[A, B, C, D, R](symbol)
+}
+
+// Func5 returns a func that calls the C function at symbol.
+func Func5[A, B, C, D, E, R Word](symbol unsafe.Pointer) func(A, B, C, D, E) R {
+	return fn5[A, B, C, D, E, R](symbol)
+}
+
+// Func6 returns a func that calls the C function at symbol.
+func Func6[A, B, C, D, E, F, R Word](symbol unsafe.Pointer) func(A, B, C, D, E, F) R {
+	return fn6[A, B, C, D, E, F, R](symbol)
+}
+
+// Proc0 returns a func that calls the void C function at symbol.
+func Proc0(symbol unsafe.Pointer) func() { return proc0(symbol) }
+
+// Proc1 returns a func that calls the void C function at symbol.
+func Proc1[A Word](symbol unsafe.Pointer) func(A) { return proc1[A](symbol) }
+
+// Proc2 returns a func that calls the void C function at symbol.
+func Proc2[A, B Word](symbol unsafe.Pointer) func(A, B) { return proc2[A, B](symbol) }
+
+// Proc3 returns a func that calls the void C function at symbol.
+func Proc3[A, B, C Word](symbol unsafe.Pointer) func(A, B, C) {
+	return proc3[A, B, C](symbol)
+}
+
+// Proc4 returns a func that calls the void C function at symbol.
+func Proc4[A, B, C, D Word](symbol unsafe.Pointer) func(A, B, C, D) {
+	return proc4[A, B, C, D](symbol)
+}
+
+// Proc5 returns a func that calls the void C function at symbol.
+func Proc5[A, B, C, D, E Word](symbol unsafe.Pointer) func(A, B, C, D, E) {
+	return proc5[A, B, C, D, E](symbol)
+}
+
+// Proc6 returns a func that calls the void C function at symbol.
+func Proc6[A, B, C, D, E, F Word](symbol unsafe.Pointer) func(A, B, C, D, E, F) {
+	return proc6[A, B, C, D, E, F](symbol)
+}
+
+// FuncErrno0 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno0[R Word](symbol unsafe.Pointer) func() (R, syscall.Errno) {
+	return fnErrno0[R](symbol)
+}
+
+// FuncErrno1 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno1[A, R Word](symbol unsafe.Pointer) func(A) (R, syscall.Errno) {
+	return fnErrno1[A, R](symbol)
+}
+
+// FuncErrno2 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno2[A, B, R Word](symbol unsafe.Pointer) func(A, B) (R, syscall.Errno) {
+	return fnErrno2[A, B, R](symbol)
+}
+
+// FuncErrno3 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno3[A, B, C, R Word](symbol unsafe.Pointer) func(A, B, C) (R, syscall.Errno) {
+	return fnErrno3[A, B, C, R](symbol)
+}
+
+// FuncErrno4 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno4[A, B, C, D, R Word](symbol unsafe.Pointer) func(A, B, C, D) (R, syscall.Errno) {
+	return fnErrno4[A, B, C, D, R](symbol)
+}
+
+// FuncErrno5 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno5[A, B, C, D, E, R Word](symbol unsafe.Pointer) func(A, B, C, D, E) (R, syscall.Errno) {
+	return fnErrno5[A, B, C, D, E, R](symbol)
+}
+
+// FuncErrno6 returns a func that calls the C function at symbol and
+// returns errno, as observed after the call, along with its result.
+func FuncErrno6[A, B, C, D, E, F, R Word](symbol unsafe.Pointer) func(A, B, C, D, E, F) (R, syscall.Errno) {
+	return fnErrno6[A, B, C, D, E, F, R](symbol)
+}
+
+// Failure returns the error of a failed call to the C function name, as
+// described by the library's Error field, describe, if it is not nil,
+// or else by errno, see [Set]. Failure is called by code generated with
+// cmd/ffigen and should not be called directly.
+func Failure(describe func() string, errno syscall.Errno, name string) error {
+	return call{name: name, getErr: reflect.ValueOf(describe)}.err(errno)
+}
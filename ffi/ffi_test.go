@@ -1,22 +1,41 @@
 package ffi_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
+	"unsafe"
 
 	"qlova.tech/abi"
+	"qlova.tech/ffi"
+	"qlova.tech/ffi/internal/dyncall"
 	"qlova.tech/lib/std"
 )
 
 func init() {
+	ffi.LockMainThread()
 	if err := std.Link(); err != nil {
 		panic(err)
 	}
 }
 
 func TestMain(m *testing.M) {
-	std.Program.Exit(abi.Int(m.Run()))
+	var code int
+	ffi.Main(func() { code = m.Run() })
+	std.Program.Exit(abi.Int(code))
 }
 
 func TestLibc(t *testing.T) {
@@ -35,6 +54,345 @@ func TestLibc(t *testing.T) {
 	})
 }
 
+func TestVariadic(t *testing.T) {
+	var buf [64]byte
+	dst := abi.NewString(unsafe.String(&buf[0], len(buf)))
+	n := std.String.Printf(dst, "%d %s %.1f %.2f %d %c %p", 3, "x", 1.5, float32(0.25), int8(-2), 'y', nil)
+	if got, want := dst.String(), "3 x 1.5 0.25 -2 y (nil)"; got != want || int(n) != len(want) {
+		t.Fatalf("sprintf: got %q (%d), want %q", got, n, want)
+	}
+}
+
+func TestCallback(t *testing.T) {
+	cmp := ffi.NewCallback(func(a, b abi.UnsafePointer) abi.Int {
+		return abi.Int(*(*int32)(a) - *(*int32)(b))
+	})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				values := [...]int32{3, 1, 2}
+				std.Memory.Sort(abi.UnsafePointer(&values[0]), 3, 4, cmp.Func())
+				if values != [...]int32{1, 2, 3} {
+					t.Errorf("qsort: got %v", values)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if cmp.Pointer() == nil {
+		t.Fatal("live callback has no pointer")
+	}
+	cmp.Free()
+	cmp.Free()
+	if cmp.Pointer() != nil {
+		t.Fatal("freed callback has a pointer")
+	}
+
+	// Go funcs passed to a call are only valid for the call.
+	handlers := dyncall.Handlers()
+	for i := int32(1); i <= 100; i++ {
+		values := [...]int32{3, 1, 2}
+		std.Memory.Sort(abi.UnsafePointer(&values[0]), 3, 4, func(a, b abi.UnsafePointer) abi.Int {
+			return abi.Int((*(*int32)(a) - *(*int32)(b)) * i)
+		})
+		if values != [...]int32{1, 2, 3} {
+			t.Fatalf("qsort: got %v", values)
+		}
+	}
+	if n := dyncall.Handlers(); n != handlers {
+		t.Fatal("callbacks leaked by qsort:", n-handlers)
+	}
+}
+
+func TestErrno(t *testing.T) {
+	if file, err := std.Files.Open("/ffi/missing", "r"); file != nil || !errors.Is(err, syscall.ENOENT) {
+		t.Fatal("expected ENOENT, got", file, err)
+	}
+	var lib struct {
+		std.LibC
+
+		Remove func(string) (abi.Int, error)                      `ffi:"remove,err=-1"`
+		Access func(string, abi.Int) error                        `ffi:"access"`
+		Chdir  func(string) (abi.Int, error)                      `ffi:"chdir,err=nonzero"`
+		Strtol func(string, *abi.Char, abi.Int) (abi.Long, error) `ffi:"strtol,err=errno"`
+	}
+	if err := ffi.Link(&lib); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.Remove("/ffi/missing"); !errors.Is(err, syscall.ENOENT) {
+		t.Fatal("remove: expected ENOENT, got", err)
+	}
+	if err := lib.Access("/ffi/missing", 0); !errors.Is(err, syscall.ENOENT) {
+		t.Fatal("access: expected ENOENT, got", err)
+	}
+	if _, err := lib.Chdir("."); err != nil {
+		t.Fatal("chdir:", err)
+	}
+	if _, err := lib.Strtol("99999999999999999999999", nil, 10); !errors.Is(err, syscall.ERANGE) {
+		t.Fatal("strtol: expected ERANGE, got", err)
+	}
+	if n, err := lib.Strtol("42", nil, 10); n != 42 || err != nil {
+		t.Fatal("strtol:", n, err)
+	}
+}
+
+func TestThread(t *testing.T) {
+	var bound struct {
+		ffi.Library `linux:"libc.so.6" darwin:"libSystem.dylib" thread:"main"`
+
+		Self func() abi.UnsafePointer `ffi:"pthread_self"`
+	}
+	var anywhere struct {
+		std.LibC
+
+		Self func() abi.UnsafePointer `ffi:"pthread_self"`
+	}
+	if err := ffi.Link(&bound, &anywhere); err != nil {
+		t.Fatal(err)
+	}
+	self := bound.Self()
+	if self == anywhere.Self() {
+		t.Fatal("test goroutine is running on the main thread")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if thread := bound.Self(); thread != self {
+				t.Error("called on", thread, "instead of the main thread", self)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLinkError(t *testing.T) {
+	var lib struct {
+		std.LibC
+
+		Length   func(abi.String) abi.Size `ffi:"ffi_missing_symbol,strlen"`
+		Missing  func()                    `ffi:"ffi_missing_symbol"`
+		Optional func()                    `ffi:"ffi_missing_symbol,optional"`
+	}
+	err := ffi.Link(&lib)
+	var missing *ffi.LinkError
+	if !errors.As(err, &missing) {
+		t.Fatal("expected a link error, got", err)
+	}
+	for _, symbols := range missing.Symbols {
+		if len(symbols) != 1 || symbols[0] != "ffi_missing_symbol" {
+			t.Fatal("unexpected missing symbols", symbols)
+		}
+	}
+	if !ffi.Bound(&lib, "Length") || ffi.Bound(&lib, "Missing") || ffi.Bound(&lib, "Optional") {
+		t.Fatal("unexpected bound fields")
+	}
+	if n := lib.Length(abi.NewString("abc")); n != 3 {
+		t.Fatal("unexpected length", n)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var lib struct {
+		ffi.Library `linux:"libc.so.6" darwin:"libSystem.dylib" lazy:"true"`
+
+		Length   func(abi.String) abi.Size                    `ffi:"ffi_missing_symbol,strlen"`
+		Printf   func(abi.String, abi.String, ...any) abi.Int `ffi:"sprintf"`
+		Missing  func()                                       `ffi:"ffi_missing_symbol"`
+		Optional func() error                                 `ffi:"ffi_missing_symbol,optional"`
+	}
+	if err := ffi.Link(&lib); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&lib)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n := lib.Length(abi.NewString("abc")); n != 3 {
+				t.Error("unexpected length", n)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var buf [16]byte
+	dst := abi.NewString(unsafe.String(&buf[0], len(buf)))
+	if lib.Printf(dst, abi.NewString("%d-%d"), 1, 2); dst.String() != "1-2" {
+		t.Fatal("unexpected sprintf", dst.String())
+	}
+	if err := lib.Optional(); err == nil {
+		t.Fatal("expected an error from a missing symbol")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a missing symbol to panic")
+			}
+		}()
+		lib.Missing()
+	}()
+
+	err := ffi.Preload(&lib)
+	var missing *ffi.LinkError
+	if !errors.As(err, &missing) {
+		t.Fatal("expected a link error, got", err)
+	}
+	for _, symbols := range missing.Symbols {
+		if len(symbols) != 1 || symbols[0] != "ffi_missing_symbol" {
+			t.Fatal("unexpected missing symbols", symbols)
+		}
+	}
+	if !ffi.Bound(&lib, "Length") || !ffi.Bound(&lib, "Printf") || ffi.Bound(&lib, "Optional") {
+		t.Fatal("unexpected bound fields")
+	}
+}
+
+// libcVersion pretends to be a particular version of libc.
+type libcVersion struct {
+	ffi.Library `linux:"libc.so.6" darwin:"libSystem.dylib"`
+}
+
+func (libcVersion) LibraryVersion() string { return "2.5.1" }
+
+func TestVersion(t *testing.T) {
+	var lib struct {
+		libcVersion
+
+		Length  func(abi.String) abi.Size            `ffi:"strlen" since:"2.5"`
+		Future  func(abi.String) (abi.Size, error)   `ffi:"strlen" since:"2.10"`
+		Missing func() error                         `ffi:"ffi_missing_symbol" since:"3"`
+		Dup     func(abi.String) (abi.String, error) `ffi:"strdup"`
+	}
+	if err := ffi.Link(&lib); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&lib)
+
+	if n := lib.Length(abi.NewString("abc")); n != 3 {
+		t.Fatal("unexpected length", n)
+	}
+	if _, err := lib.Future(abi.NewString("abc")); err != ffi.ErrUnavailable {
+		t.Fatal("expected ErrUnavailable, got", err)
+	}
+	if err := lib.Missing(); err != ffi.ErrUnavailable {
+		t.Fatal("expected ErrUnavailable, got", err)
+	}
+	if err := ffi.Preload(&lib); err != nil {
+		t.Fatal(err)
+	}
+	features := ffi.Features(&lib)
+	want := []ffi.Feature{
+		{Field: "Length", Since: "2.5", Available: true},
+		{Field: "Future", Since: "2.10"},
+		{Field: "Missing", Since: "3"},
+	}
+	if fmt.Sprint(features) != fmt.Sprint(want) {
+		t.Fatalf("unexpected features %v, want %v", features, want)
+	}
+}
+
+// libcFlaky panics the first time it is asked for its version.
+type libcFlaky struct {
+	ffi.Library `linux:"libc.so.6" darwin:"libSystem.dylib"`
+
+	asked bool
+}
+
+func (lib *libcFlaky) LibraryVersion() string {
+	if !lib.asked {
+		lib.asked = true
+		panic("boom")
+	}
+	return "2.5"
+}
+
+func TestVersionPanic(t *testing.T) {
+	var lib struct {
+		libcFlaky
+
+		Length func(abi.String) abi.Size `ffi:"strlen" since:"2.5"`
+	}
+	if err := ffi.Link(&lib); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&lib)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatal("expected the LibraryVersion panic, got", r)
+			}
+		}()
+		lib.Length(abi.NewString("abc"))
+	}()
+	if n := lib.Length(abi.NewString("abc")); n != 3 {
+		t.Fatal("unexpected length", n)
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	defer func(path []string) { ffi.SearchPath = path }(ffi.SearchPath)
+	ffi.SearchPath = []string{"/ffi/missing", "$ORIGIN/lib"}
+
+	var lib struct {
+		std.LibC
+
+		Length func(abi.String) abi.Size `ffi:"strlen"`
+	}
+	err := ffi.Set(&lib, "libffi_missing.so.0,libffi_missing.so")
+	var load *ffi.LoadError
+	if !errors.As(err, &load) {
+		t.Fatal("expected a load error, got", err)
+	}
+	if len(load.Tried) != 6 || load.Tried[0] != "/ffi/missing/libffi_missing.so.0" || load.Tried[5] != "libffi_missing.so" {
+		t.Fatal("unexpected paths tried", load.Tried)
+	}
+	if err := ffi.Set(&lib, "libffi_missing.so,libc.so.6"); err != nil {
+		t.Fatal(err)
+	}
+	if n := lib.Length(abi.NewString("abcd")); n != 4 {
+		t.Fatal("unexpected length", n)
+	}
+}
+
+func TestUnlink(t *testing.T) {
+	var a, b struct {
+		std.LibC
+
+		Length func(abi.String) abi.Size            `ffi:"strlen"`
+		Dup    func(abi.String) (abi.String, error) `ffi:"strdup"`
+	}
+	if err := ffi.Link(&a, &b); err != nil {
+		t.Fatal(err)
+	}
+	ffi.Unlink(&a)
+	if _, err := a.Dup(abi.NewString("abc")); err != ffi.ErrUnlinked {
+		t.Fatal("expected ErrUnlinked, got", err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ffi.ErrUnlinked {
+				t.Fatal("expected ErrUnlinked panic, got", r)
+			}
+		}()
+		a.Length(abi.NewString("abc"))
+	}()
+	if n := b.Length(abi.NewString("abc")); n != 3 {
+		t.Fatal("unexpected length", n)
+	}
+	ffi.Unlink(&b)
+	if ffi.Bound(&b, "Length") {
+		t.Fatal("unlinked library is still bound")
+	}
+}
+
 func BenchmarkGo(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		math.Sqrt(2)
@@ -46,3 +404,789 @@ func BenchmarkC(b *testing.B) {
 		std.Double.Sqrt(2)
 	}
 }
+
+func TestStructs(t *testing.T) {
+	if div := std.Int.Div(7, 2); div.Quo != 3 || div.Rem != 1 {
+		t.Fatal("unexpected div result", div)
+	}
+	if div := std.Long.Div(-9, 4); div.Quo != -2 || div.Rem != -1 {
+		t.Fatal("unexpected ldiv result", div)
+	}
+	if abs := std.Complex.Abs(abi.ComplexDouble{3, 4}); abs != 5 {
+		t.Fatal("unexpected cabs result", abs)
+	}
+	if conj := std.Complex.Conj(abi.ComplexDouble{1, 2}); conj != (abi.ComplexDouble{1, -2}) {
+		t.Fatal("unexpected conj result", conj)
+	}
+	if conj := std.ComplexFloat.Conj(abi.ComplexFloat{1, 2}); conj != (abi.ComplexFloat{1, -2}) {
+		t.Fatal("unexpected conjf result", conj)
+	}
+}
+
+func TestLongDouble(t *testing.T) {
+	var x abi.DoubleLong
+	if reflect.TypeOf(x).Kind() != reflect.Array {
+		t.Skip("long double is a double")
+	}
+	defer func() {
+		if r := recover(); !strings.Contains(fmt.Sprint(r), "long double is not supported") {
+			t.Fatal("expected lroundl to panic, got", r)
+		}
+	}()
+	t.Fatal("unexpected lroundl result", std.Long.RoundLong(x))
+}
+
+func BenchmarkCPlan(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		std.Double.Frexp(2.2)
+	}
+}
+
+func TestVar(t *testing.T) {
+	var libc struct {
+		std.LibC
+
+		Timezone *abi.Long                  `ffi:"timezone"`
+		Daylight abi.Pointer[abi.Int]       `ffi:"daylight"`
+		Missing  ffi.Var[abi.Int]           `ffi:"ffi_missing_variable,optional"`
+		Tzset    func()                     `ffi:"tzset"`
+		Stdout   ffi.Var[abi.UnsafePointer] `ffi:"stdout"`
+	}
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+
+	stdout := std.Files.Stdout.Load()
+	if stdout == nil || abi.UnsafePointer(stdout) != libc.Stdout.Load() {
+		t.Fatalf("stdout: got %p, want %p", stdout, libc.Stdout.Load())
+	}
+	if std.Files.Flush(stdout) != 0 {
+		t.Fatal("fflush(stdout) failed")
+	}
+
+	libc.Tzset()
+	if libc.Timezone == nil || libc.Daylight.Pointer() == 0 {
+		t.Fatal("timezone and daylight are not bound")
+	}
+	saved := *libc.Timezone
+	*libc.Timezone = 3600
+	if got := *libc.Timezone; got != 3600 {
+		t.Errorf("timezone: got %v", got)
+	}
+	*libc.Timezone = saved
+
+	if ffi.Bound(&libc, "Missing") || libc.Missing.Addr() != nil {
+		t.Fatal("missing variable is bound")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("loading a missing variable did not panic")
+			}
+		}()
+		libc.Missing.Load()
+	}()
+
+	ffi.Unlink(&libc)
+	if libc.Timezone != nil || libc.Stdout.Addr() != nil {
+		t.Fatal("unlinked variables are still bound")
+	}
+}
+
+func TestWithoutCgo(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("cgo is required on " + runtime.GOOS + "/" + runtime.GOARCH)
+	}
+	if os.Getenv("CGO_ENABLED") == "0" {
+		return // this is the test being run.
+	}
+	if testing.Short() {
+		t.Skip("skipping build in short mode")
+	}
+	cmd := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "test", "-count=1", ".")
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+// TestWithoutCgoElsewhere checks that the packages still build without
+// cgo on targets where C cannot be called without it.
+func TestWithoutCgoElsewhere(t *testing.T) {
+	if os.Getenv("CGO_ENABLED") == "0" {
+		return
+	}
+	if testing.Short() {
+		t.Skip("skipping build in short mode")
+	}
+	for _, goarch := range []string{"arm64", "386"} {
+		cmd := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "vet", "./...")
+		cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH="+goarch)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("GOARCH=%v: %v\n%s", goarch, err, out)
+		}
+	}
+}
+
+type recorder struct {
+	calls []*ffi.Call
+	ended int
+}
+
+func (r *recorder) TraceCall(call *ffi.Call) func() {
+	r.calls = append(r.calls, call)
+	return func() { r.ended++ }
+}
+
+func TestTrace(t *testing.T) {
+	var lib struct {
+		std.LibC
+
+		Abs    func(abi.Int) abi.Int                `ffi:"abs"`
+		Remove func(string) (abi.Int, error)        `ffi:"remove,err=-1"`
+		Strlen func(abi.String) abi.Size            `ffi:"strlen"`
+		Cmp    func(abi.String, abi.String) abi.Int `ffi:"strcmp"`
+		Printf func(string, ...any) abi.Int         `ffi:"printf"`
+	}
+	if err := ffi.Link(&lib); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&lib)
+
+	var traced recorder
+	ffi.Trace(&lib, &traced)
+	if lib.Abs(-3) != 3 || lib.Strlen(abi.NewString("hello")) != 5 {
+		t.Fatal("traced calls returned the wrong results")
+	}
+	lib.Remove("/ffi/missing")
+	if len(traced.calls) != 3 || traced.ended != 3 {
+		t.Fatalf("expected 3 traced calls, got %d (%d ended)", len(traced.calls), traced.ended)
+	}
+	func() {
+		defer func() { recover() }()
+		lib.Printf("%d\n", struct{ x int }{})
+	}()
+	if len(traced.calls) != 4 || traced.ended != 4 {
+		t.Fatalf("expected the panicking call to be ended, got %d calls (%d ended)", len(traced.calls), traced.ended)
+	}
+	traced.calls = traced.calls[:3]
+	abs := traced.calls[0]
+	if abs.Library != &lib || abs.Symbol != "abs" || abs.Args[0] != abi.Int(-3) || abs.Results[0] != abi.Int(3) || abs.Goroutine == 0 {
+		t.Errorf("abs: unexpected trace %+v", abs)
+	}
+	if remove := traced.calls[2]; remove.Errno != syscall.ENOENT {
+		t.Errorf("remove: expected ENOENT, got %v", remove.Errno)
+	}
+
+	var global, log bytes.Buffer
+	ffi.SetTracer(ffi.LogTracer(&global))
+	defer ffi.SetTracer(nil)
+	ffi.Trace(&lib, ffi.LogTracer(&log))
+	lib.Cmp(abi.NewString("a"), abi.NewString("a"))
+	if !strings.Contains(log.String(), " strcmp(a, a) = 0 [") || global.Len() != 0 {
+		t.Errorf("unexpected logs %q, %q", log.String(), global.String())
+	}
+	ffi.Trace(&lib, nil)
+	lib.Abs(1)
+	if !strings.Contains(global.String(), " abs(1) = 1 [") {
+		t.Errorf("unexpected global log %q", global.String())
+	}
+
+	ffi.SetTracer(nil)
+	global.Reset()
+	lib.Abs(1)
+	if global.Len() != 0 || len(traced.calls) != 3 {
+		t.Error("untraced call was traced")
+	}
+
+	var execution bytes.Buffer
+	if err := trace.Start(&execution); err != nil {
+		t.Fatal(err)
+	}
+	ffi.Trace(&lib, ffi.RegionTracer())
+	lib.Abs(-1)
+	trace.Stop()
+	if !bytes.Contains(execution.Bytes(), []byte("abs")) {
+		t.Error("execution trace is missing the abs region")
+	}
+}
+
+type mockFiles struct {
+	Printf func(string, ...any) abi.Int
+
+	open int
+}
+
+func (m *mockFiles) Open(path string) (abi.Int, error) {
+	m.open++
+	if path == "/missing" {
+		return -1, syscall.ENOENT
+	}
+	return 3, nil
+}
+
+func (m *mockFiles) Close(fd abi.Int) string { return "wrong" }
+
+func TestMock(t *testing.T) {
+	var lib struct {
+		ffi.Library `linux:"libmissing.so.1"`
+
+		Open    func(string) (abi.Int, error) `ffi:"open"`
+		Close   func(abi.Int) abi.Int         `ffi:"close"`
+		Printf  func(string, ...any) abi.Int  `ffi:"printf"`
+		Missing func() (abi.Int, error)       `ffi:"missing"`
+		Errno   *abi.Int                      `ffi:"errno"`
+	}
+	impl := &mockFiles{Printf: func(format string, args ...any) abi.Int {
+		return abi.Int(len(fmt.Sprintf(format, args...)))
+	}}
+	calls, err := ffi.Mock(&lib, impl)
+	if err == nil || !strings.Contains(err.Error(), "mock Close") {
+		t.Fatal("expected Close to be reported as mismatched, got", err)
+	}
+	if fd, err := lib.Open("/tmp"); fd != 3 || err != nil {
+		t.Fatal("open:", fd, err)
+	}
+	if _, err := lib.Open("/missing"); !errors.Is(err, syscall.ENOENT) {
+		t.Fatal("open: expected ENOENT, got", err)
+	}
+	if n := lib.Printf("%d%s", 10, "ab"); n != 4 {
+		t.Fatal("printf:", n)
+	}
+	if _, err := lib.Missing(); err == nil {
+		t.Fatal("unmocked func did not fail")
+	}
+	if !ffi.Bound(&lib, "Open") || ffi.Bound(&lib, "Close") || lib.Errno != nil {
+		t.Fatal("unexpected bindings")
+	}
+
+	opened := calls.Calls("Open")
+	if len(opened) != 2 || impl.open != 2 || opened[1].Args[0] != "/missing" || opened[1].Results[1] != syscall.ENOENT {
+		t.Fatalf("unexpected recording %+v", opened)
+	}
+	if all := calls.Calls(""); len(all) != 3 || all[2].Symbol != "printf" || all[2].Args[1].([]any)[1] != "ab" {
+		t.Fatalf("unexpected recording %+v", all)
+	}
+	calls.Reset()
+	if len(calls.Calls("")) != 0 {
+		t.Fatal("recording was not reset")
+	}
+
+	ffi.Unlink(&lib)
+	if _, err := lib.Open("/tmp"); err != ffi.ErrUnlinked {
+		t.Fatal("expected ErrUnlinked, got", err)
+	}
+}
+
+type point struct {
+	X, Y abi.Double
+}
+
+type hostAPI struct {
+	ffi.Library
+
+	Compare func(a, b abi.UnsafePointer) abi.Int    `ffi:"compare"`
+	Length  func(abi.String) abi.Size               `ffi:"length"`
+	Scale   func(point, abi.Double) point           `ffi:"scale"`
+	OnExit  func(abi.Func[func(abi.Int)], *abi.Int) `ffi:"on_exit"`
+	Unused  func()
+}
+
+func TestExport(t *testing.T) {
+	host := hostAPI{
+		Compare: func(a, b abi.UnsafePointer) abi.Int {
+			return abi.Int(*(*int32)(a) - *(*int32)(b))
+		},
+		Length: func(s abi.String) abi.Size { return abi.Size(len(s.String())) },
+		Scale:  func(p point, by abi.Double) point { return point{p.X * by, p.Y * by} },
+		OnExit: func(abi.Func[func(abi.Int)], *abi.Int) {},
+	}
+	exports, err := ffi.Export(&host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exports.Free()
+
+	table := unsafe.Slice((*abi.UnsafePointer)(exports.Pointer()), 5)
+	if table[0] == nil || table[4] != nil {
+		t.Fatal("unexpected table", table)
+	}
+	var libc struct {
+		std.LibC
+
+		Sort func(abi.UnsafePointer, abi.Size, abi.Size, abi.UnsafePointer) `ffi:"qsort"`
+	}
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+	values := [...]int32{3, 1, 2}
+	libc.Sort(abi.UnsafePointer(&values[0]), 3, 4, table[0])
+	if values != [...]int32{1, 2, 3} {
+		t.Fatal("qsort through the exported table:", values)
+	}
+
+	var header strings.Builder
+	if err := ffi.ExportHeader(&header, "host", &host); err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by ffi.ExportHeader; DO NOT EDIT.
+
+#ifndef HOST_H
+#define HOST_H
+
+#include <stdbool.h>
+#include <stddef.h>
+#include <stdint.h>
+
+struct point {
+	double X;
+	double Y;
+};
+
+struct host {
+	int (*compare)(void *, void *);
+	size_t (*length)(const char *);
+	struct point (*scale)(struct point, double);
+	void (*on_exit)(void (*)(int), int *);
+	void (*Unused)(void);
+};
+
+#endif // HOST_H
+`
+	if got := header.String(); got != want {
+		t.Fatalf("unexpected header:\n%s", got)
+	}
+
+	var unsupported struct {
+		ffi.Library
+
+		Printf func(string, ...any) abi.Int
+	}
+	if _, err := ffi.Export(&unsupported); err == nil {
+		t.Fatal("exported a variadic func")
+	}
+	exports.Free()
+	if exports.Pointer() != nil {
+		t.Fatal("freed exports have a pointer")
+	}
+}
+
+func TestSlices(t *testing.T) {
+	var lib struct {
+		std.LibC
+
+		Length func([]byte) abi.Size                                            `ffi:"strlen"`
+		Sort   func(base []int32, size abi.Size, cmp func(a, b *int32) abi.Int) `ffi:"qsort,len=size_t"`
+		Format func(dst abi.Buffer, format string, args ...any) abi.Int         `ffi:"snprintf,len=size_t"`
+	}
+	if err := ffi.Link(&lib); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&lib)
+
+	if n := lib.Length([]byte("abc\x00")); n != 3 {
+		t.Fatal("unexpected length", n)
+	}
+	values := []int32{3, 1, 2}
+	lib.Sort(values, 4, func(a, b *int32) abi.Int { return abi.Int(*a - *b) })
+	if values[0] != 1 || values[1] != 2 || values[2] != 3 {
+		t.Fatal("qsort:", values)
+	}
+	lib.Sort(nil, 4, nil)
+
+	type pair struct{ key, value int16 }
+	pairs := []pair{{3, 0}, {1, 1}, {2, 2}}
+	std.Sort(pairs, func(a, b *pair) abi.Int { return abi.Int(a.key - b.key) })
+	if pairs[0] != (pair{1, 1}) || pairs[1] != (pair{2, 2}) || pairs[2] != (pair{3, 0}) {
+		t.Fatal("std.Sort:", pairs)
+	}
+
+	buf := make([]byte, 4)
+	if n := lib.Format(abi.NewBuffer(buf), "%d", 12345); n != 5 || string(buf) != "123\x00" {
+		t.Fatalf("snprintf: %d %q", n, buf)
+	}
+
+	// a buffer passed from Go to C, and from C to Go.
+	sum := ffi.Func(ffi.NewFunc(func(b abi.Buffer) abi.Int {
+		var sum abi.Int
+		for _, c := range b.Bytes() {
+			sum += abi.Int(c)
+		}
+		return sum
+	}))
+	if n := sum(abi.NewBuffer([]byte{1, 2, 3})); n != 6 {
+		t.Fatal("unexpected sum", n)
+	}
+
+	var header strings.Builder
+	if err := ffi.ExportHeader(&header, "host", &struct {
+		ffi.Library
+
+		Write func(abi.Buffer) abi.Int           `ffi:"write"`
+		Sum   func([]abi.Long, abi.Int) abi.Long `ffi:"sum,len=uint32"`
+	}{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.String(); !strings.Contains(got, "\tint (*write)(uint8_t *, int);\n\tlong (*sum)(long *, uint32_t, int);\n") {
+		t.Fatalf("unexpected header:\n%s", got)
+	}
+}
+
+func TestRetain(t *testing.T) {
+	var libc struct {
+		std.LibC
+
+		Close func(*abi.File) abi.Int `ffi:"fclose"`
+	}
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+
+	file := std.Files.Temp()
+	if file == nil {
+		t.Fatal("tmpfile failed")
+	}
+	buf := make([]byte, abi.BufferSize)
+	ffi.Retain(buf)
+	if std.Files.SetBufferMode(file, abi.UnsafePointer(&buf[0]), abi.FullyBuffered, abi.Size(len(buf))) != 0 {
+		t.Fatal("setvbuf failed")
+	}
+	runtime.GC()
+	std.Files.PutString(abi.NewString("hello"), file)
+	if !bytes.Contains(buf, []byte("hello")) {
+		t.Fatalf("retained buffer was not used by C: %q", buf)
+	}
+	libc.Close(file)
+	ffi.Release(buf)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("releasing memory that is not retained did not panic")
+		}
+	}()
+	ffi.Release(buf)
+}
+
+func TestEscape(t *testing.T) {
+	// the checks are only built with the ffidebug tag, so the test is
+	// run by a test binary that is built with it.
+	if os.Getenv("FFI_TEST_ESCAPE") == "" {
+		if testing.Short() {
+			t.Skip("builds the test with -tags ffidebug and runs it with " + ffi.DebugEnv + "=escape=1")
+		}
+		cmd := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "test", "-count=1", "-tags", "ffidebug", "-run=^TestEscape$", "-v", ".")
+		cmd.Env = append(os.Environ(), ffi.DebugEnv+"=escape=1", "FFI_TEST_ESCAPE=1")
+		if out, err := cmd.CombinedOutput(); err != nil || !bytes.Contains(out, []byte("--- PASS: TestEscape")) {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		return
+	}
+	var libc struct {
+		std.LibC
+
+		Strtok func(abi.UnsafePointer, string) abi.UnsafePointer `ffi:"strtok"`
+		Strlen func(*[2]*abi.Char) abi.Size                      `ffi:"strlen"`
+	}
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+
+	escaped := func(fn func()) (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		fn()
+		return
+	}
+	// large enough to not share a tiny allocation with the delimiter.
+	tokens := []byte("the first token, then the second\x00")
+	ffi.Retain(tokens)
+	libc.Strtok(abi.UnsafePointer(&tokens[0]), " ")
+	if msg := escaped(func() { libc.Strtok(nil, " ") }); msg != "<nil>" {
+		t.Fatal("retained memory reported as escaped:", msg)
+	}
+	ffi.Release(tokens)
+
+	tokens = []byte("the first token, then the second\x00")
+	libc.Strtok(abi.UnsafePointer(&tokens[0]), " ")
+	if msg := escaped(func() { libc.Strtok(nil, " ") }); !strings.Contains(msg, "escaped into C") {
+		t.Fatal("expected strtok to report an escaped pointer, got", msg)
+	}
+
+	nested := &[2]*abi.Char{new(abi.Char)}
+	if msg := escaped(func() { libc.Strlen(nested) }); !strings.Contains(msg, "unpinned Go pointer") {
+		t.Fatal("expected an unpinned Go pointer to be reported, got", msg)
+	}
+}
+
+func TestFunc(t *testing.T) {
+	var libc struct {
+		std.LibC
+
+		Strlen abi.Func[func(string) abi.Size]                                                  `ffi:"strlen"`
+		Labs   abi.Func[func(abi.Long) abi.Long]                                                `ffi:"labs"`
+		Sort   func(abi.UnsafePointer, abi.Size, abi.Size, abi.Func[func(a, b *int32) abi.Int]) `ffi:"qsort"`
+	}
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+
+	if n := ffi.Func(libc.Strlen)("hello"); n != 5 {
+		t.Fatal("strlen through a function pointer:", n)
+	}
+	if n := ffi.Func(libc.Labs)(-3); n != 3 {
+		t.Fatal("labs through a function pointer:", n)
+	}
+
+	cmp := ffi.NewFunc(func(a, b *int32) abi.Int { return abi.Int(*a - *b) })
+	if cmp.Pointer() == 0 || ffi.NewFunc(func(a, b *int32) abi.Int { return 0 }).Pointer() == cmp.Pointer() {
+		t.Fatal("unexpected function pointers")
+	}
+	values := [...]int32{3, 1, 2}
+	libc.Sort(abi.UnsafePointer(&values[0]), 3, 4, cmp)
+	if values != [...]int32{1, 2, 3} {
+		t.Fatal("qsort with a Go function pointer:", values)
+	}
+	a, b := int32(2), int32(5)
+	if got := ffi.Func(cmp)(&a, &b); got != -3 {
+		t.Fatal("calling a Go function pointer:", got)
+	}
+
+	var null abi.Func[func() (abi.Int, error)]
+	if _, err := ffi.Func(null)(); err == nil {
+		t.Fatal("nil function pointer did not fail")
+	}
+}
+
+type pthreads struct {
+	std.LibC
+
+	Create func(*abi.LongUnsigned, abi.UnsafePointer, func(abi.UnsafePointer) abi.UnsafePointer, abi.UnsafePointer) abi.Int `ffi:"pthread_create,keep"`
+	Join   func(abi.LongUnsigned, *abi.UnsafePointer) abi.Int                                                               `ffi:"pthread_join"`
+}
+
+// onForeignThread calls fn on a new thread created by C.
+func (p *pthreads) onForeignThread(t *testing.T, fn func(abi.UnsafePointer) abi.UnsafePointer) abi.UnsafePointer {
+	var thread abi.LongUnsigned
+	if p.Create(&thread, nil, fn, nil) != 0 {
+		t.Fatal("pthread_create failed")
+	}
+	var result abi.UnsafePointer
+	if p.Join(thread, &result) != 0 {
+		t.Fatal("pthread_join failed")
+	}
+	return result
+}
+
+func TestForeignThread(t *testing.T) {
+	var libc pthreads
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+
+	tid := syscall.Gettid()
+	var foreign int
+	libc.onForeignThread(t, func(abi.UnsafePointer) abi.UnsafePointer {
+		foreign = syscall.Gettid()
+		return nil
+	})
+	if foreign == 0 || foreign == tid {
+		t.Fatal("callback did not run on a foreign thread", foreign, tid)
+	}
+
+	var handled []*ffi.CallbackPanic
+	ffi.SetPanicHandler(func(p *ffi.CallbackPanic) { handled = append(handled, p) })
+	defer ffi.SetPanicHandler(nil)
+	result := libc.onForeignThread(t, func(abi.UnsafePointer) abi.UnsafePointer {
+		panic("foreign")
+	})
+	if result != nil || len(handled) != 1 || handled[0].Value != "foreign" || !bytes.Contains(handled[0].Stack, []byte("TestForeignThread")) {
+		t.Fatalf("unexpected panic handling %v %+v", result, handled)
+	}
+	values := [...]int32{3, 1, 2}
+	std.Memory.Sort(abi.UnsafePointer(&values[0]), 3, 4, func(a, b abi.UnsafePointer) abi.Int {
+		panic(errors.New("compare"))
+	})
+	if len(handled) < 2 {
+		t.Fatal("expected the comparisons to panic, got", handled)
+	}
+	for _, p := range handled[1:] {
+		if err := errors.Unwrap(p); err == nil || err.Error() != "compare" {
+			t.Fatal("expected each comparison to panic, got", handled)
+		}
+	}
+}
+
+func TestQueue(t *testing.T) {
+	var libc pthreads
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+
+	queue := ffi.NewQueue()
+	stop := make(chan struct{})
+	running := make(chan int)
+	go func() {
+		runtime.LockOSThread()
+		running <- syscall.Gettid()
+		queue.Run(stop)
+	}()
+	runner := <-running
+	defer close(stop)
+
+	var threads []int
+	var nested func() int
+	nested = ffi.Queued(queue, func() int {
+		threads = append(threads, syscall.Gettid())
+		return len(threads)
+	})
+	callback := ffi.Queued(queue, func(arg abi.UnsafePointer) abi.UnsafePointer {
+		threads = append(threads, syscall.Gettid())
+		nested() // on the queue's goroutine, so it is called directly.
+		return arg
+	})
+	if result := libc.onForeignThread(t, callback); result != nil {
+		t.Fatal("unexpected result", result)
+	}
+	if len(threads) != 2 || threads[0] != runner || threads[1] != runner {
+		t.Fatalf("queued callbacks ran on %v, not %v", threads, runner)
+	}
+
+	var handled []*ffi.CallbackPanic
+	ffi.SetPanicHandler(func(p *ffi.CallbackPanic) { handled = append(handled, p) })
+	defer ffi.SetPanicHandler(nil)
+	libc.onForeignThread(t, ffi.Queued(queue, func(abi.UnsafePointer) abi.UnsafePointer {
+		panic("queued")
+	}))
+	if len(handled) != 1 || handled[0].Value != "queued" {
+		t.Fatal("unexpected panic handling", handled)
+	}
+}
+
+type timeval struct {
+	Sec, Usec abi.Long
+}
+
+func TestOutParams(t *testing.T) {
+	var libc struct {
+		std.LibC
+
+		Strtol func(s string, base abi.Int) (abi.Long, string)      `ffi:"strtol,out=1"`
+		Now    func(tz abi.UnsafePointer) (abi.Int, timeval, error) `ffi:"gettimeofday,out=0,err=-1"`
+	}
+	var libm struct {
+		std.LibM
+
+		Sincos func(x abi.Double) (sin, cos abi.Double) `ffi:"sincos,out=1,2"`
+	}
+	if err := ffi.Link(&libc, &libm); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+	defer ffi.Unlink(&libm)
+
+	if n, rest := libc.Strtol("42abc", 10); n != 42 || rest != "abc" {
+		t.Fatalf("strtol: %v %q", n, rest)
+	}
+	if _, now, err := libc.Now(nil); err != nil || now.Sec == 0 {
+		t.Fatal("gettimeofday:", now, err)
+	}
+	if sin, cos := libm.Sincos(0); sin != 0 || cos != 1 {
+		t.Fatal("sincos:", sin, cos)
+	}
+
+	divide := ffi.NewFunc(func(a, b abi.Int) (abi.Int, abi.Int, string) { return a / b, a % b, "ok" })
+	if q, r, s := ffi.Func(divide)(7, 2); q != 3 || r != 1 || s != "ok" {
+		t.Fatal("out-params of a Go function pointer:", q, r, s)
+	}
+
+	host := struct {
+		ffi.Library
+
+		Divide func(a, b abi.Int) (q, r abi.Int) `ffi:"divide,out=0"`
+	}{
+		Divide: func(a, b abi.Int) (abi.Int, abi.Int) { return a / b, a % b },
+	}
+	exports, err := ffi.Export(&host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exports.Free()
+	var exported abi.Func[func(r *abi.Int, a, b abi.Int) abi.Int]
+	exported.SetPointer(*(*unsafe.Pointer)(exports.Pointer()))
+	var r abi.Int
+	if q := ffi.Func(exported)(&r, 7, 2); q != 3 || r != 1 {
+		t.Fatal("out-params of an exported func:", q, r)
+	}
+	var header strings.Builder
+	if err := ffi.ExportHeader(&header, "host", &host); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(header.String(), "\tint (*divide)(int *, int, int);\n") {
+		t.Fatalf("unexpected header:\n%s", header.String())
+	}
+
+	var invalid struct {
+		ffi.Library
+
+		Abs func(abi.Int) (abi.Int, abi.Int, abi.Int) `ffi:"abs,out=0"`
+	}
+	if err := ffi.ExportHeader(&header, "invalid", &invalid); err == nil {
+		t.Fatal("declared a func with two C return values")
+	}
+}
+
+func TestContext(t *testing.T) {
+	var libc struct {
+		std.LibC
+
+		Sleep  func(ctx context.Context, usec abi.IntUnsigned) (abi.Int, error) `ffi:"usleep,err=-1"`
+		Gettid func(ctx context.Context) (abi.Int, error)                       `ffi:"gettid,err=-1"`
+	}
+	if err := ffi.Link(&libc); err != nil {
+		t.Fatal(err)
+	}
+	defer ffi.Unlink(&libc)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if _, err := libc.Sleep(context.Background(), 1000); err != nil {
+		t.Fatal(err)
+	}
+	if tid, err := libc.Gettid(context.Background()); err != nil || int(tid) != syscall.Gettid() {
+		t.Fatal("uncancellable call on another thread:", tid, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first, err := libc.Gettid(ctx)
+	if err != nil || int(first) == syscall.Gettid() {
+		t.Fatal("cancellable call on the calling thread:", first, err)
+	}
+	if again, _ := libc.Gettid(ctx); again != first {
+		t.Fatal("helper thread was not recycled:", first, again)
+	}
+
+	timeout, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	start := time.Now()
+	if _, err := libc.Sleep(timeout, 2_000_000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("usleep past its deadline:", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatal("waited for an abandoned call:", elapsed)
+	}
+	if tid, err := libc.Gettid(ctx); err != nil || tid == first {
+		t.Fatal("reused a helper thread that is blocked in C:", tid, err)
+	}
+
+	cancel()
+	if _, err := libc.Sleep(ctx, 2_000_000); !errors.Is(err, context.Canceled) {
+		t.Fatal("usleep with a cancelled context:", err)
+	}
+}
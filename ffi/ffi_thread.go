@@ -0,0 +1,115 @@
+package ffi
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+)
+
+// mainThread is the OS thread that runs package initialisation,
+// the main goroutine only stays on it if [LockMainThread] is called.
+var mainThread thread
+
+func init() {
+	// package initialisation runs on the main thread.
+	mainThread = currentThread()
+}
+
+// LockMainThread locks the main goroutine to the main thread, so that
+// libraries tagged with `thread:"main"` can be called from the main
+// goroutine and from [Main]. It must be called from an init func, as
+// only package initialisation is sure to run on the main thread, by
+// the packages that declare such libraries, so that programs that do
+// not import them keep an unlocked main goroutine.
+func LockMainThread() {
+	if !onMainThread() {
+		panic("ffi.LockMainThread must be called from an init func")
+	}
+	runtime.LockOSThread()
+}
+
+var (
+	mainQueue = make(chan func())
+	serving   atomic.Pointer[chan struct{}] // closed once Main stops running mainQueue.
+)
+
+// Main runs fn on a new goroutine, whilst the main goroutine runs
+// the calls that other goroutines make to libraries tagged with
+// `thread:"main"`, it returns once fn returns. Main must be called
+// from the main goroutine, usually at the start of func main, after
+// [LockMainThread] has been called by an init func.
+func Main(fn func()) {
+	if !onMainThread() {
+		panic("ffi.Main must be called from the main goroutine, locked by ffi.LockMainThread")
+	}
+	stopped := make(chan struct{})
+	if !serving.CompareAndSwap(nil, &stopped) {
+		panic("ffi.Main is already running")
+	}
+	defer func() {
+		serving.Store(nil)
+		close(stopped)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	for {
+		select {
+		case call := <-mainQueue:
+			call()
+		case <-done:
+			return
+		}
+	}
+}
+
+// onMainThread reports whether the calling goroutine is running
+// on the main thread, which is only true for the main goroutine
+// and for callbacks made by C on the main thread.
+func onMainThread() bool {
+	return sameThread(currentThread(), mainThread)
+}
+
+// threadTag returns the thread affinity from the struct tag of a
+// [Library] field.
+func threadTag(header reflect.StructField) string {
+	return header.Tag.Get("thread")
+}
+
+// onMain returns a func that calls fn on the main thread, calls
+// made from other goroutines are run by [Main] and panic if it is
+// not running, as they would otherwise never return.
+func onMain(symbol string, fn reflect.Value) reflect.Value {
+	return reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+		if onMainThread() {
+			return fn.Call(args)
+		}
+		deadlock := "ffi: " + symbol + " must be called on the main thread, call it from the main goroutine or within ffi.Main"
+		stopped := serving.Load()
+		if stopped == nil {
+			panic(deadlock)
+		}
+		var (
+			results []reflect.Value
+			failure any
+			done    = make(chan struct{})
+		)
+		select {
+		case mainQueue <- func() {
+			defer close(done)
+			defer func() { failure = recover() }()
+			results = fn.Call(args)
+		}:
+		case <-*stopped:
+			panic(deadlock)
+		}
+		<-done
+		if failure != nil {
+			panic(failure)
+		}
+		return results
+	})
+}
@@ -0,0 +1,228 @@
+package ffi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Tracer observes the calls made to the C functions of a library,
+// see [SetTracer] and [Trace]. Libraries that are not traced are
+// called without any tracing overhead.
+type Tracer interface {
+	// TraceCall is called before each call with its arguments, the
+	// func it returns, if not nil, is called after the call returns,
+	// once the Results, Errno and Duration of the call are set.
+	TraceCall(call *Call) func()
+}
+
+// Call is a traced call to a C function.
+type Call struct {
+	Library Library
+	File    string // of the shared library.
+	Field   string // name of the func field.
+	Symbol  string
+
+	Args    []any // Go values passed to the func.
+	Results []any // Go values returned by the func.
+	Errno   syscall.Errno
+
+	Start     time.Time
+	Duration  time.Duration
+	Goroutine uint64 // ID of the calling goroutine.
+
+	end func()
+}
+
+var tracers struct {
+	global  Tracer
+	library map[Library]Tracer
+} // guarded by mutex.
+
+// SetTracer installs t as the tracer of every library that does not
+// have its own tracer, see [Trace]. A nil t removes the tracer. Linked
+// libraries are rebound, so SetTracer must not be called whilst any of
+// their funcs are being called.
+func SetTracer(t Tracer) {
+	mutex.Lock()
+	tracers.global = t
+	mutex.Unlock()
+	retrace(nil)
+}
+
+// Trace installs t as the tracer of the given library, in place of any
+// tracer installed by [SetTracer]. A nil t removes it. If the library is
+// linked, its funcs are rebound, so Trace must not be called whilst any
+// of them are being called.
+func Trace(library Library, t Tracer) {
+	mutex.Lock()
+	if t == nil {
+		delete(tracers.library, library)
+	} else {
+		if tracers.library == nil {
+			tracers.library = make(map[Library]Tracer)
+		}
+		tracers.library[library] = t
+	}
+	mutex.Unlock()
+	retrace(library)
+}
+
+// tracerOf returns the tracer of the library, if any.
+func tracerOf(library Library) Tracer {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if t, ok := tracers.library[library]; ok {
+		return t
+	}
+	return tracers.global
+}
+
+// retrace rebinds the funcs of the given linked library, or of
+// every linked library if it is nil, to their current tracer.
+func retrace(library Library) {
+	mutex.Lock()
+	linked := make(map[Library]*record)
+	funcs := make(map[Library][]reflect.StructField)
+	for lib, rec := range records {
+		if library == nil || lib == library {
+			linked[lib] = rec
+			rtype := reflect.TypeOf(lib).Elem()
+			for i := 0; i < rtype.NumField(); i++ {
+				if _, ok := rec.funcs[rtype.Field(i).Name]; ok {
+					funcs[lib] = append(funcs[lib], rtype.Field(i))
+				}
+			}
+		}
+	}
+	mutex.Unlock()
+	for lib, rec := range linked {
+		tracer := tracerOf(lib)
+		for _, field := range funcs[lib] {
+			rec.bind(lib, field, tracer)
+		}
+	}
+}
+
+// site of a traced call.
+type site struct {
+	tracer  Tracer
+	library Library
+	file    string
+	field   string
+}
+
+func (s *site) begin(symbol string, args []reflect.Value) *Call {
+	call := &Call{
+		Library:   s.library,
+		File:      s.file,
+		Field:     s.field,
+		Symbol:    symbol,
+		Args:      make([]any, len(args)),
+		Goroutine: goroutineID(),
+	}
+	for i, arg := range args {
+		call.Args[i] = arg.Interface()
+	}
+	call.end = s.tracer.TraceCall(call)
+	call.Start = time.Now()
+	return call
+}
+
+func (s *site) end(call *Call, results []reflect.Value, errno syscall.Errno) {
+	call.Duration = time.Since(call.Start)
+	call.Errno = errno
+	call.Results = make([]any, len(results))
+	for i, result := range results {
+		call.Results[i] = result.Interface()
+	}
+	if call.end != nil {
+		call.end()
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, as
+// reported in the header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header, _ = bytes.CutPrefix(header, []byte("goroutine "))
+	header, _, _ = bytes.Cut(header, []byte(" "))
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
+
+// LogTracer returns a [Tracer] that writes a line of text to w
+// for each call, once it returns, such as:
+//
+//	ffi: libc.so.6 strlen(hello) = 5 [1.2µs goroutine 1]
+//
+// The tracer is safe for concurrent use.
+func LogTracer(w io.Writer) Tracer {
+	return &logTracer{w: w}
+}
+
+type logTracer struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+func (l *logTracer) TraceCall(call *Call) func() {
+	return func() {
+		var b strings.Builder
+		fmt.Fprintf(&b, "ffi: %s %s(", call.File, call.Symbol)
+		for i, arg := range call.Args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprint(&b, arg)
+		}
+		b.WriteString(")")
+		for i, result := range call.Results {
+			if i == 0 {
+				b.WriteString(" = ")
+			} else {
+				b.WriteString(", ")
+			}
+			fmt.Fprint(&b, result)
+		}
+		if call.Errno != 0 {
+			fmt.Fprintf(&b, " errno=%d (%v)", int(call.Errno), call.Errno)
+		}
+		fmt.Fprintf(&b, " [%v goroutine %d]\n", call.Duration, call.Goroutine)
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		io.WriteString(l.w, b.String())
+	}
+}
+
+// RegionTracer returns a [Tracer] that marks each call as a region,
+// named after its symbol, in the execution trace of [runtime/trace],
+// so that the time spent in C shows up in 'go tool trace'.
+func RegionTracer() Tracer {
+	return regionTracer{}
+}
+
+type regionTracer struct{}
+
+func (regionTracer) TraceCall(call *Call) func() {
+	if !trace.IsEnabled() {
+		return nil
+	}
+	region := trace.StartRegion(context.Background(), call.Symbol)
+	return func() {
+		if call.Errno != 0 {
+			trace.Log(context.Background(), call.Symbol, call.Errno.Error())
+		}
+		region.End()
+	}
+}
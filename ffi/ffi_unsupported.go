@@ -0,0 +1,36 @@
+//go:build !cgo && !(linux && amd64)
+
+package ffi
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// Without cgo, libraries can only be loaded on linux/amd64, see
+// ffi_nocgo.go, on other targets [Link] returns a [*LoadError].
+
+func dlopen(filename string, lazy bool) (handle unsafe.Pointer) {
+	return nil
+}
+
+func dlerror() string {
+	return "ffi: loading libraries without cgo is not supported on " + runtime.GOOS + "/" + runtime.GOARCH
+}
+
+func dlsym(handle unsafe.Pointer, symbol string) unsafe.Pointer {
+	return nil
+}
+
+func dlclose(handle unsafe.Pointer) {}
+
+// thread identifies an OS thread.
+type thread = int
+
+func currentThread() thread {
+	return 0
+}
+
+func sameThread(a, b thread) bool {
+	return a == b
+}
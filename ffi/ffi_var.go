@@ -0,0 +1,90 @@
+package ffi
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Var is a C global variable of type T. As a field of a [Library],
+// it is bound to the data symbol named by its `ffi` tag, such that
+// Load and Store read and write through to the variable in C memory.
+// For example, the C declaration `extern FILE *stdout;` is bound by:
+//
+//	Stdout ffi.Var[*abi.File] `ffi:"stdout"`
+//
+// Load and Store panic if the variable is not linked. Fields of type
+// *T or [abi.Pointer] are also bound to data symbols, as a pointer to
+// the variable.
+type Var[T any] struct {
+	ptr  *T
+	name string
+}
+
+// Load returns the current value of the variable.
+func (v Var[T]) Load() T {
+	return *v.addr()
+}
+
+// Store sets the variable to value.
+func (v Var[T]) Store(value T) {
+	*v.addr() = value
+}
+
+// Addr returns a pointer to the variable in C memory, or
+// nil if it is not linked.
+func (v Var[T]) Addr() *T { return v.ptr }
+
+func (v Var[T]) addr() *T {
+	if v.ptr == nil {
+		name := v.name
+		if name == "" {
+			name = "variable"
+		}
+		panic("ffi: " + name + " is not linked")
+	}
+	return v.ptr
+}
+
+func (v *Var[T]) bind(symbol unsafe.Pointer, name string) {
+	v.ptr, v.name = (*T)(symbol), name
+}
+
+// variable is implemented by *Var.
+type variable interface {
+	bind(symbol unsafe.Pointer, name string)
+}
+
+// settable is implemented by pointers to [abi.Pointer] and [abi.Func].
+type settable interface {
+	SetPointer(unsafe.Pointer)
+}
+
+// isVariable reports whether fields of type t are bound to data symbols.
+func isVariable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return true
+	case reflect.Struct:
+		ptr := reflect.PointerTo(t)
+		return ptr.Implements(reflect.TypeOf((*variable)(nil)).Elem()) ||
+			ptr.Implements(reflect.TypeOf((*settable)(nil)).Elem())
+	}
+	return false
+}
+
+// bindVariable points the variable field value at symbol, which is
+// nil when the field is not linked.
+func bindVariable(value reflect.Value, symbol unsafe.Pointer, name string) {
+	switch v := value.Addr().Interface().(type) {
+	case variable:
+		v.bind(symbol, name)
+	case settable:
+		v.SetPointer(symbol)
+	default:
+		if symbol == nil {
+			value.Set(reflect.Zero(value.Type()))
+			return
+		}
+		value.Set(reflect.NewAt(value.Type().Elem(), symbol))
+	}
+}
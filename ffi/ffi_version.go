@@ -0,0 +1,108 @@
+package ffi
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrUnavailable is returned (or panicked with, for funcs that do not
+// return an error) by a func field tagged with a since version that is
+// newer than the version of the linked library, see [Set].
+var ErrUnavailable = errors.New("ffi: symbol unavailable in this version of the library")
+
+// Versioned is implemented by libraries with func fields that are
+// tagged with a since version, usually by a method of the type that
+// embeds [Library], so that it is shared by every library struct of
+// the same shared library.
+type Versioned interface {
+	Library
+
+	// LibraryVersion returns the dotted version of the linked
+	// library, such as "2.26.5", or "" if it is unknown, in which
+	// case every version-gated func is unavailable. It is called
+	// once, on the first call to a version-gated func, so it may
+	// call the funcs of other linked libraries. If it panics, the
+	// panic is passed on to that call, and the next call asks again.
+	LibraryVersion() string
+}
+
+// Feature is a version-gated func field of a library.
+type Feature struct {
+	Field     string
+	Since     string // version of the library that added the symbol.
+	Available bool   // the symbol is bound.
+}
+
+// Features reports the version-gated func fields of the given library,
+// in field order, and whether each is available in the linked version
+// of the library. The version is queried, if it has not been already.
+func Features(library Library) []Feature {
+	mutex.Lock()
+	rec, ok := records[library]
+	mutex.Unlock()
+
+	var features []Feature
+	rtype := reflect.TypeOf(library).Elem()
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		since := field.Tag.Get("since")
+		if since == "" || field.Type.Kind() != reflect.Func {
+			continue
+		}
+		var available bool
+		if ok {
+			if p, ok := rec.pending[field.Name]; ok {
+				rec.resolve(library, p)
+			}
+			mutex.Lock()
+			available = rec.bound[field.Name]
+			mutex.Unlock()
+		}
+		features = append(features, Feature{Field: field.Name, Since: since, Available: available})
+	}
+	return features
+}
+
+// supports reports whether the linked version of the library is
+// at least since. The version is only remembered once LibraryVersion
+// returns, if it panics, it is queried again by the next call.
+func (rec *record) supports(library Library, since string) bool {
+	rec.versioning.Lock()
+	defer rec.versioning.Unlock()
+	if !rec.versioned {
+		rec.version = library.(Versioned).LibraryVersion()
+		rec.versioned = true
+	}
+	return rec.version != "" && compareVersions(rec.version, since) >= 0
+}
+
+// compareVersions compares the dotted versions a and b, numerically
+// by component, where missing components are zero and any suffix of
+// a component, such as "-rc1", is ignored.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := versionComponent(as, i), versionComponent(bs, i)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionComponent(components []string, i int) int {
+	if i >= len(components) {
+		return 0
+	}
+	digits := components[i]
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+	n, _ := strconv.Atoi(digits)
+	return n
+}
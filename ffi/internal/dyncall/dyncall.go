@@ -5,40 +5,12 @@ package dyncall
 #include <dyncall_callback.h>
 */
 import "C"
-import (
-	"unsafe"
-)
-
-const (
-	Void             = C.DC_SIGCHAR_VOID
-	Bool             = C.DC_SIGCHAR_BOOL
-	Char             = C.DC_SIGCHAR_CHAR
-	UnsignedChar     = C.DC_SIGCHAR_UCHAR
-	Short            = C.DC_SIGCHAR_SHORT
-	UnsignedShort    = C.DC_SIGCHAR_USHORT
-	Int              = C.DC_SIGCHAR_INT
-	Uint             = C.DC_SIGCHAR_UINT
-	Long             = C.DC_SIGCHAR_LONG
-	UnsignedLong     = C.DC_SIGCHAR_ULONG
-	LongLong         = C.DC_SIGCHAR_LONGLONG
-	UnsignedLongLong = C.DC_SIGCHAR_ULONGLONG
-	Float            = C.DC_SIGCHAR_FLOAT
-	Double           = C.DC_SIGCHAR_DOUBLE
-	Pointer          = C.DC_SIGCHAR_POINTER
-	String           = C.DC_SIGCHAR_STRING
-	Aggregate        = C.DC_SIGCHAR_AGGREGATE
-)
-
-type Signature struct {
-	Args    []rune
-	Returns rune
-}
-
-var functions []CallbackHandler
+import "unsafe"
 
 //export bridge_callback
 func bridge_callback(cb *C.DCCallback, args *C.DCArgs, result unsafe.Pointer, userdata uintptr) C.DCsigchar {
-	return C.DCsigchar(functions[userdata-1]((*Callback)(cb), (*Args)(args), result))
+	fn := lookup(userdata).fn
+	return C.DCsigchar(fn((*Callback)(cb), (*Args)(args), result))
 }
 
 type Args C.DCArgs
@@ -0,0 +1,55 @@
+package dyncall
+
+/*
+#include <dyncall.h>
+#include <dyncall_args.h>
+
+static void goAggrField(DCaggr *ag, DCsigchar type, DCint offset, DCsize length, DCaggr *sub) {
+	if (type == DC_SIGCHAR_AGGREGATE) {
+		dcAggrField(ag, type, offset, length, sub);
+	} else {
+		dcAggrField(ag, type, offset, length);
+	}
+}
+*/
+import "C"
+import "unsafe"
+
+// Aggr describes the layout of a C struct that is
+// passed or returned by value.
+type Aggr C.DCaggr
+
+// NewAggr returns a new aggregate of the given size in bytes,
+// with room for up to the given number of fields.
+func NewAggr(fields, size int) *Aggr {
+	return (*Aggr)(C.dcNewAggr(C.DCsize(fields), C.DCsize(size)))
+}
+
+// Field adds a field of the given signature kind to the aggregate. The
+// field is an array when length is greater than one. sub must be non-nil
+// if, and only if, kind is [Aggregate].
+func (ag *Aggr) Field(kind rune, offset, length int, sub *Aggr) {
+	C.goAggrField((*C.DCaggr)(ag), C.DCsigchar(kind), C.DCint(offset), C.DCsize(length), (*C.DCaggr)(sub))
+}
+
+// Close finishes the aggregate definition, it must be called
+// after all fields have been added and before it is used.
+func (ag *Aggr) Close() {
+	C.dcCloseAggr((*C.DCaggr)(ag))
+}
+
+// Free releases the aggregate.
+func (ag *Aggr) Free() {
+	C.dcFreeAggr((*C.DCaggr)(ag))
+}
+
+// Aggr copies the next aggregate argument into target.
+func (args *Args) Aggr(target unsafe.Pointer) unsafe.Pointer {
+	return unsafe.Pointer(C.dcbArgAggr((*C.DCArgs)(args), C.DCpointer(target)))
+}
+
+// ReturnAggr writes the aggregate pointed to by value into the
+// result of a callback.
+func (args *Args) ReturnAggr(result unsafe.Pointer, value unsafe.Pointer) {
+	C.dcbReturnAggr((*C.DCArgs)(args), (*C.DCValue)(result), C.DCpointer(value))
+}
@@ -0,0 +1,182 @@
+//go:build !cgo && linux && amd64
+
+package dyncall
+
+import "unsafe"
+
+// System V classes of an eightbyte.
+const (
+	classNone = iota
+	classInteger
+	classSSE
+)
+
+// Aggr describes the layout of a C struct that is
+// passed or returned by value.
+type Aggr struct {
+	size   int
+	fields []aggrField
+
+	// classified by Close, as in dyncall_aggregate.c
+	memory  bool   // passed on the stack.
+	classes [2]int // of each eightbyte.
+	ints    int    // number of integer registers needed.
+	sse     int    // number of vector registers needed.
+}
+
+type aggrField struct {
+	kind           rune
+	offset, length int
+	sub            *Aggr
+}
+
+// NewAggr returns a new aggregate of the given size in bytes,
+// with room for up to the given number of fields.
+func NewAggr(fields, size int) *Aggr {
+	return &Aggr{size: size, fields: make([]aggrField, 0, fields)}
+}
+
+// Field adds a field of the given signature kind to the aggregate. The
+// field is an array when length is greater than one. sub must be non-nil
+// if, and only if, kind is [Aggregate].
+func (ag *Aggr) Field(kind rune, offset, length int, sub *Aggr) {
+	ag.fields = append(ag.fields, aggrField{kind, offset, length, sub})
+}
+
+// Close finishes the aggregate definition, it must be called
+// after all fields have been added and before it is used.
+func (ag *Aggr) Close() {
+	ag.memory = ag.size > 16 || ag.size == 0 || !ag.classify(ag, 0)
+	if ag.memory {
+		return
+	}
+	for _, class := range ag.classes[:ag.words()] {
+		switch class {
+		case classInteger:
+			ag.ints++
+		case classSSE:
+			ag.sse++
+		}
+	}
+}
+
+// classify the scalar fields of sub, at the given offset within ag,
+// reporting false if they are not aligned.
+func (ag *Aggr) classify(sub *Aggr, offset int) bool {
+	for _, field := range sub.fields {
+		size, class := 8, classInteger
+		switch field.kind {
+		case Aggregate:
+			size = field.sub.size
+		case Char, UnsignedChar:
+			size = 1
+		case Short, UnsignedShort:
+			size = 2
+		case Bool, Int, Uint:
+			size = 4
+		case Float:
+			size, class = 4, classSSE
+		case Double:
+			class = classSSE
+		}
+		for i := 0; i < field.length; i++ {
+			at := offset + field.offset + i*size
+			if field.kind == Aggregate {
+				if !ag.classify(field.sub, at) {
+					return false
+				}
+				continue
+			}
+			if at%size != 0 {
+				return false
+			}
+			if eightbyte := &ag.classes[at/8]; *eightbyte != classInteger {
+				*eightbyte = class
+			}
+		}
+	}
+	return true
+}
+
+// Free releases the aggregate.
+func (ag *Aggr) Free() {}
+
+// words returns the number of eightbytes passed in registers.
+func (ag *Aggr) words() int {
+	n := (ag.size + 7) / 8
+	for i := 0; i < n; i++ {
+		if ag.classes[i] == classNone {
+			return i
+		}
+	}
+	return n
+}
+
+// word returns the eightbyte at offset within the aggregate at ptr,
+// without reading past its end.
+func (ag *Aggr) word(ptr unsafe.Pointer, offset int) uint64 {
+	var word uint64
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&word)), 8), unsafe.Slice((*byte)(unsafe.Add(ptr, offset)), min(8, ag.size-offset)))
+	return word
+}
+
+// setWord sets the eightbyte at offset within the aggregate
+// at ptr, without writing past its end.
+func (ag *Aggr) setWord(ptr unsafe.Pointer, offset int, word uint64) {
+	copy(unsafe.Slice((*byte)(unsafe.Add(ptr, offset)), min(8, ag.size-offset)), unsafe.Slice((*byte)(unsafe.Pointer(&word)), 8))
+}
+
+// unpack the aggregate returned in registers into result.
+func (ag *Aggr) unpack(result unsafe.Pointer, regs *[4]uint64) {
+	ints, floats := regs[0:2], regs[2:4]
+	for i, class := range ag.classes[:ag.words()] {
+		if class == classSSE {
+			ag.setWord(result, i*8, floats[0])
+			floats = floats[1:]
+		} else {
+			ag.setWord(result, i*8, ints[0])
+			ints = ints[1:]
+		}
+	}
+}
+
+// Aggr copies the next aggregate argument into target.
+func (args *Args) Aggr(target unsafe.Pointer) unsafe.Pointer {
+	ag := args.aggrs[0]
+	args.aggrs = args.aggrs[1:]
+	if ag.memory || args.ints+ag.ints > len(args.frame.ints) || args.floats+ag.sse > len(args.frame.floats) {
+		for i := 0; i < ag.size; i += 8 {
+			ag.setWord(target, i, args.word())
+		}
+		return target
+	}
+	for i, class := range ag.classes[:ag.words()] {
+		if class == classSSE {
+			ag.setWord(target, i*8, args.float())
+		} else {
+			ag.setWord(target, i*8, args.next())
+		}
+	}
+	return target
+}
+
+// ReturnAggr writes the aggregate pointed to by value into the
+// result of a callback.
+func (args *Args) ReturnAggr(result unsafe.Pointer, value unsafe.Pointer) {
+	ag := args.ret
+	if ag.memory {
+		copy(unsafe.Slice((*byte)(args.hidden), ag.size), unsafe.Slice((*byte)(value), ag.size))
+		*(*unsafe.Pointer)(result) = args.hidden
+		return
+	}
+	var regs [4]uint64
+	ints, floats := regs[0:2], regs[2:4]
+	for i, class := range ag.classes[:ag.words()] {
+		if class == classSSE {
+			floats[0], floats = ag.word(value, i*8), floats[1:]
+		} else {
+			ints[0], ints = ag.word(value, i*8), ints[1:]
+		}
+	}
+	*(*[4]uint64)(result) = regs
+}
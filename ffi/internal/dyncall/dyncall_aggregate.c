@@ -234,6 +234,59 @@ void dcFinishAggr(DCaggr *ag)
 #endif
 
 
+#elif defined(DC__Arch_ARM64)
+
+/* returns the number of floating point members of the aggr, setting type
+ * to their type, or -1 if the aggr isn't homogeneous (or is too large) */
+static int dc_count_hfa_members(const DCaggr *ag, DCsigchar *type)
+{
+  int i, n = 0;
+
+  for(i=0; i<ag->n_fields; ++i) {
+    const DCfield *f = ag->fields + i;
+    int m;
+
+    switch (f->type) {
+      case DC_SIGCHAR_FLOAT:
+      case DC_SIGCHAR_DOUBLE:
+        if(*type && *type != f->type)
+          return -1;
+        *type = f->type;
+        m = 1;
+        break;
+      case DC_SIGCHAR_AGGREGATE:
+        m = dc_count_hfa_members(f->sub_aggr, type);
+        if(m < 0)
+          return -1;
+        break;
+      default:
+        return -1;
+    }
+
+    n += m * f->array_len;
+    if(n > DC_ARM64_MAX_HFA_MEMBERS)
+      return -1;
+  }
+
+  return n;
+}
+
+
+static void dcFinishAggr(DCaggr *ag)
+{
+  DCsigchar type = 0;
+  int n = dc_count_hfa_members(ag, &type);
+
+  ag->hfa_type  = 0;
+  ag->hfa_count = 0;
+
+  /* members must cover the whole aggr, without padding */
+  if(n > 0 && ag->size == n * (type == DC_SIGCHAR_FLOAT ? sizeof(DCfloat) : sizeof(DCdouble))) {
+    ag->hfa_type  = type;
+    ag->hfa_count = n;
+  }
+}
+
 #else
 static void dcFinishAggr(DCaggr *ag)
 {
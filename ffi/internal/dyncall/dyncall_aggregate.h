@@ -47,6 +47,13 @@ extern "C" {
 
 #endif
 
+#if defined(DC__Arch_ARM64)
+
+/* arm64 homogeneous floating-point aggregates (HFA), of 1 to 4 members of the same floating point type, are passed in fp regs */
+#  define DC_ARM64_MAX_HFA_MEMBERS 4
+
+#endif
+
 
 typedef struct DCfield_ {
 	DCsize offset, size, alignment, array_len;
@@ -58,6 +65,10 @@ struct DCaggr_ {
 	DCsize size, n_fields, alignment;
 #if defined(DC_UNIX) && defined(DC__Arch_AMD64)
 	DCuchar sysv_classes[DC_SYSV_MAX_NUM_CLASSES]; /* !code relies on this to be 64 bits! */
+#endif
+#if defined(DC__Arch_ARM64)
+	DCsigchar hfa_type;  /* DC_SIGCHAR_FLOAT or DC_SIGCHAR_DOUBLE if the aggr is an HFA, else 0 */
+	DCuchar   hfa_count; /* number of HFA members */
 #endif
 	DCfield fields[];
 };
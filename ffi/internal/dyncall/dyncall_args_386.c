@@ -0,0 +1,100 @@
+/*
+
+ Package: dyncall
+ Library: dyncallback
+ File: dyncallback/dyncall_args_x86.c
+ Description: Callback's Arguments VM - Implementation for x86
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+#include "dyncall_args_386.h"
+
+#include <string.h>
+
+
+/* takes the hidden ptr to the aggr ret value, if any, off the stack */
+static void take_aggr_return(DCArgs* args)
+{
+  if (args->aggr_return_register == 0) {
+    args->aggr_return = *(DCpointer*)args->stack_ptr++;
+    args->aggr_return_register = -1;
+  }
+}
+
+
+static void* arg(DCArgs* args, size_t size)
+{
+  void* p;
+  take_aggr_return(args);
+  p = args->stack_ptr;
+  args->stack_ptr += (size + (sizeof(int)-1)) / sizeof(int); /* advance to next full stack slot */
+  return p;
+}
+
+
+
+DCint       dcbArgInt      (DCArgs* p) { return *(DCint*)arg(p, sizeof(DCint)); }
+DClong      dcbArgLong     (DCArgs* p) { return (long)  dcbArgInt(p); }
+DCchar      dcbArgChar     (DCArgs* p) { return (char)  dcbArgInt(p); }
+DCshort     dcbArgShort    (DCArgs* p) { return (short) dcbArgInt(p); }
+DCbool      dcbArgBool     (DCArgs* p) { return dcbArgInt(p) != 0; }
+DClonglong  dcbArgLongLong (DCArgs* p) { return *(DClonglong*)arg(p, sizeof(DClonglong)); }
+
+DCuint      dcbArgUInt     (DCArgs* p) { return (DCuint)      dcbArgInt(p);      }
+DCuchar     dcbArgUChar    (DCArgs* p) { return (DCuchar)     dcbArgChar(p);     }
+DCushort    dcbArgUShort   (DCArgs* p) { return (DCushort)    dcbArgShort(p);    }
+DCulong     dcbArgULong    (DCArgs* p) { return (DCulong)     dcbArgLong(p);     }
+DCulonglong dcbArgULongLong(DCArgs* p) { return (DCulonglong) dcbArgLongLong(p); }
+
+
+DCpointer   dcbArgPointer  (DCArgs* p) { return *(DCpointer*)arg(p, sizeof(DCpointer)); }
+
+DCdouble    dcbArgDouble   (DCArgs* p) { return *(DCdouble*)arg(p, sizeof(DCdouble)); }
+DCfloat     dcbArgFloat    (DCArgs* p) { return *(DCfloat*) arg(p, sizeof(DCfloat));  }
+
+DCpointer   dcbArgAggr     (DCArgs* p, DCpointer target)
+{
+  DCaggr *ag = *(p->aggrs++);
+
+  if(!ag) {
+    /* non-trivial aggr: retrieve as ptr, user is supposed to make copy */
+    return dcbArgPointer(p);
+  }
+
+  /* aggrs are passed by value on the stack */
+  return memcpy(target, arg(p, ag->size), ag->size);
+}
+
+
+void dcbReturnAggr(DCArgs *args, DCValue *result, DCpointer ret)
+{
+  DCaggr *ag = *(args->aggrs++);
+
+  /* aggrs are always returned via the hidden ptr, which is also returned in eax */
+  take_aggr_return(args);
+  if(ag)
+    memcpy(args->aggr_return, ret, ag->size);
+  else {
+    /* non-trivial aggr: all we can do is to provide the ptr to the output space, user has to make copy */
+  }
+  result->p = args->aggr_return;
+}
+
@@ -0,0 +1,45 @@
+/*
+
+ Package: dyncall
+ Library: dyncallback
+ File: dyncallback/dyncall_args_x86.h
+ Description: Callback's Arguments VM - Header for x86
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+#ifndef DYNCALLBACK_ARGS_X86_H
+#define DYNCALLBACK_ARGS_X86_H
+
+#include "dyncall_args.h"
+#include "dyncall_aggregate.h"
+
+
+struct DCArgs
+{
+  /* state */
+  int*            stack_ptr;              /* offset 0 */
+  DCaggr**        aggrs;                  /* offset 4 */
+  int             aggr_return_register;   /* offset 8, 0 if the first stack slot is a hidden ptr to the aggr ret value */
+  DCpointer       aggr_return;            /* offset 12, the hidden ptr, once taken off the stack */
+};
+
+#endif /* DYNCALLBACK_ARGS_X86_H */
+
@@ -2,8 +2,8 @@
 
  Package: dyncall
  Library: dyncallback
- File: dyncallback/dyncall_args_arm64_apple.c
- Description: Callback's Arguments VM - Implementation for Apple's ARM64 / ARMv8 / AAPCS64
+ File: dyncallback/dyncall_args_arm64.c
+ Description: Callback's Arguments VM - Implementation for ARM64 / ARMv8 / AAPCS64
  License:
 
    Copyright (c) 2015-2022 Daniel Adler <dadler@uni-goettingen.de>,
@@ -24,8 +24,10 @@
 */
 
 #include "dyncall_args.h"
+#include "dyncall_aggregate.h"
 
 #include <stdint.h>
+#include <string.h>
 
 typedef union {
   struct { double value; } d;
@@ -36,15 +38,18 @@ struct DCArgs
 {
   /* buffers and stack-pointer: */
 
-  uint64_t  I[8];
-  DCFPU_t   F[8];
-  uint8_t*  sp;
+  uint64_t  I[8];          /* offset 0   */
+  DCFPU_t   F[8];          /* offset 64  */
+  uint8_t*  sp;            /* offset 128 */
   
   /* counters: */
-  int i;
-  int f;
-  int s;
-  int reserved;
+  int i;                   /* offset 136 */
+  int f;                   /* offset 140 */
+  int s;                   /* offset 144 */
+  int reserved;            /* offset 148 */
+
+  DCpointer x8;            /* offset 152: indirect result location */
+  DCaggr**  aggrs;         /* offset 160 */
 };
 
 static inline uint8_t* align(uint8_t* p, size_t v)
@@ -53,38 +58,45 @@ static inline uint8_t* align(uint8_t* p, size_t v)
 }
 
 
+/* returns the next arg of the given size on the stack, which are in 8 byte
+   slots, except on Apple, which packs them with their natural alignment */
+static uint8_t* arg_stack(DCArgs* p, size_t size, size_t alignment)
+{
+  uint8_t* value;
+#if !defined(DC__OS_Darwin)
+  if (alignment < 8)
+    alignment = 8;
+#endif
+  p->sp = align(p->sp, alignment);
+  value = p->sp;
+  p->sp += size;
+#if !defined(DC__OS_Darwin)
+  p->sp = align(p->sp, 8);
+#endif
+  return value;
+}
+
+
 DClonglong dcbArgLongLong (DCArgs* p) 
 {
   if (p->i < 8) {
     return p->I[p->i++];
   } else {
-    DClonglong value;
-    p->sp = align(p->sp,sizeof(DClonglong));
-    value =  * ( (DClonglong*) p->sp );
-    p->sp += sizeof(DClonglong);
-    return value;
+    return * ( (DClonglong*) arg_stack(p, sizeof(DClonglong), sizeof(DClonglong)) );
   }
 }
 DCdouble  dcbArgDouble (DCArgs* p) {
   if (p->f < 8) { 
     return p->F[p->f++].d.value;
   } else {
-    DCdouble value;
-    p->sp = align(p->sp,sizeof(DCdouble));
-    value =  * ( (DCdouble*) p->sp );
-    p->sp += sizeof(DCdouble);
-    return value;
+    return * ( (DCdouble*) arg_stack(p, sizeof(DCdouble), sizeof(DCdouble)) );
   }
 }
 DCfloat   dcbArgFloat  (DCArgs* p) {
   if (p->f < 8) {
     return p->F[p->f++].f.value;
   } else {
-    DCfloat value;
-    p->sp = align(p->sp,sizeof(DCfloat));
-    value =  * ( (DCfloat*) p->sp );
-    p->sp += sizeof(DCfloat);
-    return value;
+    return * ( (DCfloat*) arg_stack(p, sizeof(DCfloat), sizeof(DCfloat)) );
   }
 }
 
@@ -92,11 +104,7 @@ DClong    dcbArgLong   (DCArgs* p) {
   if (p->i < 8) {
     return (DClong) p->I[p->i++];
   } else {
-    DClong value;
-    p->sp = align(p->sp,sizeof(DClong));
-    value =  * ( (DClong*) p->sp );
-    p->sp += sizeof(DClong);
-    return value;
+    return * ( (DClong*) arg_stack(p, sizeof(DClong), sizeof(DClong)) );
   }
 }
 
@@ -104,11 +112,7 @@ DCint     dcbArgInt    (DCArgs* p) {
   if (p->i < 8) {
     return (DCint) p->I[p->i++];
   } else {
-    DCint value;
-    p->sp = align(p->sp,sizeof(DCint));
-    value =  * ( (DCint*) p->sp );
-    p->sp += sizeof(DCint);
-    return value;
+    return * ( (DCint*) arg_stack(p, sizeof(DCint), sizeof(DCint)) );
   }
 }
 
@@ -116,11 +120,7 @@ DCshort   dcbArgShort  (DCArgs* p) {
   if (p->i < 8) {
     return (DCshort) p->I[p->i++];
   } else {
-    DCshort value;
-    p->sp = align(p->sp,sizeof(DCshort));
-    value =  * ( (DCshort*) p->sp );
-    p->sp += sizeof(DCshort);
-    return value;
+    return * ( (DCshort*) arg_stack(p, sizeof(DCshort), sizeof(DCshort)) );
   }
 }
 
@@ -128,11 +128,7 @@ DCchar    dcbArgChar   (DCArgs* p) {
   if (p->i < 8) {
     return (DCchar) p->I[p->i++];
   } else {
-    DCchar value;
-    p->sp = align(p->sp,sizeof(DCchar));
-    value =  * ( (DCchar*) p->sp );
-    p->sp += sizeof(DCchar);
-    return value;
+    return * ( (DCchar*) arg_stack(p, sizeof(DCchar), sizeof(DCchar)) );
   }
 }
 
@@ -140,11 +136,7 @@ DCbool    dcbArgBool   (DCArgs* p) {
   if (p->i < 8) {
     return (DCbool) p->I[p->i++];
   } else {
-    DCbool value;
-    p->sp = align(p->sp,sizeof(DCbool));
-    value =  * ( (DCbool*) p->sp );
-    p->sp += sizeof(DCbool);
-    return value;
+    return * ( (DCbool*) arg_stack(p, sizeof(DCbool), sizeof(DCbool)) );
   }
 }
 
@@ -158,6 +150,73 @@ DCushort    dcbArgUShort   (DCArgs* p) { return (DCushort)    dcbArgShort(p);
 DCulong     dcbArgULong    (DCArgs* p) { return (DCulong)     dcbArgLong(p);     }
 DCulonglong dcbArgULongLong(DCArgs* p) { return (DCulonglong) dcbArgLongLong(p); }
 
-DCpointer   dcbArgAggr     (DCArgs* p, DCpointer target)                   { /* @@@AGGR not impl */ return NULL; }
-void        dcbReturnAggr  (DCArgs *args, DCValue *result, DCpointer ret)  { /* @@@AGGR not impl */ }
+DCpointer   dcbArgAggr     (DCArgs* p, DCpointer target)
+{
+  int i, n;
+  DCaggr *ag = *(p->aggrs++);
+
+  if(!ag) {
+    /* non-trivial aggr: retrieve as ptr, user is supposed to make copy */
+    return dcbArgPointer(p);
+  }
+
+  if(ag->hfa_count) {
+    /* HFAs are passed in as many consecutive fp regs as members, or on the stack */
+    size_t size = ag->size / ag->hfa_count;
+    if(p->f + ag->hfa_count <= 8) {
+      for(i=0; i<ag->hfa_count; ++i)
+        memcpy((uint8_t*)target + i*size, &p->F[p->f++], size);
+      return target;
+    }
+    p->f = 8;
+    memcpy(target, arg_stack(p, ag->size, ag->alignment), ag->size);
+    p->sp = align(p->sp, 8);
+    return target;
+  }
+
+  if(ag->size > 16) {
+    /* passed via pointer to a copy made by the caller */
+    return memcpy(target, dcbArgPointer(p), ag->size);
+  }
+
+  /* small aggrs are passed in as many consecutive int regs as needed, or on the stack */
+  n = (ag->size + 7) / 8;
+  if(ag->alignment == 16)
+    p->i = (p->i + 1) & ~1; /* start at even reg */
+  if(p->i + n <= 8) {
+    memcpy(target, &p->I[p->i], ag->size);
+    p->i += n;
+    return target;
+  }
+  p->i = 8;
+  memcpy(target, arg_stack(p, ag->size, ag->alignment), ag->size);
+  p->sp = align(p->sp, 8);
+  return target;
+}
+
+void        dcbReturnAggr  (DCArgs *args, DCValue *result, DCpointer ret)
+{
+  int i;
+  DCaggr *ag = *(args->aggrs++);
+
+  if(!ag || (!ag->hfa_count && ag->size > 16)) {
+    /* returned via the memory pointed to by x8 */
+    if(ag)
+      memcpy(args->x8, ret, ag->size);
+    else {
+      /* non-trivial aggr: all we can do is to provide the ptr to the output space, user has to make copy */
+    }
+    result->p = args->x8;
+    return;
+  }
+
+  /* space for 4 dwords is pointed to by result, the callback thunk loads x0-x1 */
+  /* and d0-d3 from it, so lay out HFA members one per dword, in d0-d3         */
+  if(ag->hfa_count) {
+    size_t size = ag->size / ag->hfa_count;
+    for(i=0; i<ag->hfa_count; ++i)
+      memcpy((uint8_t*)result + i*8, (uint8_t*)ret + i*size, size);
+  } else
+    memcpy(result, ret, ag->size);
+}
 
@@ -0,0 +1,103 @@
+/*
+
+ Package: dyncall
+ Library: dyncallback
+ File: dyncallback/dyncall_callback_x86.c
+ Description: Callback - Implementation for x86
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+#include "dyncall_callback.h"
+#include "dyncall_alloc_wx.h"
+#include "dyncall_aggregate.h"
+#include "dyncall_thunk.h"
+
+
+/* Callback symbol. */
+extern void dcCallback_x86_cdecl();
+
+struct DCCallback
+{
+  DCThunk            thunk;                /* offset 0,  size 16 */
+  DCCallbackHandler* handler;              /* offset 16 */
+  void*              userdata;             /* offset 20 */
+  DCint              aggr_return_register; /* offset 24 */
+  DCaggr *const *    aggrs;                /* offset 28 */
+};
+
+
+void dcbInitCallback2(DCCallback* pcb, const DCsigchar* signature, DCCallbackHandler* handler, void* userdata, DCaggr *const * aggrs)
+{
+  const DCsigchar *ch = signature;
+  DCint num_aggrs = 0;
+
+  pcb->handler              = handler;
+  pcb->userdata             = userdata;
+  pcb->aggrs                = NULL;
+  pcb->aggr_return_register = -2; /* default, = no aggr as ret value */
+
+  if(*ch == DC_SIGCHAR_CC_PREFIX)
+    ch += 2;
+
+  while(*ch)
+    num_aggrs += (*(ch++) == DC_SIGCHAR_AGGREGATE);
+
+  if(num_aggrs)
+  {
+    pcb->aggrs = aggrs;
+
+    /* aggrs are always returned via a hidden pointer (first arg) */
+    if (ch != signature && *(ch - 1) == DC_SIGCHAR_AGGREGATE)
+      pcb->aggr_return_register = 0;
+  }
+}
+
+
+DCCallback* dcbNewCallback2(const DCsigchar* signature, DCCallbackHandler* handler, void* userdata, DCaggr *const * aggrs)
+{
+  int err;
+  DCCallback* pcb;
+  err = dcAllocWX(sizeof(DCCallback), (void**) &pcb);
+  if(err)
+    return NULL;
+
+  dcbInitCallback2(pcb, signature, handler, userdata, aggrs);
+  dcbInitThunk(&pcb->thunk, dcCallback_x86_cdecl);
+
+  err = dcInitExecWX(pcb, sizeof(DCCallback));
+  if(err) {
+    dcFreeWX(pcb, sizeof(DCCallback));
+    return NULL;
+  }
+
+  return pcb;
+}
+
+void dcbFreeCallback(DCCallback* pcb)
+{
+  dcFreeWX(pcb, sizeof(DCCallback));
+}
+
+void* dcbGetUserData(DCCallback* pcb)
+{
+  return pcb->userdata;
+}
+
@@ -26,6 +26,7 @@
 
 #include "dyncall_callback.h"
 #include "dyncall_alloc_wx.h"
+#include "dyncall_aggregate.h"
 #include "dyncall_thunk.h"
 
 
@@ -37,13 +38,24 @@ struct DCCallback               /*  off  size */
   DCThunk            thunk;     /*   0     32 */
   DCCallbackHandler* handler;   /*  32      8 */
   void*              userdata;  /*  40      8 */
-};                              /* total   48 */ 
-                                /* aligned 48 */ 
+  DCaggr *const *    aggrs;     /*  48      8 */
+};                              /* total   56 */ 
+                                /* aligned 56 */ 
 
 void dcbInitCallback2(DCCallback* pcb, const DCsigchar* signature, DCCallbackHandler* handler, void* userdata, DCaggr *const * aggrs)
 {
+  const DCsigchar *ch = signature;
+  DCint num_aggrs = 0;
+
   pcb->handler = handler;
   pcb->userdata = userdata;
+  pcb->aggrs = NULL;
+
+  while(*ch)
+    num_aggrs += (*(ch++) == DC_SIGCHAR_AGGREGATE);
+
+  if(num_aggrs)
+    pcb->aggrs = aggrs;
 }
 
 
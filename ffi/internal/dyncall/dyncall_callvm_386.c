@@ -0,0 +1,250 @@
+/*
+
+ Package: dyncall
+ Library: dyncall
+ File: dyncall/dyncall_callvm_x86.c
+ Description: Call VM for x86 architecture implementation
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+/* x86 cdecl calling convention, System V i386 ABI. */
+
+
+#include "dyncall_callvm_386.h"
+#include "dyncall_alloc.h"
+#include "dyncall_aggregate.h"
+
+#include <assert.h>
+
+
+/*
+** x86 cdecl calling convention
+**
+** - all arguments are passed on the stack, in 4 byte slots
+** - hybrid return-type call (bool ... pointer in eax, long long in eax:edx,
+**   float and double in st(0))
+** - aggregates are returned via a hidden pointer, passed as the first
+**   argument, which the callee pops off the stack
+**
+*/
+
+extern void dcCall_x86_cdecl(DCpointer target, DCpointer stackdata, DCsize size);
+
+
+
+
+static void dc_callvm_free_x86(DCCallVM* in_self)
+{
+  dcFreeMem(in_self);
+}
+
+
+static void dc_callvm_reset_x86(DCCallVM* in_self)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcVecReset(&self->mVecHead);
+  self->mAggrReturn = 0;
+}
+
+
+
+
+static void dc_callvm_argInt_x86(DCCallVM* in_self, DCint x)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcVecAppend(&self->mVecHead, &x, sizeof(DCint));
+}
+
+
+static void dc_callvm_argBool_x86(DCCallVM* in_self, DCbool x)
+{
+  dc_callvm_argInt_x86(in_self, (DCint)x);
+}
+
+
+static void dc_callvm_argChar_x86(DCCallVM* in_self, DCchar x)
+{
+  dc_callvm_argInt_x86(in_self, x);
+}
+
+
+static void dc_callvm_argShort_x86(DCCallVM* in_self, DCshort x)
+{
+  dc_callvm_argInt_x86(in_self, x);
+}
+
+
+static void dc_callvm_argLong_x86(DCCallVM* in_self, DClong x)
+{
+  dc_callvm_argInt_x86(in_self, (DCint)x);
+}
+
+
+static void dc_callvm_argLongLong_x86(DCCallVM* in_self, DClonglong x)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcVecAppend(&self->mVecHead, &x, sizeof(DClonglong));
+}
+
+
+static void dc_callvm_argFloat_x86(DCCallVM* in_self, DCfloat x)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcVecAppend(&self->mVecHead, &x, sizeof(DCfloat));
+}
+
+
+static void dc_callvm_argDouble_x86(DCCallVM* in_self, DCdouble x)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcVecAppend(&self->mVecHead, &x, sizeof(DCdouble));
+}
+
+
+static void dc_callvm_argPointer_x86(DCCallVM* in_self, DCpointer x)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcVecAppend(&self->mVecHead, &x, sizeof(DCpointer));
+}
+
+
+static void dc_callvm_argAggr_x86(DCCallVM* in_self, const DCaggr* ag, const void* x)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+
+  if (!ag) {
+    /* non-trivial aggrs (C++) are passed via pointer, copy has to be
+     * provided by user, as dyncall cannot do such copies */
+    dc_callvm_argPointer_x86(in_self, (DCpointer)x);
+    return;
+  }
+
+  /* aggrs are copied onto the stack, padded to a whole number of slots */
+  dcVecAppend(&self->mVecHead, x, ag->size);
+  dcVecSkip(&self->mVecHead, ((ag->size + (sizeof(DCint)-1)) & -sizeof(DCint)) - ag->size);
+}
+
+
+/* Call. */
+static void dc_callvm_call_x86(DCCallVM* in_self, DCpointer target)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  dcCall_x86_cdecl(
+    target,
+    dcVecData(&self->mVecHead),  /* Pointer to stack arguments. */
+    dcVecSize(&self->mVecHead)   /* Size of stack data.         */
+  );
+}
+
+
+static void dc_callvm_begin_aggr_x86(DCCallVM* in_self, const DCaggr *ag)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+
+  assert(dcVecSize(&self->mVecHead) == 0 && "dc_callvm_begin_aggr_x86 should be called before any function arguments are declared");
+
+  /* aggrs are always returned via hidden pointer (first arg), reserve its slot */
+  self->mAggrReturn = 1;
+  dcVecSkip(&self->mVecHead, sizeof(DCpointer));
+}
+
+
+static void dc_callvm_call_x86_aggr(DCCallVM* in_self, DCpointer target, const DCaggr *ag, DCpointer ret)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+
+  assert(self->mAggrReturn && "dc_callvm_begin_aggr_x86 should be called before calls returning aggregates");
+
+  *(DCpointer*)dcVecData(&self->mVecHead) = ret;
+
+  /* the callee pops the hidden pointer, which the call kernel restores */
+  dc_callvm_call_x86(in_self, target);
+}
+
+
+static void dc_callvm_mode_x86(DCCallVM* in_self, DCint mode);
+
+DCCallVM_vt gVT_x86_cdecl =
+{
+  &dc_callvm_free_x86
+, &dc_callvm_reset_x86
+, &dc_callvm_mode_x86
+, &dc_callvm_argBool_x86
+, &dc_callvm_argChar_x86
+, &dc_callvm_argShort_x86
+, &dc_callvm_argInt_x86
+, &dc_callvm_argLong_x86
+, &dc_callvm_argLongLong_x86
+, &dc_callvm_argFloat_x86
+, &dc_callvm_argDouble_x86
+, &dc_callvm_argPointer_x86
+, &dc_callvm_argAggr_x86
+, (DCvoidvmfunc*)     &dc_callvm_call_x86
+, (DCboolvmfunc*)     &dc_callvm_call_x86
+, (DCcharvmfunc*)     &dc_callvm_call_x86
+, (DCshortvmfunc*)    &dc_callvm_call_x86
+, (DCintvmfunc*)      &dc_callvm_call_x86
+, (DClongvmfunc*)     &dc_callvm_call_x86
+, (DClonglongvmfunc*) &dc_callvm_call_x86
+, (DCfloatvmfunc*)    &dc_callvm_call_x86
+, (DCdoublevmfunc*)   &dc_callvm_call_x86
+, (DCpointervmfunc*)  &dc_callvm_call_x86
+, (DCaggrvmfunc*)     &dc_callvm_call_x86_aggr
+, (DCbeginaggrvmfunc*)&dc_callvm_begin_aggr_x86
+};
+
+
+
+/* mode */
+
+static void dc_callvm_mode_x86(DCCallVM* in_self, DCint mode)
+{
+  DCCallVM_x86* self = (DCCallVM_x86*)in_self;
+  DCCallVM_vt* vt;
+
+  switch(mode) {
+    case DC_CALL_C_DEFAULT:
+    case DC_CALL_C_DEFAULT_THIS:
+    case DC_CALL_C_X86_CDECL: /* = DC_CALL_C_X86_WIN32_THIS_GNU */
+    case DC_CALL_C_ELLIPSIS:
+    case DC_CALL_C_ELLIPSIS_VARARGS:
+      vt = &gVT_x86_cdecl;
+      break;
+    default:
+      self->mInterface.mError = DC_ERROR_UNSUPPORTED_MODE;
+      return;
+  }
+  dc_callvm_base_init(&self->mInterface, vt);
+}
+
+/* Public API. */
+DCCallVM* dcNewCallVM(DCsize size)
+{
+  DCCallVM_x86* p = (DCCallVM_x86*)dcAllocMem(sizeof(DCCallVM_x86)+size);
+
+  dc_callvm_mode_x86((DCCallVM*)p, DC_CALL_C_DEFAULT);
+
+  dcVecInit(&p->mVecHead, size);
+  dc_callvm_reset_x86((DCCallVM*)p);
+
+  return (DCCallVM*)p;
+}
+
@@ -0,0 +1,53 @@
+/*
+
+ Package: dyncall
+ Library: dyncall
+ File: dyncall/dyncall_callvm_x86.h
+ Description:
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+/*
+
+  dyncall callvm for x86 architecture
+
+  SUPPORTED CALLING CONVENTIONS
+  cdecl, System V i386 ABI
+
+*/
+
+
+#ifndef DYNCALL_CALLVM_X86_H
+#define DYNCALL_CALLVM_X86_H
+
+#include "dyncall_macros.h"
+#include "dyncall_callvm.h"
+#include "dyncall_vector.h"
+
+typedef struct
+{
+  DCCallVM  mInterface;  /* this CallVM interface                                           */
+  DCint     mAggrReturn; /* whether the stack begins with a hidden ptr to the aggr ret value */
+  DCVecHead mVecHead;    /* parameters to be pushed onto stack                              */
+} DCCallVM_x86;
+
+#endif /* DYNCALL_CALLVM_X86_H */
+
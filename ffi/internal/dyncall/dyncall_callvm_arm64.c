@@ -0,0 +1,355 @@
+/*
+
+ Package: dyncall
+ Library: dyncall
+ File: dyncall/dyncall_callvm_arm64.c
+ Description: ARM 64-bit ARM Architecture - Implementation
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+/* ARM 64-bit AArch64 PCS, Apple arm64 variant. */
+
+
+#include "dyncall_callvm_arm64.h"
+#include "dyncall_alloc.h"
+#include "dyncall_aggregate.h"
+
+#include <stdint.h>
+#include <string.h>
+#include <assert.h>
+
+
+/*
+** arm64 calling convention
+**
+** - the first 8 int/pointer args are passed in x0-x7, the first 8 fp args
+**   in d0-d7 (s0-s7 for floats), the rest on the stack in 8 byte slots
+** - aggrs up to 16 bytes are passed in int regs, HFAs in fp regs, larger
+**   ones via pointer to a caller-made copy
+** - aggrs are returned like they are passed, larger ones via the memory
+**   pointed to by x8
+** - hybrid return-type call (bool ... pointer in x0, float and double in d0)
+**
+** Apple's variant passes the variable args of ellipsis calls on the stack,
+** and packs stack args with their natural alignment.
+*/
+
+extern void dcCall_arm64(DCpointer target, DCpointer data, DCsize size, DCpointer regdata, DCpointer retregs);
+
+
+
+
+static void dc_callvm_free_arm64(DCCallVM* in_self)
+{
+  dcFreeMem(in_self);
+}
+
+
+static void dc_callvm_reset_arm64(DCCallVM* in_self)
+{
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+  dcVecReset(&self->mVecHead);
+  self->mRegCount_i = self->mRegCount_f = 0;
+  self->mRegData.x8 = NULL;
+  self->mpAggrVecCopies = ((DCchar*)dcVecData(&self->mVecHead)) + self->mVecHead.mTotal;
+}
+
+
+/* pushes an arg of the given size onto the stack */
+static void dc_callvm_push_arm64(DCCallVM_arm64* self, const void* x, DCsize size, DCsize align)
+{
+#if defined(DC__OS_Darwin)
+  if(!self->mVarargs) {
+    dcVecAlign(&self->mVecHead, align);
+    dcVecAppend(&self->mVecHead, x, size);
+    return;
+  }
+#endif
+  if(align < 8)
+    align = 8;
+  dcVecAlign(&self->mVecHead, align);
+  dcVecAppend(&self->mVecHead, x, size);
+  dcVecAlign(&self->mVecHead, 8);
+}
+
+
+static int dc_callvm_regs_arm64(DCCallVM_arm64* self)
+{
+#if defined(DC__OS_Darwin)
+  return !self->mVarargs;
+#else
+  return 1;
+#endif
+}
+
+
+static void dc_callvm_argInteger_arm64(DCCallVM_arm64* self, DClonglong x, DCsize size)
+{
+  if(dc_callvm_regs_arm64(self) && self->mRegCount_i < numIntRegs)
+    self->mRegData.i[self->mRegCount_i++] = x;
+  else
+    dc_callvm_push_arm64(self, &x, size, size); /* little endian, so the low bytes come first */
+}
+
+
+static void dc_callvm_argBool_arm64(DCCallVM* in_self, DCbool x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, x, sizeof(DCbool));
+}
+
+
+static void dc_callvm_argChar_arm64(DCCallVM* in_self, DCchar x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, x, sizeof(DCchar));
+}
+
+
+static void dc_callvm_argShort_arm64(DCCallVM* in_self, DCshort x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, x, sizeof(DCshort));
+}
+
+
+static void dc_callvm_argInt_arm64(DCCallVM* in_self, DCint x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, x, sizeof(DCint));
+}
+
+
+static void dc_callvm_argLong_arm64(DCCallVM* in_self, DClong x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, x, sizeof(DClong));
+}
+
+
+static void dc_callvm_argLongLong_arm64(DCCallVM* in_self, DClonglong x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, x, sizeof(DClonglong));
+}
+
+
+static void dc_callvm_argPointer_arm64(DCCallVM* in_self, DCpointer x)
+{
+  dc_callvm_argInteger_arm64((DCCallVM_arm64*)in_self, (DClonglong)(intptr_t)x, sizeof(DCpointer));
+}
+
+
+static void dc_callvm_argFloat_arm64(DCCallVM* in_self, DCfloat x)
+{
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+
+  if(dc_callvm_regs_arm64(self) && self->mRegCount_f < numFloatRegs) {
+    self->mRegData.f[self->mRegCount_f] = 0.0;
+    memcpy(&self->mRegData.f[self->mRegCount_f++], &x, sizeof(DCfloat));
+  } else
+    dc_callvm_push_arm64(self, &x, sizeof(DCfloat), sizeof(DCfloat));
+}
+
+
+static void dc_callvm_argDouble_arm64(DCCallVM* in_self, DCdouble x)
+{
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+
+  if(dc_callvm_regs_arm64(self) && self->mRegCount_f < numFloatRegs)
+    self->mRegData.f[self->mRegCount_f++] = x;
+  else
+    dc_callvm_push_arm64(self, &x, sizeof(DCdouble), sizeof(DCdouble));
+}
+
+
+static void dc_callvm_argAggr_arm64(DCCallVM* in_self, const DCaggr* ag, const void* x)
+{
+  int i, n;
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+
+  if (!ag) {
+    /* non-trivial aggrs (C++) are passed via pointer, copy has to be
+     * provided by user, as dyncall cannot do such copies */
+    dc_callvm_argPointer_arm64(in_self, (DCpointer)x);
+    return;
+  }
+
+  if(ag->hfa_count) {
+    /* HFAs are passed in as many consecutive fp regs as members, or on the stack */
+    DCsize size = ag->size / ag->hfa_count;
+    if(dc_callvm_regs_arm64(self) && self->mRegCount_f + ag->hfa_count <= numFloatRegs) {
+      for(i=0; i<ag->hfa_count; ++i) {
+        self->mRegData.f[self->mRegCount_f] = 0.0;
+        memcpy(&self->mRegData.f[self->mRegCount_f++], (const DCchar*)x + i*size, size);
+      }
+      return;
+    }
+    if(dc_callvm_regs_arm64(self))
+      self->mRegCount_f = numFloatRegs; /* no later fp args go in regs, either */
+    dc_callvm_push_arm64(self, x, ag->size, ag->alignment);
+    dcVecAlign(&self->mVecHead, 8);
+    return;
+  }
+
+  if(ag->size > 16) {
+    /* pass the aggr indirectly via hidden pointer; requires caller-made copy
+     * to mimic pass-by-value semantics (or a call that modifies the param
+     * would corrupt the source aggr)
+     * place those copies at the end of the param vector (aligned to 16b);
+     * it's a bit of a hack, but should be safe: in any case the vector has
+     * to be big enough to hold all params */
+    self->mpAggrVecCopies = (void*)((intptr_t)((DCchar*)self->mpAggrVecCopies - ag->size) & -16);
+    x = memcpy(self->mpAggrVecCopies, x, ag->size);
+    dc_callvm_argPointer_arm64(in_self, (DCpointer)x);
+    return;
+  }
+
+  /* small aggrs are passed in as many consecutive int regs as needed, or on the stack */
+  n = (ag->size + 7) / 8;
+  if(dc_callvm_regs_arm64(self)) {
+    if(ag->alignment == 16)
+      self->mRegCount_i = (self->mRegCount_i + 1) & ~1; /* start at even reg */
+    if(self->mRegCount_i + n <= numIntRegs) {
+      self->mRegData.i[self->mRegCount_i + n - 1] = 0;
+      memcpy(&self->mRegData.i[self->mRegCount_i], x, ag->size);
+      self->mRegCount_i += n;
+      return;
+    }
+    self->mRegCount_i = numIntRegs; /* no later int args go in regs, either */
+  }
+  dc_callvm_push_arm64(self, x, ag->size, ag->alignment);
+  dcVecAlign(&self->mVecHead, 8);
+}
+
+
+/* Call. */
+static void dc_callvm_call_arm64(DCCallVM* in_self, DCpointer target)
+{
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+
+  dcVecAlign(&self->mVecHead, 16); /* call kernel copies 16 byte units, keeping sp aligned */
+  dcCall_arm64(
+    target,
+    dcVecData(&self->mVecHead),  /* Pointer to stack arguments. */
+    dcVecSize(&self->mVecHead),  /* Size of stack data.         */
+    &self->mRegData,             /* Pointer to register data.   */
+    NULL
+  );
+}
+
+
+static void dc_callvm_call_arm64_aggr(DCCallVM* in_self, DCpointer target, const DCaggr *ag, DCpointer ret)
+{
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+
+  if (!ag || (!ag->hfa_count && ag->size > 16)) {
+    /* aggr is returned via the memory pointed to by x8 */
+    self->mRegData.x8 = ret;
+    dc_callvm_call_arm64(in_self, target);
+  } else {
+    int i;
+    DCchar ret_regs[48];           /* 6 dwords: x0-x1, d0-d3 */
+    dcVecAlign(&self->mVecHead, 16);
+    dcCall_arm64(
+      target,
+      dcVecData(&self->mVecHead),  /* Pointer to stack arguments. */
+      dcVecSize(&self->mVecHead),  /* Size of stack data.         */
+      &self->mRegData,             /* Pointer to register data.   */
+      ret_regs
+    );
+    /* reassemble aggr to be returned from reg data */
+    if(ag->hfa_count) {
+      DCsize size = ag->size / ag->hfa_count;
+      for(i=0; i<ag->hfa_count; ++i)
+        memcpy((DCchar*)ret + i*size, ret_regs + 16 + i*8, size);
+    } else
+      memcpy(ret, ret_regs, ag->size);
+  }
+}
+
+
+static void dc_callvm_mode_arm64(DCCallVM* in_self, DCint mode);
+
+DCCallVM_vt gVT_arm64 =
+{
+  &dc_callvm_free_arm64
+, &dc_callvm_reset_arm64
+, &dc_callvm_mode_arm64
+, &dc_callvm_argBool_arm64
+, &dc_callvm_argChar_arm64
+, &dc_callvm_argShort_arm64
+, &dc_callvm_argInt_arm64
+, &dc_callvm_argLong_arm64
+, &dc_callvm_argLongLong_arm64
+, &dc_callvm_argFloat_arm64
+, &dc_callvm_argDouble_arm64
+, &dc_callvm_argPointer_arm64
+, &dc_callvm_argAggr_arm64
+, (DCvoidvmfunc*)     &dc_callvm_call_arm64
+, (DCboolvmfunc*)     &dc_callvm_call_arm64
+, (DCcharvmfunc*)     &dc_callvm_call_arm64
+, (DCshortvmfunc*)    &dc_callvm_call_arm64
+, (DCintvmfunc*)      &dc_callvm_call_arm64
+, (DClongvmfunc*)     &dc_callvm_call_arm64
+, (DClonglongvmfunc*) &dc_callvm_call_arm64
+, (DCfloatvmfunc*)    &dc_callvm_call_arm64
+, (DCdoublevmfunc*)   &dc_callvm_call_arm64
+, (DCpointervmfunc*)  &dc_callvm_call_arm64
+, (DCaggrvmfunc*)     &dc_callvm_call_arm64_aggr
+, NULL /* beginAggr, x8 doesn't take up an arg reg */
+};
+
+
+
+/* mode */
+
+static void dc_callvm_mode_arm64(DCCallVM* in_self, DCint mode)
+{
+  DCCallVM_arm64* self = (DCCallVM_arm64*)in_self;
+  DCCallVM_vt* vt;
+
+  switch(mode) {
+    case DC_CALL_C_DEFAULT:
+    case DC_CALL_C_DEFAULT_THIS:
+    case DC_CALL_C_ARM64:
+    case DC_CALL_C_ELLIPSIS:
+      self->mVarargs = 0;
+      vt = &gVT_arm64;
+      break;
+    case DC_CALL_C_ELLIPSIS_VARARGS:
+      self->mVarargs = 1;
+      vt = &gVT_arm64;
+      break;
+    default:
+      self->mInterface.mError = DC_ERROR_UNSUPPORTED_MODE;
+      return;
+  }
+  dc_callvm_base_init(&self->mInterface, vt);
+}
+
+/* Public API. */
+DCCallVM* dcNewCallVM(DCsize size)
+{
+  DCCallVM_arm64* p = (DCCallVM_arm64*)dcAllocMem(sizeof(DCCallVM_arm64)+size);
+
+  dc_callvm_mode_arm64((DCCallVM*)p, DC_CALL_C_DEFAULT);
+
+  dcVecInit(&p->mVecHead, size);
+  dc_callvm_reset_arm64((DCCallVM*)p);
+
+  return (DCCallVM*)p;
+}
+
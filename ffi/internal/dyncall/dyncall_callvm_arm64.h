@@ -0,0 +1,68 @@
+/*
+
+ Package: dyncall
+ Library: dyncall
+ File: dyncall/dyncall_callvm_arm64.h
+ Description: ARM 64-bit ARM Architecture - Header
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+/*
+
+  dyncall callvm for arm64 architecture
+
+  SUPPORTED CALLING CONVENTIONS
+  ARM 64-bit AArch64 PCS, with Apple's variant for variadic and stack arguments
+
+*/
+
+
+#ifndef DYNCALL_CALLVM_ARM64_H
+#define DYNCALL_CALLVM_ARM64_H
+
+#include "dyncall_macros.h"
+#include "dyncall_callvm.h"
+#include "dyncall_vector.h"
+
+#define numIntRegs   8
+#define numFloatRegs 8
+
+/* NOTE: if something changes in DCRegData_arm64, update offset marks in dyncall_call_arm64.S */
+typedef struct
+{
+  DCdouble   f[numFloatRegs]; /* offset 0:   d0-d7, floats in the low 4 bytes */
+  DClonglong i[numIntRegs];   /* offset 64:  x0-x7                            */
+  DCpointer  x8;              /* offset 128: indirect result location         */
+} DCRegData_arm64;
+
+typedef struct
+{
+  DCCallVM        mInterface;      /* this CallVM interface                                        */
+  DCint           mVarargs;        /* variable args of an ellipsis call follow (Apple only)        */
+  DCint           mRegCount_i;     /* number of int registers used for parameter passing           */
+  DCint           mRegCount_f;     /* number of fp registers used for parameter passing            */
+  DCpointer       mpAggrVecCopies; /* ptr to copies of aggrs passed via hidden ptr (end of vector) */
+  DCRegData_arm64 mRegData;        /* parameters to be passed via registers                        */
+  DCVecHead       mVecHead;        /* parameters to be pushed onto stack                           */
+} DCCallVM_arm64;
+
+#endif /* DYNCALL_CALLVM_ARM64_H */
+
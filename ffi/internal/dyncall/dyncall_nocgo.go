@@ -0,0 +1,175 @@
+//go:build !cgo && linux && amd64
+
+package dyncall
+
+import (
+	"math"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	_ "qlova.tech/ffi/internal/fakecgo" // threads for C.
+)
+
+//go:cgo_import_dynamic dyncall_errno_location __errno_location "libc.so.6"
+
+// callbackFrame is written by callbackasm, it holds the register
+// arguments of a callback and the registers to return.
+type callbackFrame struct {
+	ints   [6]uint64
+	floats [8]uint64
+	stack  unsafe.Pointer // arguments passed on the C stack.
+	index  uintptr        // userdata of the callback.
+	result [4]uint64      // RAX, RDX, XMM0, XMM1.
+}
+
+// callbackFunc is called by callbackasm, through runtime.cgocallback.
+var callbackFunc = callback
+
+func callback(frame *callbackFrame) {
+	h := lookup(frame.index)
+	args := &Args{frame: frame, aggrs: h.sig.Aggrs}
+	if h.sig.Returns == Aggregate {
+		args.ret = h.sig.Aggrs[len(h.sig.Aggrs)-1]
+		if args.ret.memory {
+			args.hidden = pointer(args.next())
+		}
+	}
+	cb := (*Callback)(thunkOf(frame.index))
+	switch h.fn(cb, args, unsafe.Pointer(&frame.result)) {
+	case Float, Double:
+		frame.result[2] = frame.result[0]
+	}
+}
+
+// Args of a callback, read in order from the registers and
+// then the stack, as per the System V ABI.
+type Args struct {
+	frame  *callbackFrame
+	ints   int
+	floats int
+	words  int     // read from the stack.
+	aggrs  []*Aggr // yet to be read.
+
+	ret    *Aggr          // returned aggregate, if any.
+	hidden unsafe.Pointer // where ret is returned, if in memory.
+}
+
+func (args *Args) word() uint64 {
+	value := *(*uint64)(unsafe.Add(args.frame.stack, args.words*8))
+	args.words++
+	return value
+}
+
+// next integer class argument.
+func (args *Args) next() uint64 {
+	if args.ints < len(args.frame.ints) {
+		args.ints++
+		return args.frame.ints[args.ints-1]
+	}
+	return args.word()
+}
+
+// next floating point argument.
+func (args *Args) float() uint64 {
+	if args.floats < len(args.frame.floats) {
+		args.floats++
+		return args.frame.floats[args.floats-1]
+	}
+	return args.word()
+}
+
+func (args *Args) Bool() int32 {
+	if args.Int() != 0 {
+		return 1
+	}
+	return 0
+}
+
+func (args *Args) Char() int8               { return int8(args.next()) }
+func (args *Args) Short() int16             { return int16(args.next()) }
+func (args *Args) Int() int32               { return int32(args.next()) }
+func (args *Args) Long() int64              { return int64(args.next()) }
+func (args *Args) LongLong() int64          { return int64(args.next()) }
+func (args *Args) UnsignedChar() uint8      { return uint8(args.next()) }
+func (args *Args) UnsignedShort() uint16    { return uint16(args.next()) }
+func (args *Args) UnsignedInt() uint32      { return uint32(args.next()) }
+func (args *Args) UnsignedLong() uint64     { return args.next() }
+func (args *Args) UnsignedLongLong() uint64 { return args.next() }
+func (args *Args) Float() float32           { return math.Float32frombits(uint32(args.float())) }
+func (args *Args) Double() float64          { return math.Float64frombits(args.float()) }
+func (args *Args) Pointer() unsafe.Pointer  { return pointer(args.next()) }
+
+// pointer returns the C pointer held by a register.
+func pointer(word uint64) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&word))
+}
+
+// Callback is a C function pointer, it points to a thunk that
+// jumps to callbackasm with the userdata of the callback.
+type Callback struct{ _ [thunkSize]byte }
+
+const (
+	thunkSize = 32
+	thunkPage = 4096
+)
+
+// thunk code, as in dyncall_thunk_amd64.c:
+//
+//	movabs r10, userdata
+//	movabs r11, callbackasm
+//	jmp r11
+var thunkCode = [thunkSize]byte{
+	0: 0x49, 1: 0xBA, // userdata at 2
+	10: 0x49, 11: 0xBB, // callbackasm at 12
+	20: 0x41, 21: 0xFF, 22: 0xE3,
+}
+
+var callbackasmABI0 uintptr // set by assembly.
+
+// thunks holds the pages of thunks, each userdata has its own
+// thunk, which is never unmapped so that it can be reused.
+var thunks struct {
+	sync.Mutex
+	pages [][]byte
+}
+
+// thunkOf returns the thunk for the userdata, mapping
+// the page that holds it, if needed.
+func thunkOf(userdata uintptr) unsafe.Pointer {
+	const perPage = thunkPage / thunkSize
+	index := int(userdata - 1)
+	thunks.Lock()
+	defer thunks.Unlock()
+	for len(thunks.pages) <= index/perPage {
+		page, err := syscall.Mmap(-1, 0, thunkPage, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+		if err != nil {
+			panic("dyncall: cannot allocate callback: " + err.Error())
+		}
+		first := uint64(len(thunks.pages) * perPage)
+		for i := 0; i < perPage; i++ {
+			code := page[i*thunkSize:]
+			copy(code, thunkCode[:])
+			*(*uint64)(unsafe.Pointer(&code[2])) = first + uint64(i) + 1
+			*(*uint64)(unsafe.Pointer(&code[12])) = uint64(callbackasmABI0)
+		}
+		if err := syscall.Mprotect(page, syscall.PROT_READ|syscall.PROT_EXEC); err != nil {
+			panic("dyncall: cannot allocate callback: " + err.Error())
+		}
+		thunks.pages = append(thunks.pages, page)
+	}
+	return unsafe.Pointer(&thunks.pages[index/perPage][index%perPage*thunkSize])
+}
+
+// NewCallback returns a C function pointer with the given signature
+// that calls handler, it must be freed with [Callback.Free] once it
+// is no longer reachable from C.
+func NewCallback(sig Signature, handler CallbackHandler) *Callback {
+	return (*Callback)(thunkOf(register(handler, sig, nil)))
+}
+
+// Free releases the callback and its handler, the callback
+// must not be called afterwards.
+func (callback *Callback) Free() {
+	unregister(*(*uintptr)(unsafe.Add(unsafe.Pointer(callback), 2)))
+}
@@ -0,0 +1,128 @@
+//go:build !cgo && linux && amd64
+
+#include "textflag.h"
+
+GLOBL ·callstubABI0(SB), NOPTR|RODATA, $8
+DATA ·callstubABI0(SB)/8, $callstub<>(SB)
+
+GLOBL ·callbackasmABI0(SB), NOPTR|RODATA, $8
+DATA ·callbackasmABI0(SB)/8, $callbackasm<>(SB)
+
+// void callstub(callFrame *f), called by runtime.cgocall on the
+// system stack with the C calling convention, see dyncall_call_amd64.S
+TEXT callstub<>(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	PUSHQ	BX
+	PUSHQ	R12
+	MOVQ	DI, BX
+
+	// copy the stack arguments, keeping SP aligned to 16 bytes.
+	MOVQ	128(BX), CX
+	MOVQ	CX, AX
+	SHLQ	$3, AX
+	ADDQ	$15, AX
+	ANDQ	$-16, AX
+	SUBQ	AX, SP
+	MOVQ	120(BX), SI
+	MOVQ	SP, DI
+copy:
+	TESTQ	CX, CX
+	JZ	copied
+	MOVQ	(SI), AX
+	MOVQ	AX, (DI)
+	ADDQ	$8, SI
+	ADDQ	$8, DI
+	DECQ	CX
+	JMP	copy
+copied:
+
+	// errno = 0, so that it can be observed after the call.
+	CALL	dyncall_errno_location(SB)
+	MOVL	$0, (AX)
+	MOVQ	AX, R12
+
+	MOVQ	56(BX), X0
+	MOVQ	64(BX), X1
+	MOVQ	72(BX), X2
+	MOVQ	80(BX), X3
+	MOVQ	88(BX), X4
+	MOVQ	96(BX), X5
+	MOVQ	104(BX), X6
+	MOVQ	112(BX), X7
+	MOVQ	8(BX), DI
+	MOVQ	16(BX), SI
+	MOVQ	24(BX), DX
+	MOVQ	32(BX), CX
+	MOVQ	40(BX), R8
+	MOVQ	48(BX), R9
+	MOVQ	136(BX), AX // number of vector registers, for variadic calls.
+	MOVQ	0(BX), R10
+	CALL	R10
+
+	MOVQ	AX, 144(BX)
+	MOVQ	DX, 152(BX)
+	MOVQ	X0, 160(BX)
+	MOVQ	X1, 168(BX)
+	MOVL	(R12), AX
+	MOVL	AX, 176(BX)
+
+	LEAQ	-16(BP), SP
+	POPQ	R12
+	POPQ	BX
+	POPQ	BP
+	RET
+
+// callbackasm is jumped to by the thunk of a callback, with its
+// userdata in R10. It saves the arguments to a callbackFrame and
+// calls callback through runtime.cgocallback, as crosscall2 does.
+TEXT callbackasm<>(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	PUSHQ	BX
+	PUSHQ	R12
+	PUSHQ	R13
+	PUSHQ	R14
+	PUSHQ	R15
+
+	// 0(SP) arguments of cgocallback, 24(SP) callbackFrame.
+	SUBQ	$184, SP
+	MOVQ	DI, 24(SP)
+	MOVQ	SI, 32(SP)
+	MOVQ	DX, 40(SP)
+	MOVQ	CX, 48(SP)
+	MOVQ	R8, 56(SP)
+	MOVQ	R9, 64(SP)
+	MOVQ	X0, 72(SP)
+	MOVQ	X1, 80(SP)
+	MOVQ	X2, 88(SP)
+	MOVQ	X3, 96(SP)
+	MOVQ	X4, 104(SP)
+	MOVQ	X5, 112(SP)
+	MOVQ	X6, 120(SP)
+	MOVQ	X7, 128(SP)
+	LEAQ	16(BP), AX
+	MOVQ	AX, 136(SP)
+	MOVQ	R10, 144(SP)
+
+	MOVQ	·callbackFunc(SB), AX
+	MOVQ	0(AX), AX
+	MOVQ	AX, 0(SP)
+	LEAQ	24(SP), AX
+	MOVQ	AX, 8(SP)
+	MOVQ	$0, 16(SP)
+	CALL	runtime·cgocallback(SB)
+
+	MOVQ	152(SP), AX
+	MOVQ	160(SP), DX
+	MOVQ	168(SP), X0
+	MOVQ	176(SP), X1
+
+	ADDQ	$184, SP
+	POPQ	R15
+	POPQ	R14
+	POPQ	R13
+	POPQ	R12
+	POPQ	BX
+	POPQ	BP
+	RET
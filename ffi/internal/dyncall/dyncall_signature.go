@@ -0,0 +1,103 @@
+package dyncall
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Signature kinds, as defined by dyncall_signature.h.
+const (
+	Void             = 'v'
+	Bool             = 'B'
+	Char             = 'c'
+	UnsignedChar     = 'C'
+	Short            = 's'
+	UnsignedShort    = 'S'
+	Int              = 'i'
+	Uint             = 'I'
+	Long             = 'j'
+	UnsignedLong     = 'J'
+	LongLong         = 'l'
+	UnsignedLongLong = 'L'
+	Float            = 'f'
+	Double           = 'd'
+	Pointer          = 'p'
+	String           = 'Z'
+	Aggregate        = 'A'
+)
+
+// Calling convention modes, see [VM.Mode].
+const (
+	ModeDefault         = 0
+	ModeEllipsis        = 100
+	ModeEllipsisVarargs = 101
+)
+
+type Signature struct {
+	Args    []rune
+	Returns rune
+
+	// Aggrs describes each Aggregate in Args, in order,
+	// followed by the Returns aggregate, if any.
+	Aggrs []*Aggr
+}
+
+type CallbackHandler func(*Callback, *Args, unsafe.Pointer) rune
+
+// handlers is the table of callback handlers, the userdata of each
+// callback is its index in the table plus one. Freed slots are
+// reused, so that the table does not grow without bound.
+var handlers struct {
+	sync.RWMutex
+	table []handler
+	free  []uintptr
+}
+
+type handler struct {
+	fn    CallbackHandler
+	sig   Signature
+	aggrs unsafe.Pointer // C memory, freed with the callback.
+}
+
+// register adds the handler to the table and returns its userdata.
+func register(fn CallbackHandler, sig Signature, aggrs unsafe.Pointer) uintptr {
+	handlers.Lock()
+	defer handlers.Unlock()
+	if n := len(handlers.free); n > 0 {
+		userdata := handlers.free[n-1]
+		handlers.free = handlers.free[:n-1]
+		handlers.table[userdata-1] = handler{fn, sig, aggrs}
+		return userdata
+	}
+	handlers.table = append(handlers.table, handler{fn, sig, aggrs})
+	return uintptr(len(handlers.table))
+}
+
+// unregister removes the handler with the given userdata from the
+// table, returning its aggregate table.
+func unregister(userdata uintptr) unsafe.Pointer {
+	handlers.Lock()
+	defer handlers.Unlock()
+	aggrs := handlers.table[userdata-1].aggrs
+	handlers.table[userdata-1] = handler{}
+	handlers.free = append(handlers.free, userdata)
+	return aggrs
+}
+
+// Handlers returns the number of callbacks that have not been freed.
+func Handlers() int {
+	handlers.RLock()
+	defer handlers.RUnlock()
+	return len(handlers.table) - len(handlers.free)
+}
+
+// lookup returns the handler with the given userdata.
+func lookup(userdata uintptr) handler {
+	handlers.RLock()
+	h := handlers.table[userdata-1]
+	handlers.RUnlock()
+	if h.fn == nil {
+		panic("dyncall: callback called after it was freed")
+	}
+	return h
+}
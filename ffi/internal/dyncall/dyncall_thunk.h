@@ -62,7 +62,7 @@ extern "C" {
 void   dcbInitThunk(DCThunk* p, void (*entry)());
 
 #if defined(DC__Arch_Intel_x86)
-#include "dyncall_thunk_x86.h"
+#include "dyncall_thunk_386.h"
 #elif defined (DC__Arch_AMD64)
 #include "dyncall_thunk_amd64.h"
 #elif defined (DC__Arch_PPC32)
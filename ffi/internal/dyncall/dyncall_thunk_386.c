@@ -0,0 +1,60 @@
+/*
+
+ Package: dyncall
+ Library: dyncallback
+ File: dyncallback/dyncall_thunk_x86.c
+ Description: Thunk - Implementation for x86
+ License:
+
+   Copyright (c) 2007-2018 Daniel Adler <dadler@uni-goettingen.de>,
+                           Tassilo Philipp <tphilipp@potion-studios.com>
+
+   Permission to use, copy, modify, and distribute this software for any
+   purpose with or without fee is hereby granted, provided that the above
+   copyright notice and this permission notice appear in all copies.
+
+   THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+   WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+   MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+   ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+   WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+   ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+   OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+*/
+
+
+
+#include "dyncall_thunk.h"
+
+#include <string.h>
+
+void dcbInitThunk(DCThunk* p, void (*entry)())
+{
+  /*
+    # x86 thunk code:
+    .intel_syntax
+
+    thunk:
+        mov   eax, thunk  # copy ptr to thunk to EAX and use address
+        jmp   [eax+12]    # in 'entry' (stored at thunk+12) for jump
+        nop
+        nop
+        nop
+        nop
+    entry:
+        .resd 1
+   */
+
+  static const unsigned char code[12] = {
+    0xb8, 0x00, 0x00, 0x00, 0x00, /* mov eax, imm32 */
+    0xff, 0x60, 0x0c,             /* jmp [eax+12]   */
+    0x90, 0x90, 0x90, 0x90        /* nop            */
+  };
+  void* self = p;
+
+  memcpy(p->code, code, sizeof(code));
+  memcpy(p->code+1, &self, sizeof(self));
+  p->entry = entry;
+}
+
@@ -0,0 +1,84 @@
+//go:build !cgo && !(linux && amd64)
+
+package dyncall
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// Without cgo, C can only be called on linux/amd64, see dyncall_nocgo.go,
+// other targets build so that the packages that import this one do too,
+// but every call panics.
+
+func unsupported() {
+	panic("dyncall: calling C without cgo is not supported on " + runtime.GOOS + "/" + runtime.GOARCH)
+}
+
+type Args struct{}
+
+func (args *Args) Bool() int32              { unsupported(); return 0 }
+func (args *Args) Char() int8               { unsupported(); return 0 }
+func (args *Args) Short() int16             { unsupported(); return 0 }
+func (args *Args) Int() int32               { unsupported(); return 0 }
+func (args *Args) Long() int64              { unsupported(); return 0 }
+func (args *Args) LongLong() int64          { unsupported(); return 0 }
+func (args *Args) UnsignedChar() uint8      { unsupported(); return 0 }
+func (args *Args) UnsignedShort() uint16    { unsupported(); return 0 }
+func (args *Args) UnsignedInt() uint32      { unsupported(); return 0 }
+func (args *Args) UnsignedLong() uint64     { unsupported(); return 0 }
+func (args *Args) UnsignedLongLong() uint64 { unsupported(); return 0 }
+func (args *Args) Float() float32           { unsupported(); return 0 }
+func (args *Args) Double() float64          { unsupported(); return 0 }
+func (args *Args) Pointer() unsafe.Pointer  { unsupported(); return nil }
+
+func (args *Args) Aggr(target unsafe.Pointer) unsafe.Pointer { unsupported(); return nil }
+
+func (args *Args) ReturnAggr(result unsafe.Pointer, value unsafe.Pointer) { unsupported() }
+
+type Callback struct{}
+
+func NewCallback(sig Signature, handler CallbackHandler) *Callback { unsupported(); return nil }
+
+func (callback *Callback) Free() { unsupported() }
+
+type Aggr struct{}
+
+func NewAggr(fields, size int) *Aggr { return &Aggr{} }
+
+func (ag *Aggr) Field(kind rune, offset, length int, sub *Aggr) {}
+func (ag *Aggr) Close()                                         {}
+func (ag *Aggr) Free()                                          {}
+
+type VM struct{}
+
+func NewVM(size int) *VM { return &VM{} }
+
+func (vm *VM) Reset()               {}
+func (vm *VM) Mode(mode int)        {}
+func (vm *VM) Errno() syscall.Errno { return 0 }
+func (vm *VM) Free()                {}
+
+func (vm *VM) PushBool(value bool)                     { unsupported() }
+func (vm *VM) PushInt8(value int8)                     { unsupported() }
+func (vm *VM) PushInt16(value int16)                   { unsupported() }
+func (vm *VM) PushInt32(value int32)                   { unsupported() }
+func (vm *VM) PushInt(value int)                       { unsupported() }
+func (vm *VM) PushInt64(value int64)                   { unsupported() }
+func (vm *VM) PushFloat32(value float32)               { unsupported() }
+func (vm *VM) PushFloat64(value float64)               { unsupported() }
+func (vm *VM) PushPointer(value unsafe.Pointer)        { unsupported() }
+func (vm *VM) PushAggr(ag *Aggr, value unsafe.Pointer) { unsupported() }
+
+func (vm *VM) Call(address unsafe.Pointer)                                      { unsupported() }
+func (vm *VM) CallBool(address unsafe.Pointer) bool                             { unsupported(); return false }
+func (vm *VM) CallInt8(address unsafe.Pointer) int8                             { unsupported(); return 0 }
+func (vm *VM) CallInt16(address unsafe.Pointer) int16                           { unsupported(); return 0 }
+func (vm *VM) CallInt32(address unsafe.Pointer) int32                           { unsupported(); return 0 }
+func (vm *VM) CallInt(address unsafe.Pointer) int                               { unsupported(); return 0 }
+func (vm *VM) CallInt64(address unsafe.Pointer) int64                           { unsupported(); return 0 }
+func (vm *VM) CallFloat32(address unsafe.Pointer) float32                       { unsupported(); return 0 }
+func (vm *VM) CallFloat64(address unsafe.Pointer) float64                       { unsupported(); return 0 }
+func (vm *VM) CallPointer(address unsafe.Pointer) unsafe.Pointer                { unsupported(); return nil }
+func (vm *VM) CallAggr(address unsafe.Pointer, ag *Aggr, result unsafe.Pointer) { unsupported() }
@@ -9,21 +9,24 @@ package dyncall
 
 extern DCsigchar bridge_callback(DCCallback*, DCArgs*, DCValue*, uintptr_t);
 
-DCCallback *goNewCallback(const DCsigchar * signature, uintptr_t userdata) {
-	return dcbNewCallback(signature, (DCCallbackHandler*)bridge_callback, (void*)userdata);
+DCCallback *goNewCallback(const DCsigchar * signature, uintptr_t userdata, DCaggr *const * aggrs) {
+	return dcbNewCallback2(signature, (DCCallbackHandler*)bridge_callback, (void*)userdata, aggrs);
 }
 
 typedef struct {
 	DCsigchar vtype;
 	DCValue value;
+	const DCaggr *aggr;
 } GoArg;
 
-void goArgs(DCCallVM *vm, GoArg *arg, int argc) {
-	dcReset(vm);
+void goPush(DCCallVM *vm, GoArg *arg, int argc) {
 	DCValue value;
 	for (int i = 0; i < argc; i++) {
 		value = arg[i].value;
 		switch (arg[i].vtype) {
+		case DC_SIGCHAR_CC_PREFIX:
+			dcMode(vm, value.i);
+			break;
 		case DC_SIGCHAR_BOOL:
 			dcArgBool(vm, value.B);
 			break;
@@ -70,12 +73,24 @@ void goArgs(DCCallVM *vm, GoArg *arg, int argc) {
 			dcArgPointer(vm, value.p);
 			break;
 		case DC_SIGCHAR_AGGREGATE:
-			assert(0); // FIXME
+			dcArgAggr(vm, arg[i].aggr, value.p);
 			break;
 		}
 	}
 }
 
+void goArgs(DCCallVM *vm, GoArg *arg, int argc) {
+	dcReset(vm);
+	goPush(vm, arg, argc);
+}
+
+void goCallAggr(DCCallVM *vm, DCpointer funcptr, const DCaggr *ag, DCpointer ret, GoArg *arg, int argc) {
+	dcReset(vm);
+	dcBeginCallAggr(vm, ag);
+	goPush(vm, arg, argc);
+	dcCallAggr(vm, funcptr, ag, ret);
+}
+
 double goCallDouble(DCCallVM *vm, DCpointer funcptr, GoArg *arg, int argc) {
 	goArgs(vm, arg, argc);
 	return dcCallDouble(vm, funcptr);
@@ -84,28 +99,47 @@ double goCallDouble(DCCallVM *vm, DCpointer funcptr, GoArg *arg, int argc) {
 */
 import "C"
 import (
+	"syscall"
 	"unsafe"
 )
 
 type Callback C.DCCallback
 
-type CallbackHandler func(*Callback, *Args, unsafe.Pointer) rune
-
+// NewCallback returns a C function pointer with the given signature
+// that calls handler, it must be freed with [Callback.Free] once it
+// is no longer reachable from C.
 func NewCallback(sig Signature, handler CallbackHandler) *Callback {
-	functions = append(functions, handler)
-
 	s := C.CString(string(sig.Args) + ")" + string(sig.Returns))
 	defer C.free(unsafe.Pointer(s))
-	return (*Callback)(C.goNewCallback((*C.DCsigchar)(s), C.uintptr_t(len(functions))))
+
+	// the aggregate table must outlive the callback, so
+	// it is allocated in C memory.
+	var aggrs **C.DCaggr
+	if len(sig.Aggrs) > 0 {
+		aggrs = (**C.DCaggr)(C.malloc(C.size_t(len(sig.Aggrs)) * C.size_t(unsafe.Sizeof(aggrs))))
+		table := unsafe.Slice(aggrs, len(sig.Aggrs))
+		for i, ag := range sig.Aggrs {
+			table[i] = (*C.DCaggr)(ag)
+		}
+	}
+	userdata := register(handler, sig, unsafe.Pointer(aggrs))
+	return (*Callback)(C.goNewCallback((*C.DCsigchar)(s), C.uintptr_t(userdata), aggrs))
 }
 
+// Free releases the callback and its handler, the callback
+// must not be called afterwards.
 func (callback *Callback) Free() {
+	userdata := uintptr(C.dcbGetUserData((*C.DCCallback)(callback)))
 	C.dcbFreeCallback((*C.DCCallback)(callback))
+	C.free(unregister(userdata))
 }
 
 type VM struct {
 	ptr *C.DCCallVM
 	buf []C.GoArg
+
+	modal bool          // the calling convention mode has been changed.
+	errno syscall.Errno // the value of errno after the last call.
 }
 
 func NewVM(size int) *VM {
@@ -117,6 +151,38 @@ func NewVM(size int) *VM {
 
 func (vm *VM) Reset() {
 	vm.buf = vm.buf[:0]
+	if vm.modal {
+		vm.modal = false
+		vm.Mode(ModeDefault)
+	}
+}
+
+// Mode switches the calling convention mode for the arguments
+// that follow, C variadic functions must switch to ModeEllipsis
+// before any arguments and then to ModeEllipsisVarargs before
+// the variadic arguments.
+func (vm *VM) Mode(mode int) {
+	var val C.DCValue
+	*(*C.DCint)(unsafe.Pointer(&val)) = C.DCint(mode)
+	vm.buf = append(vm.buf, C.GoArg{
+		vtype: C.DC_SIGCHAR_CC_PREFIX,
+		value: val,
+	})
+	vm.modal = mode != ModeDefault
+}
+
+// Errno returns the value of errno after the last call, as observed
+// on the same thread, or zero if the call did not set errno.
+func (vm *VM) Errno() syscall.Errno {
+	return vm.errno
+}
+
+// errnoOf returns the errno reported by a cgo call.
+func errnoOf(err error) syscall.Errno {
+	if errno, ok := err.(syscall.Errno); ok {
+		return errno
+	}
+	return 0
 }
 
 func (vm *VM) Free() {
@@ -208,51 +274,89 @@ func (vm *VM) PushPointer(value unsafe.Pointer) {
 	})
 }
 
+// PushAggr pushes the aggregate pointed to by value, the
+// value must remain valid until the call has been made.
+func (vm *VM) PushAggr(ag *Aggr, value unsafe.Pointer) {
+	var val C.DCValue
+	*(*C.DCpointer)(unsafe.Pointer(&val)) = C.DCpointer(value)
+	vm.buf = append(vm.buf, C.GoArg{
+		vtype: C.DC_SIGCHAR_AGGREGATE,
+		value: val,
+		aggr:  (*C.DCaggr)(ag),
+	})
+}
+
 func (vm *VM) Call(address unsafe.Pointer) {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	C.dcCallVoid((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	_, err := C.dcCallVoid((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
 }
 
 func (vm *VM) CallBool(address unsafe.Pointer) bool {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return C.dcCallBool((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))) != 0
+	result, err := C.dcCallBool((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return result != 0
 }
 
 func (vm *VM) CallInt8(address unsafe.Pointer) int8 {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return int8(C.dcCallChar((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallChar((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return int8(result)
 }
 
 func (vm *VM) CallInt16(address unsafe.Pointer) int16 {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return int16(C.dcCallShort((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallShort((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return int16(result)
 }
 
 func (vm *VM) CallInt32(address unsafe.Pointer) int32 {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return int32(C.dcCallInt((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallInt((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return int32(result)
 }
 
 func (vm *VM) CallInt(address unsafe.Pointer) int {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return int(C.dcCallLong((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallLong((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return int(result)
 }
 
 func (vm *VM) CallInt64(address unsafe.Pointer) int64 {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return int64(C.dcCallLongLong((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallLongLong((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return int64(result)
 }
 
 func (vm *VM) CallFloat32(address unsafe.Pointer) float32 {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return float32(C.dcCallFloat((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallFloat((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return float32(result)
 }
 
 func (vm *VM) CallFloat64(address unsafe.Pointer) float64 {
-	return float64(C.goCallDouble((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)), unsafe.SliceData(vm.buf), C.int(len(vm.buf))))
+	result, err := C.goCallDouble((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
+	vm.errno = errnoOf(err)
+	return float64(result)
 }
 
 func (vm *VM) CallPointer(address unsafe.Pointer) unsafe.Pointer {
 	C.goArgs((*C.DCCallVM)(vm.ptr), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
-	return unsafe.Pointer(C.dcCallPointer((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address))))
+	result, err := C.dcCallPointer((*C.DCCallVM)(vm.ptr), (C.DCpointer)(unsafe.Pointer(address)))
+	vm.errno = errnoOf(err)
+	return unsafe.Pointer(result)
+}
+
+// CallAggr calls the function at address, which returns an
+// aggregate described by ag, the result is written to result.
+func (vm *VM) CallAggr(address unsafe.Pointer, ag *Aggr, result unsafe.Pointer) {
+	_, err := C.goCallAggr((*C.DCCallVM)(vm.ptr), (C.DCpointer)(address), (*C.DCaggr)(ag), (C.DCpointer)(result), unsafe.SliceData(vm.buf), C.int(len(vm.buf)))
+	vm.errno = errnoOf(err)
 }
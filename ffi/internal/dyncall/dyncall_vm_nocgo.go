@@ -0,0 +1,209 @@
+//go:build !cgo && linux && amd64
+
+package dyncall
+
+import (
+	"math"
+	"syscall"
+	"unsafe"
+)
+
+//go:linkname runtime_cgocall runtime.cgocall
+//go:noescape
+func runtime_cgocall(fn uintptr, arg unsafe.Pointer) int32
+
+var callstubABI0 uintptr // set by assembly.
+
+// callFrame is read and written by callstub, it holds the registers
+// and stack of a C call, as per the System V ABI.
+type callFrame struct {
+	fn     unsafe.Pointer
+	ints   [6]uint64
+	floats [8]uint64
+	stack  unsafe.Pointer
+	words  uintptr   // on the stack.
+	sse    uintptr   // number of vector registers used, for variadic calls.
+	result [4]uint64 // RAX, RDX, XMM0, XMM1.
+	errno  int32
+}
+
+// arg that has been pushed.
+type arg struct {
+	kind  rune
+	value uint64
+	ptr   unsafe.Pointer // kept alive until the call.
+	aggr  *Aggr
+}
+
+type VM struct {
+	buf   []arg
+	frame callFrame
+	stack []uint64
+
+	errno syscall.Errno // the value of errno after the last call.
+}
+
+func NewVM(size int) *VM {
+	return &VM{}
+}
+
+func (vm *VM) Reset() {
+	vm.buf = vm.buf[:0]
+}
+
+// Mode switches the calling convention mode for the arguments
+// that follow, C variadic functions must switch to ModeEllipsis
+// before any arguments and then to ModeEllipsisVarargs before
+// the variadic arguments. Variadic arguments are passed like
+// any other on amd64, so the mode is only a hint.
+func (vm *VM) Mode(mode int) {}
+
+// Errno returns the value of errno after the last call, as observed
+// on the same thread, or zero if the call did not set errno.
+func (vm *VM) Errno() syscall.Errno {
+	return vm.errno
+}
+
+func (vm *VM) Free() {}
+
+func (vm *VM) push(kind rune, value uint64) {
+	vm.buf = append(vm.buf, arg{kind: kind, value: value})
+}
+
+func (vm *VM) PushBool(value bool) {
+	if value {
+		vm.push(Bool, 1)
+	} else {
+		vm.push(Bool, 0)
+	}
+}
+
+func (vm *VM) PushInt8(value int8)       { vm.push(Char, uint64(value)) }
+func (vm *VM) PushInt16(value int16)     { vm.push(Short, uint64(value)) }
+func (vm *VM) PushInt32(value int32)     { vm.push(Int, uint64(value)) }
+func (vm *VM) PushInt(value int)         { vm.push(Long, uint64(value)) }
+func (vm *VM) PushInt64(value int64)     { vm.push(LongLong, uint64(value)) }
+func (vm *VM) PushFloat32(value float32) { vm.push(Float, uint64(math.Float32bits(value))) }
+func (vm *VM) PushFloat64(value float64) { vm.push(Double, math.Float64bits(value)) }
+
+func (vm *VM) PushPointer(value unsafe.Pointer) {
+	vm.buf = append(vm.buf, arg{kind: Pointer, value: uint64(uintptr(value)), ptr: value})
+}
+
+// PushAggr pushes the aggregate pointed to by value, the
+// value must remain valid until the call has been made.
+func (vm *VM) PushAggr(ag *Aggr, value unsafe.Pointer) {
+	vm.buf = append(vm.buf, arg{kind: Aggregate, ptr: value, aggr: ag})
+}
+
+// call assigns the pushed arguments to registers and the stack,
+// then calls the function at address, ret is the aggregate that
+// it returns into result, if any.
+func (vm *VM) call(address unsafe.Pointer, ret *Aggr, result unsafe.Pointer) {
+	f := &vm.frame
+	*f = callFrame{fn: address}
+	ints, floats := 0, 0
+	vm.stack = vm.stack[:0]
+	if ret != nil && ret.memory {
+		f.ints[0] = uint64(uintptr(result))
+		ints++
+	}
+	for _, arg := range vm.buf {
+		switch arg.kind {
+		case Float, Double:
+			if floats < len(f.floats) {
+				f.floats[floats] = arg.value
+				floats++
+			} else {
+				vm.stack = append(vm.stack, arg.value)
+			}
+		case Aggregate:
+			ag := arg.aggr
+			if ag.memory || ints+ag.ints > len(f.ints) || floats+ag.sse > len(f.floats) {
+				for i := 0; i < ag.size; i += 8 {
+					vm.stack = append(vm.stack, ag.word(arg.ptr, i))
+				}
+				continue
+			}
+			for i, class := range ag.classes[:ag.words()] {
+				if class == classSSE {
+					f.floats[floats] = ag.word(arg.ptr, i*8)
+					floats++
+				} else {
+					f.ints[ints] = ag.word(arg.ptr, i*8)
+					ints++
+				}
+			}
+		default:
+			if ints < len(f.ints) {
+				f.ints[ints] = arg.value
+				ints++
+			} else {
+				vm.stack = append(vm.stack, arg.value)
+			}
+		}
+	}
+	f.sse = uintptr(floats)
+	f.stack = unsafe.Pointer(unsafe.SliceData(vm.stack))
+	f.words = uintptr(len(vm.stack))
+	runtime_cgocall(callstubABI0, unsafe.Pointer(f))
+	vm.errno = syscall.Errno(f.errno)
+}
+
+func (vm *VM) Call(address unsafe.Pointer) {
+	vm.call(address, nil, nil)
+}
+
+func (vm *VM) CallBool(address unsafe.Pointer) bool {
+	vm.call(address, nil, nil)
+	return int32(vm.frame.result[0]) != 0
+}
+
+func (vm *VM) CallInt8(address unsafe.Pointer) int8 {
+	vm.call(address, nil, nil)
+	return int8(vm.frame.result[0])
+}
+
+func (vm *VM) CallInt16(address unsafe.Pointer) int16 {
+	vm.call(address, nil, nil)
+	return int16(vm.frame.result[0])
+}
+
+func (vm *VM) CallInt32(address unsafe.Pointer) int32 {
+	vm.call(address, nil, nil)
+	return int32(vm.frame.result[0])
+}
+
+func (vm *VM) CallInt(address unsafe.Pointer) int {
+	vm.call(address, nil, nil)
+	return int(vm.frame.result[0])
+}
+
+func (vm *VM) CallInt64(address unsafe.Pointer) int64 {
+	vm.call(address, nil, nil)
+	return int64(vm.frame.result[0])
+}
+
+func (vm *VM) CallFloat32(address unsafe.Pointer) float32 {
+	vm.call(address, nil, nil)
+	return math.Float32frombits(uint32(vm.frame.result[2]))
+}
+
+func (vm *VM) CallFloat64(address unsafe.Pointer) float64 {
+	vm.call(address, nil, nil)
+	return math.Float64frombits(vm.frame.result[2])
+}
+
+func (vm *VM) CallPointer(address unsafe.Pointer) unsafe.Pointer {
+	vm.call(address, nil, nil)
+	return pointer(vm.frame.result[0])
+}
+
+// CallAggr calls the function at address, which returns an
+// aggregate described by ag, the result is written to result.
+func (vm *VM) CallAggr(address unsafe.Pointer, ag *Aggr, result unsafe.Pointer) {
+	vm.call(address, ag, result)
+	if !ag.memory {
+		ag.unpack(result, &vm.frame.result)
+	}
+}
@@ -0,0 +1,180 @@
+//go:build !cgo && linux && amd64
+
+#include "textflag.h"
+
+// The trampolines below are called by the runtime with the C calling
+// convention, on the system stack, they preserve the callee-saved
+// registers and keep the stack aligned to 16 bytes at each call.
+
+// setg_gcc is the runtime function that sets g, called with g in DI.
+GLOBL setg_gcc<>(SB), NOPTR, $8
+
+// void x_cgo_init(G *g, void (*setg)(void*))
+TEXT x_cgo_init_trampoline(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	PUSHQ	BX
+	PUSHQ	R12
+	MOVQ	DI, R12
+	MOVQ	SI, setg_gcc<>(SB)
+	SUBQ	$16, SP
+	MOVQ	SP, DI
+	CALL	x_cgo_getstackbound_trampoline(SB)
+	MOVQ	0(SP), AX
+	MOVQ	AX, 0(R12) // g->stacklo
+	ADDQ	$16, SP
+	POPQ	R12
+	POPQ	BX
+	POPQ	BP
+	RET
+
+// void x_cgo_getstackbound(uintptr bounds[2])
+TEXT x_cgo_getstackbound_trampoline(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	PUSHQ	BX
+	PUSHQ	R12
+	MOVQ	DI, BX
+	// 0(SP) addr, 8(SP) size, 16(SP) pthread_attr_t
+	SUBQ	$80, SP
+	LEAQ	16(SP), DI
+	CALL	fakecgo_pthread_attr_init(SB)
+	CALL	fakecgo_pthread_self(SB)
+	MOVQ	AX, DI
+	LEAQ	16(SP), SI
+	CALL	fakecgo_pthread_getattr_np(SB)
+	LEAQ	16(SP), DI
+	LEAQ	0(SP), SI
+	LEAQ	8(SP), DX
+	CALL	fakecgo_pthread_attr_getstack(SB)
+	LEAQ	16(SP), DI
+	CALL	fakecgo_pthread_attr_destroy(SB)
+	MOVQ	0(SP), AX
+	MOVQ	AX, 0(BX)
+	ADDQ	8(SP), AX
+	MOVQ	AX, 8(BX)
+	ADDQ	$80, SP
+	POPQ	R12
+	POPQ	BX
+	POPQ	BP
+	RET
+
+// void x_cgo_thread_start(ThreadStart *ts), where ThreadStart is
+// struct { G *g; uintptr *tls; void (*fn)(void); }.
+TEXT x_cgo_thread_start_trampoline(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	PUSHQ	BX
+	PUSHQ	R12
+	// 0(SP) ign sigset_t, 128(SP) old sigset_t,
+	// 256(SP) pthread_attr_t, 320(SP) pthread_t, 328(SP) size
+	SUBQ	$336, SP
+	MOVQ	DI, R12
+	MOVQ	$24, DI
+	CALL	fakecgo_malloc(SB)
+	TESTQ	AX, AX
+	JZ	fail
+	MOVQ	0(R12), CX
+	MOVQ	CX, 0(AX)
+	MOVQ	8(R12), CX
+	MOVQ	CX, 8(AX)
+	MOVQ	16(R12), CX
+	MOVQ	CX, 16(AX)
+	MOVQ	AX, R12
+
+	// block all signals on the new thread until the runtime sets it up.
+	LEAQ	0(SP), DI
+	CALL	fakecgo_sigfillset(SB)
+	MOVL	$2, DI // SIG_SETMASK
+	LEAQ	0(SP), SI
+	LEAQ	128(SP), DX
+	CALL	fakecgo_pthread_sigmask(SB)
+
+	LEAQ	256(SP), DI
+	CALL	fakecgo_pthread_attr_init(SB)
+	LEAQ	256(SP), DI
+	MOVL	$1, SI // PTHREAD_CREATE_DETACHED
+	CALL	fakecgo_pthread_attr_setdetachstate(SB)
+	LEAQ	256(SP), DI
+	LEAQ	328(SP), SI
+	CALL	fakecgo_pthread_attr_getstacksize(SB)
+	MOVQ	0(R12), AX
+	MOVQ	328(SP), CX
+	MOVQ	CX, 8(AX) // g->stackhi = size
+
+	LEAQ	320(SP), DI
+	LEAQ	256(SP), SI
+	LEAQ	threadentry<>(SB), DX
+	MOVQ	R12, CX
+	CALL	fakecgo_pthread_create(SB)
+	MOVQ	AX, BX
+
+	MOVL	$2, DI // SIG_SETMASK
+	LEAQ	128(SP), SI
+	XORL	DX, DX
+	CALL	fakecgo_pthread_sigmask(SB)
+	LEAQ	256(SP), DI
+	CALL	fakecgo_pthread_attr_destroy(SB)
+	TESTQ	BX, BX
+	JNZ	fail
+
+	ADDQ	$336, SP
+	POPQ	R12
+	POPQ	BX
+	POPQ	BP
+	RET
+fail:
+	CALL	fakecgo_abort(SB)
+	RET
+
+// void *threadentry(ThreadStart *ts)
+TEXT threadentry<>(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	PUSHQ	BX
+	PUSHQ	R12
+	PUSHQ	R13
+	PUSHQ	R14
+	PUSHQ	R15
+	SUBQ	$8, SP
+	MOVQ	0(DI), R12  // g
+	MOVQ	16(DI), R13 // fn
+	CALL	fakecgo_free(SB)
+	MOVQ	R12, DI
+	MOVQ	setg_gcc<>(SB), AX
+	CALL	AX
+	CALL	R13
+	ADDQ	$8, SP
+	POPQ	R15
+	POPQ	R14
+	POPQ	R13
+	POPQ	R12
+	POPQ	BX
+	POPQ	BP
+	XORL	AX, AX
+	RET
+
+// void x_cgo_notify_runtime_init_done(void), C threads are not
+// able to call into Go before the runtime is initialized.
+TEXT x_cgo_notify_runtime_init_done_trampoline(SB), NOSPLIT|NOFRAME, $0-0
+	RET
+
+// void x_cgo_setenv(char **arg)
+TEXT x_cgo_setenv_trampoline(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	MOVQ	8(DI), SI
+	MOVQ	0(DI), DI
+	MOVL	$1, DX
+	CALL	fakecgo_setenv(SB)
+	POPQ	BP
+	RET
+
+// void x_cgo_unsetenv(char **arg)
+TEXT x_cgo_unsetenv_trampoline(SB), NOSPLIT|NOFRAME, $0-0
+	PUSHQ	BP
+	MOVQ	SP, BP
+	MOVQ	0(DI), DI
+	CALL	fakecgo_unsetenv(SB)
+	POPQ	BP
+	RET
@@ -0,0 +1,73 @@
+//go:build !cgo && linux && amd64
+
+// Package fakecgo stands in for runtime/cgo when building without cgo,
+// such that C libraries can be loaded with dlopen and called into. It
+// links the program dynamically against libc and provides the hooks
+// that the runtime calls to create threads with pthreads, so that C
+// code finds a valid thread local storage on every thread.
+package fakecgo
+
+import _ "unsafe"
+
+//go:cgo_import_dynamic fakecgo_malloc malloc "libc.so.6"
+//go:cgo_import_dynamic fakecgo_free free "libc.so.6"
+//go:cgo_import_dynamic fakecgo_abort abort "libc.so.6"
+//go:cgo_import_dynamic fakecgo_setenv setenv "libc.so.6"
+//go:cgo_import_dynamic fakecgo_unsetenv unsetenv "libc.so.6"
+//go:cgo_import_dynamic fakecgo_sigfillset sigfillset "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_self pthread_self "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_create pthread_create "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_sigmask pthread_sigmask "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_getattr_np pthread_getattr_np "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_attr_init pthread_attr_init "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_attr_destroy pthread_attr_destroy "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_attr_getstack pthread_attr_getstack "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_attr_getstacksize pthread_attr_getstacksize "libc.so.6"
+//go:cgo_import_dynamic fakecgo_pthread_attr_setdetachstate pthread_attr_setdetachstate "libc.so.6"
+//go:cgo_import_dynamic _ _ "libc.so.6"
+
+//go:linkname _iscgo runtime.iscgo
+var _iscgo = true
+
+//go:linkname _set_crosscall2 runtime.set_crosscall2
+var _set_crosscall2 = set_crosscall2
+
+// set_crosscall2 is a no-op, as there is no pthread key destructor.
+func set_crosscall2() {}
+
+//go:linkname x_cgo_init_trampoline x_cgo_init_trampoline
+//go:linkname _cgo_init _cgo_init
+var x_cgo_init_trampoline byte
+var _cgo_init = &x_cgo_init_trampoline
+
+//go:linkname x_cgo_thread_start_trampoline x_cgo_thread_start_trampoline
+//go:linkname _cgo_thread_start _cgo_thread_start
+var x_cgo_thread_start_trampoline byte
+var _cgo_thread_start = &x_cgo_thread_start_trampoline
+
+//go:linkname x_cgo_notify_runtime_init_done_trampoline x_cgo_notify_runtime_init_done_trampoline
+//go:linkname _cgo_notify_runtime_init_done _cgo_notify_runtime_init_done
+var x_cgo_notify_runtime_init_done_trampoline byte
+var _cgo_notify_runtime_init_done = &x_cgo_notify_runtime_init_done_trampoline
+
+//go:linkname x_cgo_getstackbound_trampoline x_cgo_getstackbound_trampoline
+//go:linkname _cgo_getstackbound _cgo_getstackbound
+var x_cgo_getstackbound_trampoline byte
+var _cgo_getstackbound = &x_cgo_getstackbound_trampoline
+
+//go:linkname x_cgo_setenv_trampoline x_cgo_setenv_trampoline
+//go:linkname _cgo_setenv runtime._cgo_setenv
+var x_cgo_setenv_trampoline byte
+var _cgo_setenv = &x_cgo_setenv_trampoline
+
+//go:linkname x_cgo_unsetenv_trampoline x_cgo_unsetenv_trampoline
+//go:linkname _cgo_unsetenv runtime._cgo_unsetenv
+var x_cgo_unsetenv_trampoline byte
+var _cgo_unsetenv = &x_cgo_unsetenv_trampoline
+
+// x_cgo_pthread_key_created stays zero, so that the runtime
+// releases the M of a C thread after each callback.
+var x_cgo_pthread_key_created uintptr
+
+//go:linkname _cgo_pthread_key_created _cgo_pthread_key_created
+var _cgo_pthread_key_created = &x_cgo_pthread_key_created
@@ -68,7 +68,7 @@ var AudioStreams struct {
 var AudioDevices struct {
 	Lib
 
-	Default func(*abi.String, *AudioSpec, abi.Int) abi.Error `ffi:"SDL_GetDefaultAudioInfo"` // Get the ID of a built-in audio device that is the "best" fit for the desired device specification.
+	Default func(iscapture abi.Int) (abi.Error, abi.String, AudioSpec) `ffi:"SDL_GetDefaultAudioInfo,out=0,1" since:"2.24"` // Get the ID of a built-in audio device that is the "best" fit for the desired device specification.
 
 	Open   func(AudioDeviceName, abi.Int, *AudioSpec, *AudioSpec, AudioAllowedChanges) (abi.Error, AudioDevice) `ffi:"SDL_OpenAudioDevice"`      // Open a specific audio device.
 	Count  func(abi.Int) AudioDeviceIndex                                                                       `ffi:"SDL_GetNumAudioDevices"`   // Get the number of available devices exposed by the current driver.
@@ -80,10 +80,10 @@ var AudioDevices struct {
 	Unlock func(AudioDevice)                                                                                    `ffi:"SDL_UnlockAudioDevice"`    // Unlock the audio device mutex.
 	Close  func(AudioDevice)                                                                                    `ffi:"SDL_CloseAudioDevice"`     // Close a specific audio device.
 
-	Queue      func(device AudioDevice, data abi.Pointer[abi.Uint8], len abi.Uint32) abi.Error `ffi:"SDL_QueueAudio"`         // Queue more audio to playback on a specific device.
-	Dequeue    func(device AudioDevice, data abi.Pointer[abi.Uint8], len abi.Uint32) abi.Error `ffi:"SDL_DequeueAudio"`       // Dequeue more audio for playback on a specific device.
-	QueuedSuze func(device AudioDevice) abi.Uint32                                             `ffi:"SDL_GetQueuedAudioSize"` // Get the number of bytes of still-queued audio.
-	ClearQueue func(device AudioDevice)                                                        `ffi:"SDL_ClearQueuedAudio"`   // Drop any queued audio data.
+	Queue      func(device AudioDevice, data []byte) abi.Error `ffi:"SDL_QueueAudio,len=uint32"`   // Queue more audio to playback on a specific device.
+	Dequeue    func(device AudioDevice, data []byte) abi.Error `ffi:"SDL_DequeueAudio,len=uint32"` // Dequeue more audio for playback on a specific device.
+	QueuedSuze func(device AudioDevice) abi.Uint32             `ffi:"SDL_GetQueuedAudioSize"`      // Get the number of bytes of still-queued audio.
+	ClearQueue func(device AudioDevice)                        `ffi:"SDL_ClearQueuedAudio"`        // Drop any queued audio data.
 }
 
 type AudioDriver string
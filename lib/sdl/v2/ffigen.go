@@ -0,0 +1,498 @@
+// Code generated by ffigen. DO NOT EDIT.
+
+//go:build ffistatic
+
+package sdl
+
+import (
+	"qlova.tech/abi"
+	"qlova.tech/ffi"
+	"unsafe"
+)
+
+func init() {
+	ffi.Static(&Atomics, map[string]func(symbol unsafe.Pointer){
+		"TryLock": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, Bool](symbol)
+			Atomics.TryLock = func(a0 *SpinLock) Bool {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"Lock": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			Atomics.Lock = func(a0 *SpinLock) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+		"Unlock": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			Atomics.Unlock = func(a0 *SpinLock) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+		"CompareAndSwap": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, abi.Int, abi.Int, Bool](symbol)
+			Atomics.CompareAndSwap = func(a0 *AtomicInt, a1 abi.Int, a2 abi.Int) Bool {
+				return call(unsafe.Pointer(a0), a1, a2)
+			}
+		},
+		"Set": func(symbol unsafe.Pointer) {
+			call := ffi.Proc2[unsafe.Pointer, abi.Int](symbol)
+			Atomics.Set = func(a0 *AtomicInt, a1 abi.Int) {
+				call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"Get": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Atomics.Get = func(a0 *AtomicInt) abi.Int {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"Add": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.Int, abi.Int](symbol)
+			Atomics.Add = func(a0 *AtomicInt, a1 abi.Int) abi.Int {
+				return call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"CompareAndSwapPointer": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, abi.UnsafePointer, abi.UnsafePointer, Bool](symbol)
+			Atomics.CompareAndSwapPointer = func(a0 *abi.AtomicUintptr, a1 abi.UnsafePointer, a2 abi.UnsafePointer) Bool {
+				return call(unsafe.Pointer(a0), a1, a2)
+			}
+		},
+		"SetPointer": func(symbol unsafe.Pointer) {
+			call := ffi.Proc2[unsafe.Pointer, abi.UnsafePointer](symbol)
+			Atomics.SetPointer = func(a0 *abi.AtomicUintptr, a1 abi.UnsafePointer) {
+				call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"GetPointer": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.UnsafePointer](symbol)
+			Atomics.GetPointer = func(a0 *abi.AtomicUintptr) abi.UnsafePointer {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+	})
+}
+
+// Audio is linked by reflection for:
+//
+//   - Open: more than one result
+//   - FreeWAV: parameter 0: abi.Buffer is passed by value
+//   - BuildCVT: more than 6 parameters
+func init() {
+	ffi.Static(&Audio, map[string]func(symbol unsafe.Pointer){
+		"Init": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			Audio.Init = func(a0 AudioDriver) abi.Error {
+				return call(abi.NewString(string(a0)).Pointer())
+			}
+		},
+		"Quit": func(symbol unsafe.Pointer) {
+			Audio.Quit = ffi.Func0[abi.Error](symbol)
+		},
+		"DriverCount": func(symbol unsafe.Pointer) {
+			Audio.DriverCount = ffi.Func0[abi.Int](symbol)
+		},
+		"DriverIndex": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[abi.Int, unsafe.Pointer](symbol)
+			Audio.DriverIndex = func(a0 abi.Int) AudioDriver {
+				r := call(a0)
+				return AudioDriver((*(*abi.String)(unsafe.Pointer(&r))).String())
+			}
+		},
+		"Driver": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			Audio.Driver = func() AudioDriver {
+				r := call()
+				return AudioDriver((*(*abi.String)(unsafe.Pointer(&r))).String())
+			}
+		},
+		"Status": func(symbol unsafe.Pointer) {
+			Audio.Status = ffi.Func0[AudioStatus](symbol)
+		},
+		"Pause": func(symbol unsafe.Pointer) {
+			Audio.Pause = ffi.Proc1[Bool](symbol)
+		},
+		"LoadWAV": func(symbol unsafe.Pointer) {
+			call := ffi.Proc4[unsafe.Pointer, abi.Int, unsafe.Pointer, unsafe.Pointer](symbol)
+			Audio.LoadWAV = func(a0 *File, a1 abi.Int, a2 *AudioSpec, a3 *abi.Buffer) {
+				call(unsafe.Pointer(a0), a1, unsafe.Pointer(a2), unsafe.Pointer(a3))
+			}
+		},
+		"Convert": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			Audio.Convert = func(a0 *AudioCVT) abi.Error {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"Mix": func(symbol unsafe.Pointer) {
+			call := ffi.Proc4[unsafe.Pointer, unsafe.Pointer, abi.Uint32, abi.Int](symbol)
+			Audio.Mix = func(a0 *abi.Uint8, a1 *abi.Uint8, a2 abi.Uint32, a3 abi.Int) {
+				call(unsafe.Pointer(a0), unsafe.Pointer(a1), a2, a3)
+			}
+		},
+		"MixFormat": func(symbol unsafe.Pointer) {
+			call := ffi.Proc5[unsafe.Pointer, unsafe.Pointer, AudioFormat, abi.Uint32, abi.Int](symbol)
+			Audio.MixFormat = func(a0 *abi.Uint8, a1 *abi.Uint8, a2 AudioFormat, a3 abi.Uint32, a4 abi.Int) {
+				call(unsafe.Pointer(a0), unsafe.Pointer(a1), a2, a3, a4)
+			}
+		},
+		"Lock": func(symbol unsafe.Pointer) {
+			Audio.Lock = ffi.Proc0(symbol)
+		},
+		"Unlock": func(symbol unsafe.Pointer) {
+			Audio.Unlock = ffi.Proc0(symbol)
+		},
+		"Close": func(symbol unsafe.Pointer) {
+			Audio.Close = ffi.Proc0(symbol)
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&AudioStreams, map[string]func(symbol unsafe.Pointer){
+		"New": func(symbol unsafe.Pointer) {
+			call := ffi.FuncErrno6[AudioFormat, abi.Uint8, abi.Int, AudioFormat, abi.Uint8, abi.Int, unsafe.Pointer](symbol)
+			AudioStreams.New = func(a0 AudioFormat, a1 abi.Uint8, a2 abi.Int, a3 AudioFormat, a4 abi.Uint8, a5 abi.Int) (AudioStream, error) {
+				r, errno := call(a0, a1, a2, a3, a4, a5)
+				if r == nil {
+					return *(*AudioStream)(unsafe.Pointer(&r)), ffi.Failure(nil, errno, "SDL_NewAudioStream")
+				}
+				return *(*AudioStream)(unsafe.Pointer(&r)), nil
+			}
+		},
+		"Put": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.Error](symbol)
+			AudioStreams.Put = func(a0 AudioStream, a1 abi.Pointer[abi.Uint8], a2 abi.Int) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)), a2)
+			}
+		},
+		"Get": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			AudioStreams.Get = func(a0 AudioStream) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Flush": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			AudioStreams.Flush = func(a0 AudioStream) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Clear": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			AudioStreams.Clear = func(a0 AudioStream) {
+				call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Free": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			AudioStreams.Free = func(a0 AudioStream) {
+				call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+	})
+}
+
+// AudioDevices is linked by reflection for:
+//
+//   - Default: out= positions
+//   - Open: more than one result
+//   - Spec: more than one result
+//   - Queue: parameter 1: unsupported type []byte
+//   - Dequeue: parameter 1: unsupported type []byte
+func init() {
+	ffi.Static(&AudioDevices, map[string]func(symbol unsafe.Pointer){
+		"Count": func(symbol unsafe.Pointer) {
+			AudioDevices.Count = ffi.Func1[abi.Int, AudioDeviceIndex](symbol)
+		},
+		"Name": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[AudioDeviceIndex, abi.Int, unsafe.Pointer](symbol)
+			AudioDevices.Name = func(a0 AudioDeviceIndex, a1 abi.Int) AudioDeviceName {
+				r := call(a0, a1)
+				return AudioDeviceName((*(*abi.String)(unsafe.Pointer(&r))).String())
+			}
+		},
+		"Pause": func(symbol unsafe.Pointer) {
+			AudioDevices.Pause = ffi.Proc2[AudioDevice, Bool](symbol)
+		},
+		"Status": func(symbol unsafe.Pointer) {
+			AudioDevices.Status = ffi.Func1[AudioDevice, AudioStatus](symbol)
+		},
+		"Lock": func(symbol unsafe.Pointer) {
+			AudioDevices.Lock = ffi.Proc1[AudioDevice](symbol)
+		},
+		"Unlock": func(symbol unsafe.Pointer) {
+			AudioDevices.Unlock = ffi.Proc1[AudioDevice](symbol)
+		},
+		"Close": func(symbol unsafe.Pointer) {
+			AudioDevices.Close = ffi.Proc1[AudioDevice](symbol)
+		},
+		"QueuedSuze": func(symbol unsafe.Pointer) {
+			AudioDevices.QueuedSuze = ffi.Func1[AudioDevice, abi.Uint32](symbol)
+		},
+		"ClearQueue": func(symbol unsafe.Pointer) {
+			AudioDevices.ClearQueue = ffi.Proc1[AudioDevice](symbol)
+		},
+	})
+}
+
+// Hints is linked by reflection for:
+//
+//   - AddHintCallback: parameter 0: HintCallback is a callback
+//   - DelHintCallback: parameter 0: HintCallback is a callback
+func init() {
+	ffi.Static(&Hints, map[string]func(symbol unsafe.Pointer){
+		"ClearHints": func(symbol unsafe.Pointer) {
+			Hints.ClearHints = ffi.Proc0(symbol)
+		},
+		"GetHint": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, unsafe.Pointer](symbol)
+			Hints.GetHint = func(a0 Hint) abi.String {
+				r := call(abi.NewString(string(a0)).Pointer())
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+		"GetHintBoolean": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, Bool, Bool](symbol)
+			Hints.GetHintBoolean = func(a0 Hint, a1 Bool) Bool {
+				return call(abi.NewString(string(a0)).Pointer(), a1)
+			}
+		},
+		"ResetHint": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, Bool](symbol)
+			Hints.ResetHint = func(a0 Hint) Bool {
+				return call(abi.NewString(string(a0)).Pointer())
+			}
+		},
+		"ResetHints": func(symbol unsafe.Pointer) {
+			Hints.ResetHints = ffi.Proc0(symbol)
+		},
+		"SetHint": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, Bool](symbol)
+			Hints.SetHint = func(a0 Hint, a1 string) Bool {
+				return call(abi.NewString(string(a0)).Pointer(), abi.NewString(string(a1)).Pointer())
+			}
+		},
+		"SetHintWithPriority": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, HintPriority, Bool](symbol)
+			Hints.SetHintWithPriority = func(a0 Hint, a1 string, a2 HintPriority) Bool {
+				return call(abi.NewString(string(a0)).Pointer(), abi.NewString(string(a1)).Pointer(), a2)
+			}
+		},
+	})
+}
+
+// Log is linked by reflection for:
+//
+//   - Printf: variadic
+//   - Message: variadic
+//   - Verbose: variadic
+//   - Debug: variadic
+//   - Info: variadic
+//   - Warn: variadic
+//   - Error: variadic
+//   - Critical: variadic
+func init() {
+	ffi.Static(&Log, map[string]func(symbol unsafe.Pointer){
+		"SetAllPriority": func(symbol unsafe.Pointer) {
+			Log.SetAllPriority = ffi.Proc1[LogPriority](symbol)
+		},
+		"SetPriority": func(symbol unsafe.Pointer) {
+			Log.SetPriority = ffi.Proc2[LogCategory, LogPriority](symbol)
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&version, map[string]func(symbol unsafe.Pointer){
+		"Get": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			version.Get = func(a0 *Version) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&System, map[string]func(symbol unsafe.Pointer){
+		"Init": func(symbol unsafe.Pointer) {
+			System.Init = ffi.Func1[Module, abi.Error](symbol)
+		},
+		"Stop": func(symbol unsafe.Pointer) {
+			System.Stop = ffi.Proc1[Module](symbol)
+		},
+		"Loaded": func(symbol unsafe.Pointer) {
+			System.Loaded = ffi.Func1[Module, Module](symbol)
+		},
+		"Quit": func(symbol unsafe.Pointer) {
+			System.Quit = ffi.Proc0(symbol)
+		},
+		"Revision": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			System.Revision = func() string {
+				r := call()
+				return string((*(*abi.String)(unsafe.Pointer(&r))).String())
+			}
+		},
+		"Version": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			System.Version = func(a0 *Version) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+		"DefaultAssertionHandler": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			System.DefaultAssertionHandler = func() AssertionHandler {
+				r := call()
+				return *(*AssertionHandler)(unsafe.Pointer(&r))
+			}
+		},
+		"SetAssertionHandler": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			System.SetAssertionHandler = func(a0 AssertionHandler) {
+				call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"GetAssertionHandler": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, unsafe.Pointer](symbol)
+			System.GetAssertionHandler = func(a0 *abi.UnsafePointer) AssertionHandler {
+				r := call(unsafe.Pointer(a0))
+				return *(*AssertionHandler)(unsafe.Pointer(&r))
+			}
+		},
+		"GetAssertionReport": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			System.GetAssertionReport = func() *AssertionData {
+				r := call()
+				return (*AssertionData)(r)
+			}
+		},
+		"ResetAssertionReport": func(symbol unsafe.Pointer) {
+			System.ResetAssertionReport = ffi.Proc0(symbol)
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Windows, map[string]func(symbol unsafe.Pointer){
+		"Error": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			Windows.Error = func() string {
+				r := call()
+				return string((*(*abi.String)(unsafe.Pointer(&r))).String())
+			}
+		},
+		"Create": func(symbol unsafe.Pointer) {
+			call := ffi.FuncErrno6[unsafe.Pointer, abi.Int, abi.Int, abi.Int, abi.Int, WindowFlags, unsafe.Pointer](symbol)
+			Windows.Create = func(a0 string, a1 abi.Int, a2 abi.Int, a3 abi.Int, a4 abi.Int, a5 WindowFlags) (Window, error) {
+				r, errno := call(abi.NewString(string(a0)).Pointer(), a1, a2, a3, a4, a5)
+				if r == nil {
+					return *(*Window)(unsafe.Pointer(&r)), ffi.Failure(Windows.Error, errno, "SDL_CreateWindow")
+				}
+				return *(*Window)(unsafe.Pointer(&r)), nil
+			}
+		},
+		"GetSurface": func(symbol unsafe.Pointer) {
+			call := ffi.FuncErrno1[unsafe.Pointer, unsafe.Pointer](symbol)
+			Windows.GetSurface = func(a0 Window) (Surface, error) {
+				r, errno := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+				if r == nil {
+					return *(*Surface)(unsafe.Pointer(&r)), ffi.Failure(Windows.Error, errno, "SDL_GetWindowSurface")
+				}
+				return *(*Surface)(unsafe.Pointer(&r)), nil
+			}
+		},
+		"UpdateSurface": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			Windows.UpdateSurface = func(a0 Window) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Destroy": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			Windows.Destroy = func(a0 Window) {
+				call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Draw, map[string]func(symbol unsafe.Pointer){
+		"FilledRect": func(symbol unsafe.Pointer) {
+			call := ffi.Proc3[unsafe.Pointer, unsafe.Pointer, Color](symbol)
+			Draw.FilledRect = func(a0 Surface, a1 *Rect, a2 Color) {
+				call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Timer, map[string]func(symbol unsafe.Pointer){
+		"Delay": func(symbol unsafe.Pointer) {
+			Timer.Delay = ffi.Proc1[abi.Uint32](symbol)
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Events, map[string]func(symbol unsafe.Pointer){
+		"Poll": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Events.Poll = func(a0 *Event) abi.Int {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+	})
+}
+
+// Errors is linked by reflection for:
+//
+//   - SetError: variadic
+func init() {
+	ffi.Static(&Errors, map[string]func(symbol unsafe.Pointer){
+		"Clear": func(symbol unsafe.Pointer) {
+			Errors.Clear = ffi.Proc0(symbol)
+		},
+		"Get": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			Errors.Get = func() string {
+				r := call()
+				return string((*(*abi.String)(unsafe.Pointer(&r))).String())
+			}
+		},
+		"GetErrorMessage": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.Int, unsafe.Pointer](symbol)
+			Errors.GetErrorMessage = func(a0 abi.String, a1 abi.Int) abi.String {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1)
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Video, map[string]func(symbol unsafe.Pointer){
+		"GetRenderDrawBlendMode": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Error](symbol)
+			Video.GetRenderDrawBlendMode = func(a0 Renderer, a1 *BlendMode) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1))
+			}
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Surfaces, map[string]func(symbol unsafe.Pointer){
+		"GetBlendMode": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Error](symbol)
+			Surfaces.GetBlendMode = func(a0 Surface, a1 *BlendMode) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1))
+			}
+		},
+	})
+}
@@ -41,17 +41,17 @@ const (
 )
 
 var Hints struct {
-	ffi.Library `linux:"libSDL2-2.0.so.0" darwin:"libSDL2.dylib"`
-
-	AddHintCallback     func(Hint, callback HintCallback, userdata Userdata) `ffi:"SDL_AddHintCallback"`     // AddHintCallback adds a function to watch a particular hint.
-	ClearHints          func()                                               `ffi:"SDL_ClearHints"`          // ClearHints clears all hints.
-	DelHintCallback     func(Hint, callback HintCallback, userdata Userdata) `ffi:"SDL_DelHintCallback"`     // DelHintCallback removes a function watching a particular hint.
-	GetHint             func(Hint) abi.String                                `ffi:"SDL_GetHint"`             // GetHint gets the value of a hint.
-	GetHintBoolean      func(hint Hint, defaultVal Bool) Bool                `ffi:"SDL_GetHintBoolean"`      // GetHintBoolean gets the value of a hint as a boolean.
-	ResetHint           func(Hint) Bool                                      `ffi:"SDL_ResetHint"`           // ResetHint resets a hint to its default value.
-	ResetHints          func()                                               `ffi:"SDL_ResetHints"`          // ResetHints resets all hints to their default values.
-	SetHint             func(Hint, string) Bool                              `ffi:"SDL_SetHint"`             // SetHint sets the value of a hint.
-	SetHintWithPriority func(Hint, string, HintPriority) Bool                `ffi:"SDL_SetHintWithPriority"` // SetHintWithPriority sets a hint with a specific priority.
+	ffi.Library `linux:"libSDL2-2.0.so.0,libSDL2.so" darwin:"libSDL2-2.0.0.dylib,libSDL2.dylib"`
+
+	AddHintCallback     func(Hint, callback HintCallback, userdata Userdata) `ffi:"SDL_AddHintCallback,keep"` // AddHintCallback adds a function to watch a particular hint.
+	ClearHints          func()                                               `ffi:"SDL_ClearHints"`           // ClearHints clears all hints.
+	DelHintCallback     func(Hint, callback HintCallback, userdata Userdata) `ffi:"SDL_DelHintCallback,keep"` // DelHintCallback removes a function watching a particular hint.
+	GetHint             func(Hint) abi.String                                `ffi:"SDL_GetHint"`              // GetHint gets the value of a hint.
+	GetHintBoolean      func(hint Hint, defaultVal Bool) Bool                `ffi:"SDL_GetHintBoolean"`       // GetHintBoolean gets the value of a hint as a boolean.
+	ResetHint           func(Hint) Bool                                      `ffi:"SDL_ResetHint"`            // ResetHint resets a hint to its default value.
+	ResetHints          func()                                               `ffi:"SDL_ResetHints"`           // ResetHints resets all hints to their default values.
+	SetHint             func(Hint, string) Bool                              `ffi:"SDL_SetHint"`              // SetHint sets the value of a hint.
+	SetHintWithPriority func(Hint, string, HintPriority) Bool                `ffi:"SDL_SetHintWithPriority"`  // SetHintWithPriority sets a hint with a specific priority.
 }
 
 const (
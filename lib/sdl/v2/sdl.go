@@ -23,7 +23,10 @@
 
 package sdl
 
+//go:generate go run qlova.tech/cmd/ffigen
+
 import (
+	"fmt"
 	"unsafe"
 
 	"qlova.tech/abi"
@@ -31,7 +34,40 @@ import (
 )
 
 type Lib struct {
-	ffi.Library `linux:"libSDL2-2.0.so.0" darwin:"libSDL2.dylib"`
+	ffi.Library `linux:"libSDL2-2.0.so.0,libSDL2.so" darwin:"libSDL2-2.0.0.dylib,libSDL2.dylib"`
+}
+
+// MainLib is for the parts of SDL that must be called on the main
+// thread, such as video and events, see [ffi.Main].
+type MainLib struct {
+	ffi.Library `linux:"libSDL2-2.0.so.0,libSDL2.so" darwin:"libSDL2-2.0.0.dylib,libSDL2.dylib" thread:"main"`
+}
+
+func init() {
+	// MainLib is called from the main goroutine.
+	ffi.LockMainThread()
+}
+
+// LibraryVersion implements [ffi.Versioned].
+func (Lib) LibraryVersion() string { return libraryVersion() }
+
+// LibraryVersion implements [ffi.Versioned].
+func (MainLib) LibraryVersion() string { return libraryVersion() }
+
+// version of SDL, bound on a [Lib] rather than through [System], so
+// that the version-gated funcs of libraries that can be called from
+// any thread, such as audio callbacks, do not need [ffi.Main] to query
+// it. SDL_GetVersion is thread-safe.
+var version struct {
+	Lib
+
+	Get func(*Version) `ffi:"SDL_GetVersion"`
+}
+
+func libraryVersion() string {
+	var v Version
+	version.Get(&v)
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
 }
 
 func Link() error {
@@ -49,6 +85,7 @@ func Link() error {
 		&Video,
 		&Log,
 		&Surfaces,
+		&version,
 	)
 }
 
@@ -69,7 +106,7 @@ const (
 )
 
 var System struct {
-	Lib
+	MainLib
 
 	/*
 		Init initialize the SDL library.
@@ -169,7 +206,7 @@ const (
 )
 
 var Windows struct {
-	Lib
+	MainLib
 
 	Error func() string `ffi:"SDL_GetError"`
 
@@ -189,7 +226,7 @@ type Rect struct {
 }
 
 var Draw struct {
-	Lib
+	MainLib
 
 	FilledRect func(Surface, *Rect, Color) `ffi:"SDL_FillRect"`
 }
@@ -245,16 +282,16 @@ type Quit struct {
 }
 
 var Events struct {
-	Lib
+	MainLib
 
 	Poll func(*Event) abi.Int `ffi:"SDL_PollEvent"`
 }
 
 var Errors struct {
-	Lib
+	MainLib
 
-	Clear           func()                                           `ffi:"SDL_ClearError"`
-	Get             func() string                                    `ffi:"SDL_GetError"`
-	GetErrorMessage func(abi.String, abi.Int) abi.String             `ffi:"SDL_GetErrorMessage"`
-	SetError        func(abi.String, ...abi.UnsafePointer) abi.Error `ffi:"SDL_SetError"`
+	Clear           func()                               `ffi:"SDL_ClearError"`
+	Get             func() string                        `ffi:"SDL_GetError"`
+	GetErrorMessage func(abi.String, abi.Int) abi.String `ffi:"SDL_GetErrorMessage"`
+	SetError        func(string, ...any) abi.Error       `ffi:"SDL_SetError"`
 }
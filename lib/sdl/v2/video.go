@@ -48,13 +48,13 @@ type DisplayMode struct {
 type Renderer abi.Opaque[Renderer]
 
 var Video struct {
-	Lib
+	MainLib
 
 	GetRenderDrawBlendMode func(Renderer, *BlendMode) abi.Error `ffi:"SDL_GetRenderDrawBlendMode"`
 }
 
 var Surfaces struct {
-	Lib
+	MainLib
 
 	GetBlendMode func(Surface, *BlendMode) abi.Error `ffi:"SDL_GetSurfaceBlendMode"`
 }
@@ -67,29 +67,29 @@ var ComplexFloat struct {
 var ComplexDoubleLong struct {
 	LibM
 
-	Real func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"creall"`
-	Imag func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"cimagl"`
-	Abs  func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"cabsl"`
-	Arg  func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"cargl"`
-	Conj func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"conjl"`
-	Proj func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cprojl"`
-
-	Exp func(abi.ComplexDoubleLong) abi.ComplexDoubleLong                        `ffi:"cexpl"`
-	Log func(abi.ComplexDoubleLong) abi.ComplexDoubleLong                        `ffi:"clogl"`
-	Pow func(abi.ComplexDoubleLong, abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cpowl"`
-
-	Sqrt func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"csqrtl"`
-	Sin  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"csinl"`
-	Cos  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ccosl"`
-	Tan  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ctanl"`
-	Asin func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"casinl"`
-	Acos func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cacosl"`
-	Atan func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"catanl"`
-
-	Sinh  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"csinhl"`
-	Cosh  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ccoshl"`
-	Tanh  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ctanhl"`
-	Asinh func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"casinhl"`
-	Acosh func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cacoshl"`
-	Atanh func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"catanhl"`
+	Real func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"creall" ffigen:"-"`
+	Imag func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"cimagl" ffigen:"-"`
+	Abs  func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"cabsl" ffigen:"-"`
+	Arg  func(abi.ComplexDoubleLong) abi.DoubleLong        `ffi:"cargl" ffigen:"-"`
+	Conj func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"conjl" ffigen:"-"`
+	Proj func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cprojl" ffigen:"-"`
+
+	Exp func(abi.ComplexDoubleLong) abi.ComplexDoubleLong                        `ffi:"cexpl" ffigen:"-"`
+	Log func(abi.ComplexDoubleLong) abi.ComplexDoubleLong                        `ffi:"clogl" ffigen:"-"`
+	Pow func(abi.ComplexDoubleLong, abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cpowl" ffigen:"-"`
+
+	Sqrt func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"csqrtl" ffigen:"-"`
+	Sin  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"csinl" ffigen:"-"`
+	Cos  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ccosl" ffigen:"-"`
+	Tan  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ctanl" ffigen:"-"`
+	Asin func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"casinl" ffigen:"-"`
+	Acos func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cacosl" ffigen:"-"`
+	Atan func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"catanl" ffigen:"-"`
+
+	Sinh  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"csinhl" ffigen:"-"`
+	Cosh  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ccoshl" ffigen:"-"`
+	Tanh  func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"ctanhl" ffigen:"-"`
+	Asinh func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"casinhl" ffigen:"-"`
+	Acosh func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"cacoshl" ffigen:"-"`
+	Atanh func(abi.ComplexDoubleLong) abi.ComplexDoubleLong `ffi:"catanhl" ffigen:"-"`
 }
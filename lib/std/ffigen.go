@@ -0,0 +1,1240 @@
+// Code generated by ffigen. DO NOT EDIT.
+
+//go:build ffistatic
+
+package std
+
+import (
+	"qlova.tech/abi"
+	"qlova.tech/ffi"
+	"unsafe"
+)
+
+// Complex is linked by reflection for:
+//
+//   - Real: parameter 0: abi.ComplexDouble is an array
+//   - Imag: parameter 0: abi.ComplexDouble is an array
+//   - Abs: parameter 0: abi.ComplexDouble is an array
+//   - Arg: parameter 0: abi.ComplexDouble is an array
+//   - Conj: parameter 0: abi.ComplexDouble is an array
+//   - Proj: parameter 0: abi.ComplexDouble is an array
+//   - Exp: parameter 0: abi.ComplexDouble is an array
+//   - Log: parameter 0: abi.ComplexDouble is an array
+//   - Pow: parameter 0: abi.ComplexDouble is an array
+//   - Sqrt: parameter 0: abi.ComplexDouble is an array
+//   - Sin: parameter 0: abi.ComplexDouble is an array
+//   - Cos: parameter 0: abi.ComplexDouble is an array
+//   - Tan: parameter 0: abi.ComplexDouble is an array
+//   - Asin: parameter 0: abi.ComplexDouble is an array
+//   - Acos: parameter 0: abi.ComplexDouble is an array
+//   - Atan: parameter 0: abi.ComplexDouble is an array
+//   - Sinh: parameter 0: abi.ComplexDouble is an array
+//   - Cosh: parameter 0: abi.ComplexDouble is an array
+//   - Tanh: parameter 0: abi.ComplexDouble is an array
+//   - Asinh: parameter 0: abi.ComplexDouble is an array
+//   - Acosh: parameter 0: abi.ComplexDouble is an array
+//   - Atanh: parameter 0: abi.ComplexDouble is an array
+func init() {
+	ffi.Static(&Complex, map[string]func(symbol unsafe.Pointer){})
+}
+
+// ComplexFloat is linked by reflection for:
+//
+//   - Real: parameter 0: abi.ComplexFloat is an array
+//   - Imag: parameter 0: abi.ComplexFloat is an array
+//   - Abs: parameter 0: abi.ComplexFloat is an array
+//   - Arg: parameter 0: abi.ComplexFloat is an array
+//   - Conj: parameter 0: abi.ComplexFloat is an array
+//   - Proj: parameter 0: abi.ComplexFloat is an array
+//   - Exp: parameter 0: abi.ComplexFloat is an array
+//   - Log: parameter 0: abi.ComplexFloat is an array
+//   - Pow: parameter 0: abi.ComplexFloat is an array
+//   - Sqrt: parameter 0: abi.ComplexFloat is an array
+//   - Sin: parameter 0: abi.ComplexFloat is an array
+//   - Cos: parameter 0: abi.ComplexFloat is an array
+//   - Tan: parameter 0: abi.ComplexFloat is an array
+//   - Asin: parameter 0: abi.ComplexFloat is an array
+//   - Acos: parameter 0: abi.ComplexFloat is an array
+//   - Atan: parameter 0: abi.ComplexFloat is an array
+//   - Sinh: parameter 0: abi.ComplexFloat is an array
+//   - Cosh: parameter 0: abi.ComplexFloat is an array
+//   - Tanh: parameter 0: abi.ComplexFloat is an array
+//   - Asinh: parameter 0: abi.ComplexFloat is an array
+//   - Acosh: parameter 0: abi.ComplexFloat is an array
+//   - Atanh: parameter 0: abi.ComplexFloat is an array
+func init() {
+	ffi.Static(&ComplexFloat, map[string]func(symbol unsafe.Pointer){})
+}
+
+// ComplexDoubleLong is linked by reflection for:
+//
+//   - Real: parameter 0: abi.ComplexDoubleLong is an array
+//   - Imag: parameter 0: abi.ComplexDoubleLong is an array
+//   - Abs: parameter 0: abi.ComplexDoubleLong is an array
+//   - Arg: parameter 0: abi.ComplexDoubleLong is an array
+//   - Conj: parameter 0: abi.ComplexDoubleLong is an array
+//   - Proj: parameter 0: abi.ComplexDoubleLong is an array
+//   - Exp: parameter 0: abi.ComplexDoubleLong is an array
+//   - Log: parameter 0: abi.ComplexDoubleLong is an array
+//   - Pow: parameter 0: abi.ComplexDoubleLong is an array
+//   - Sqrt: parameter 0: abi.ComplexDoubleLong is an array
+//   - Sin: parameter 0: abi.ComplexDoubleLong is an array
+//   - Cos: parameter 0: abi.ComplexDoubleLong is an array
+//   - Tan: parameter 0: abi.ComplexDoubleLong is an array
+//   - Asin: parameter 0: abi.ComplexDoubleLong is an array
+//   - Acos: parameter 0: abi.ComplexDoubleLong is an array
+//   - Atan: parameter 0: abi.ComplexDoubleLong is an array
+//   - Sinh: parameter 0: abi.ComplexDoubleLong is an array
+//   - Cosh: parameter 0: abi.ComplexDoubleLong is an array
+//   - Tanh: parameter 0: abi.ComplexDoubleLong is an array
+//   - Asinh: parameter 0: abi.ComplexDoubleLong is an array
+//   - Acosh: parameter 0: abi.ComplexDoubleLong is an array
+//   - Atanh: parameter 0: abi.ComplexDoubleLong is an array
+func init() {
+	ffi.Static(&ComplexDoubleLong, map[string]func(symbol unsafe.Pointer){})
+}
+
+// Int is linked by reflection for:
+//
+//   - Div: result: Div[abi.Int] is passed by value
+func init() {
+	ffi.Static(&Int, map[string]func(symbol unsafe.Pointer){
+		"Abs": func(symbol unsafe.Pointer) {
+			Int.Abs = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"Rand": func(symbol unsafe.Pointer) {
+			Int.Rand = ffi.Func0[abi.Int](symbol)
+		},
+		"SetRandSeed": func(symbol unsafe.Pointer) {
+			Int.SetRandSeed = ffi.Proc1[abi.Int](symbol)
+		},
+	})
+}
+
+// Long is linked by reflection for:
+//
+//   - Div: result: Div[abi.Long] is passed by value
+//   - RoundLong: parameter 0: abi.DoubleLong is an array
+func init() {
+	ffi.Static(&Long, map[string]func(symbol unsafe.Pointer){
+		"Abs": func(symbol unsafe.Pointer) {
+			Long.Abs = ffi.Func1[abi.Long, abi.Long](symbol)
+		},
+		"RoundFloat": func(symbol unsafe.Pointer) {
+			Long.RoundFloat = ffi.Func1[abi.Float, abi.Long](symbol)
+		},
+		"Round": func(symbol unsafe.Pointer) {
+			Long.Round = ffi.Func1[abi.Double, abi.Long](symbol)
+		},
+	})
+}
+
+// LongLong is linked by reflection for:
+//
+//   - Div: result: Div[abi.LongLong] is passed by value
+//   - RoundLong: parameter 0: abi.DoubleLong is an array
+func init() {
+	ffi.Static(&LongLong, map[string]func(symbol unsafe.Pointer){
+		"Abs": func(symbol unsafe.Pointer) {
+			LongLong.Abs = ffi.Func1[abi.LongLong, abi.LongLong](symbol)
+		},
+		"RoundFloat": func(symbol unsafe.Pointer) {
+			LongLong.RoundFloat = ffi.Func1[abi.Float, abi.LongLong](symbol)
+		},
+		"Round": func(symbol unsafe.Pointer) {
+			LongLong.Round = ffi.Func1[abi.Double, abi.LongLong](symbol)
+		},
+	})
+}
+
+// IntMax is linked by reflection for:
+//
+//   - Div: result: Div[abi.IntMax] is passed by value
+func init() {
+	ffi.Static(&IntMax, map[string]func(symbol unsafe.Pointer){
+		"Abs": func(symbol unsafe.Pointer) {
+			IntMax.Abs = ffi.Func1[abi.IntMax, abi.IntMax](symbol)
+		},
+	})
+}
+
+// Double is linked by reflection for:
+//
+//   - RemainderQuotient: more than one result
+//   - Frexp: more than one result
+//   - Modf: more than one result
+//   - NextToward: parameter 1: abi.DoubleLong is an array
+func init() {
+	ffi.Static(&Double, map[string]func(symbol unsafe.Pointer){
+		"Abs": func(symbol unsafe.Pointer) {
+			Double.Abs = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Mod": func(symbol unsafe.Pointer) {
+			Double.Mod = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Remainder": func(symbol unsafe.Pointer) {
+			Double.Remainder = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"FusedMuliplyAdd": func(symbol unsafe.Pointer) {
+			Double.FusedMuliplyAdd = ffi.Func3[abi.Double, abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Max": func(symbol unsafe.Pointer) {
+			Double.Max = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Min": func(symbol unsafe.Pointer) {
+			Double.Min = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"PositiveDifference": func(symbol unsafe.Pointer) {
+			Double.PositiveDifference = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Nan": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Double](symbol)
+			Double.Nan = func(a0 abi.String) abi.Double {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Exp": func(symbol unsafe.Pointer) {
+			Double.Exp = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Exp2": func(symbol unsafe.Pointer) {
+			Double.Exp2 = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Expm1": func(symbol unsafe.Pointer) {
+			Double.Expm1 = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Log": func(symbol unsafe.Pointer) {
+			Double.Log = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Log10": func(symbol unsafe.Pointer) {
+			Double.Log10 = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Log2": func(symbol unsafe.Pointer) {
+			Double.Log2 = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Log1p": func(symbol unsafe.Pointer) {
+			Double.Log1p = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Pow": func(symbol unsafe.Pointer) {
+			Double.Pow = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Sqrt": func(symbol unsafe.Pointer) {
+			Double.Sqrt = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Cbrt": func(symbol unsafe.Pointer) {
+			Double.Cbrt = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Hypot": func(symbol unsafe.Pointer) {
+			Double.Hypot = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Sin": func(symbol unsafe.Pointer) {
+			Double.Sin = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Cos": func(symbol unsafe.Pointer) {
+			Double.Cos = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Tan": func(symbol unsafe.Pointer) {
+			Double.Tan = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Asin": func(symbol unsafe.Pointer) {
+			Double.Asin = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Acos": func(symbol unsafe.Pointer) {
+			Double.Acos = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Atan": func(symbol unsafe.Pointer) {
+			Double.Atan = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Atan2": func(symbol unsafe.Pointer) {
+			Double.Atan2 = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"Sinh": func(symbol unsafe.Pointer) {
+			Double.Sinh = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Cosh": func(symbol unsafe.Pointer) {
+			Double.Cosh = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Tanh": func(symbol unsafe.Pointer) {
+			Double.Tanh = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Asinh": func(symbol unsafe.Pointer) {
+			Double.Asinh = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Acosh": func(symbol unsafe.Pointer) {
+			Double.Acosh = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Atanh": func(symbol unsafe.Pointer) {
+			Double.Atanh = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Erf": func(symbol unsafe.Pointer) {
+			Double.Erf = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Erfc": func(symbol unsafe.Pointer) {
+			Double.Erfc = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"GammaT": func(symbol unsafe.Pointer) {
+			Double.GammaT = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"GammaL": func(symbol unsafe.Pointer) {
+			Double.GammaL = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Ceil": func(symbol unsafe.Pointer) {
+			Double.Ceil = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Floor": func(symbol unsafe.Pointer) {
+			Double.Floor = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Trunc": func(symbol unsafe.Pointer) {
+			Double.Trunc = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Round": func(symbol unsafe.Pointer) {
+			Double.Round = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"NearbyInt": func(symbol unsafe.Pointer) {
+			Double.NearbyInt = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Int": func(symbol unsafe.Pointer) {
+			Double.Int = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"Long": func(symbol unsafe.Pointer) {
+			Double.Long = ffi.Func1[abi.Double, abi.Long](symbol)
+		},
+		"LongLong": func(symbol unsafe.Pointer) {
+			Double.LongLong = ffi.Func1[abi.Double, abi.LongLong](symbol)
+		},
+		"Ldexp": func(symbol unsafe.Pointer) {
+			Double.Ldexp = ffi.Func2[abi.Double, abi.Int, abi.Double](symbol)
+		},
+		"Scale": func(symbol unsafe.Pointer) {
+			Double.Scale = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"ScaleLong": func(symbol unsafe.Pointer) {
+			Double.ScaleLong = ffi.Func2[abi.Double, abi.Long, abi.Double](symbol)
+		},
+		"LogInt": func(symbol unsafe.Pointer) {
+			Double.LogInt = ffi.Func1[abi.Double, abi.Int](symbol)
+		},
+		"Logb": func(symbol unsafe.Pointer) {
+			Double.Logb = ffi.Func1[abi.Double, abi.Double](symbol)
+		},
+		"NextAfter": func(symbol unsafe.Pointer) {
+			Double.NextAfter = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+		"CopySign": func(symbol unsafe.Pointer) {
+			Double.CopySign = ffi.Func2[abi.Double, abi.Double, abi.Double](symbol)
+		},
+	})
+}
+
+// Float is linked by reflection for:
+//
+//   - RemainderQuotient: more than one result
+//   - Frexp: more than one result
+//   - Modf: more than one result
+//   - NextToward: parameter 1: abi.DoubleLong is an array
+func init() {
+	ffi.Static(&Float, map[string]func(symbol unsafe.Pointer){
+		"Abs": func(symbol unsafe.Pointer) {
+			Float.Abs = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Mod": func(symbol unsafe.Pointer) {
+			Float.Mod = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Remainder": func(symbol unsafe.Pointer) {
+			Float.Remainder = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"FusedMuliplyAdd": func(symbol unsafe.Pointer) {
+			Float.FusedMuliplyAdd = ffi.Func3[abi.Float, abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Max": func(symbol unsafe.Pointer) {
+			Float.Max = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Min": func(symbol unsafe.Pointer) {
+			Float.Min = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"PositiveDifference": func(symbol unsafe.Pointer) {
+			Float.PositiveDifference = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Nan": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Float](symbol)
+			Float.Nan = func(a0 abi.String) abi.Float {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Exp": func(symbol unsafe.Pointer) {
+			Float.Exp = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Exp2": func(symbol unsafe.Pointer) {
+			Float.Exp2 = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Expm1": func(symbol unsafe.Pointer) {
+			Float.Expm1 = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Log": func(symbol unsafe.Pointer) {
+			Float.Log = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Log10": func(symbol unsafe.Pointer) {
+			Float.Log10 = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Log2": func(symbol unsafe.Pointer) {
+			Float.Log2 = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Log1p": func(symbol unsafe.Pointer) {
+			Float.Log1p = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Pow": func(symbol unsafe.Pointer) {
+			Float.Pow = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Sqrt": func(symbol unsafe.Pointer) {
+			Float.Sqrt = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Cbrt": func(symbol unsafe.Pointer) {
+			Float.Cbrt = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Hypot": func(symbol unsafe.Pointer) {
+			Float.Hypot = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Sin": func(symbol unsafe.Pointer) {
+			Float.Sin = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Cos": func(symbol unsafe.Pointer) {
+			Float.Cos = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Tan": func(symbol unsafe.Pointer) {
+			Float.Tan = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Asin": func(symbol unsafe.Pointer) {
+			Float.Asin = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Acos": func(symbol unsafe.Pointer) {
+			Float.Acos = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Atan": func(symbol unsafe.Pointer) {
+			Float.Atan = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Atan2": func(symbol unsafe.Pointer) {
+			Float.Atan2 = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"Sinh": func(symbol unsafe.Pointer) {
+			Float.Sinh = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Cosh": func(symbol unsafe.Pointer) {
+			Float.Cosh = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Tanh": func(symbol unsafe.Pointer) {
+			Float.Tanh = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Asinh": func(symbol unsafe.Pointer) {
+			Float.Asinh = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Acosh": func(symbol unsafe.Pointer) {
+			Float.Acosh = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Atanh": func(symbol unsafe.Pointer) {
+			Float.Atanh = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Erf": func(symbol unsafe.Pointer) {
+			Float.Erf = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Erfc": func(symbol unsafe.Pointer) {
+			Float.Erfc = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"GammaT": func(symbol unsafe.Pointer) {
+			Float.GammaT = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"GammaL": func(symbol unsafe.Pointer) {
+			Float.GammaL = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Ceil": func(symbol unsafe.Pointer) {
+			Float.Ceil = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Floor": func(symbol unsafe.Pointer) {
+			Float.Floor = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Trunc": func(symbol unsafe.Pointer) {
+			Float.Trunc = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Round": func(symbol unsafe.Pointer) {
+			Float.Round = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"NearbyInt": func(symbol unsafe.Pointer) {
+			Float.NearbyInt = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Int": func(symbol unsafe.Pointer) {
+			Float.Int = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"Long": func(symbol unsafe.Pointer) {
+			Float.Long = ffi.Func1[abi.Float, abi.Long](symbol)
+		},
+		"LongLong": func(symbol unsafe.Pointer) {
+			Float.LongLong = ffi.Func1[abi.Float, abi.LongLong](symbol)
+		},
+		"Ldexp": func(symbol unsafe.Pointer) {
+			Float.Ldexp = ffi.Func2[abi.Float, abi.Int, abi.Float](symbol)
+		},
+		"Scale": func(symbol unsafe.Pointer) {
+			Float.Scale = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"ScaleLong": func(symbol unsafe.Pointer) {
+			Float.ScaleLong = ffi.Func2[abi.Float, abi.Long, abi.Float](symbol)
+		},
+		"LogInt": func(symbol unsafe.Pointer) {
+			Float.LogInt = ffi.Func1[abi.Float, abi.Int](symbol)
+		},
+		"Logb": func(symbol unsafe.Pointer) {
+			Float.Logb = ffi.Func1[abi.Float, abi.Float](symbol)
+		},
+		"NextAfter": func(symbol unsafe.Pointer) {
+			Float.NextAfter = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+		"CopySign": func(symbol unsafe.Pointer) {
+			Float.CopySign = ffi.Func2[abi.Float, abi.Float, abi.Float](symbol)
+		},
+	})
+}
+
+// DoubleLong is linked by reflection for:
+//
+//   - Abs: parameter 0: abi.DoubleLong is an array
+//   - Mod: parameter 0: abi.DoubleLong is an array
+//   - Remainder: parameter 0: abi.DoubleLong is an array
+//   - RemainderQuotient: more than one result
+//   - FusedMuliplyAdd: parameter 0: abi.DoubleLong is an array
+//   - Max: parameter 0: abi.DoubleLong is an array
+//   - Min: parameter 0: abi.DoubleLong is an array
+//   - PositiveDifference: parameter 0: abi.DoubleLong is an array
+//   - Nan: result: abi.DoubleLong is an array
+//   - Exp: parameter 0: abi.DoubleLong is an array
+//   - Exp2: parameter 0: abi.DoubleLong is an array
+//   - Expm1: parameter 0: abi.DoubleLong is an array
+//   - Log: parameter 0: abi.DoubleLong is an array
+//   - Log10: parameter 0: abi.DoubleLong is an array
+//   - Log2: parameter 0: abi.DoubleLong is an array
+//   - Log1p: parameter 0: abi.DoubleLong is an array
+//   - Pow: parameter 0: abi.DoubleLong is an array
+//   - Sqrt: parameter 0: abi.DoubleLong is an array
+//   - Cbrt: parameter 0: abi.DoubleLong is an array
+//   - Hypot: parameter 0: abi.DoubleLong is an array
+//   - Sin: parameter 0: abi.DoubleLong is an array
+//   - Cos: parameter 0: abi.DoubleLong is an array
+//   - Tan: parameter 0: abi.DoubleLong is an array
+//   - Asin: parameter 0: abi.DoubleLong is an array
+//   - Acos: parameter 0: abi.DoubleLong is an array
+//   - Atan: parameter 0: abi.DoubleLong is an array
+//   - Atan2: parameter 0: abi.DoubleLong is an array
+//   - Sinh: parameter 0: abi.DoubleLong is an array
+//   - Cosh: parameter 0: abi.DoubleLong is an array
+//   - Tanh: parameter 0: abi.DoubleLong is an array
+//   - Asinh: parameter 0: abi.DoubleLong is an array
+//   - Acosh: parameter 0: abi.DoubleLong is an array
+//   - Atanh: parameter 0: abi.DoubleLong is an array
+//   - Erf: parameter 0: abi.DoubleLong is an array
+//   - Erfc: parameter 0: abi.DoubleLong is an array
+//   - GammaT: parameter 0: abi.DoubleLong is an array
+//   - GammaL: parameter 0: abi.DoubleLong is an array
+//   - Ceil: parameter 0: abi.DoubleLong is an array
+//   - Floor: parameter 0: abi.DoubleLong is an array
+//   - Trunc: parameter 0: abi.DoubleLong is an array
+//   - Round: parameter 0: abi.DoubleLong is an array
+//   - NearbyInt: parameter 0: abi.DoubleLong is an array
+//   - Int: parameter 0: abi.DoubleLong is an array
+//   - Long: parameter 0: abi.DoubleLong is an array
+//   - LongLong: parameter 0: abi.DoubleLong is an array
+//   - Frexp: more than one result
+//   - Ldexp: parameter 0: abi.DoubleLong is an array
+//   - Modf: more than one result
+//   - Scale: parameter 0: abi.DoubleLong is an array
+//   - ScaleLong: parameter 0: abi.DoubleLong is an array
+//   - LogInt: parameter 0: abi.DoubleLong is an array
+//   - Logb: parameter 0: abi.DoubleLong is an array
+//   - NextAfter: parameter 0: abi.DoubleLong is an array
+//   - NextToward: parameter 0: abi.DoubleLong is an array
+//   - CopySign: parameter 0: abi.DoubleLong is an array
+func init() {
+	ffi.Static(&DoubleLong, map[string]func(symbol unsafe.Pointer){})
+}
+
+func init() {
+	ffi.Static(&Char, map[string]func(symbol unsafe.Pointer){
+		"IsAlphaNumeric": func(symbol unsafe.Pointer) {
+			Char.IsAlphaNumeric = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsAlpha": func(symbol unsafe.Pointer) {
+			Char.IsAlpha = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsUpper": func(symbol unsafe.Pointer) {
+			Char.IsUpper = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsLower": func(symbol unsafe.Pointer) {
+			Char.IsLower = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsDigit": func(symbol unsafe.Pointer) {
+			Char.IsDigit = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsHexDigit": func(symbol unsafe.Pointer) {
+			Char.IsHexDigit = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsControl": func(symbol unsafe.Pointer) {
+			Char.IsControl = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsGraph": func(symbol unsafe.Pointer) {
+			Char.IsGraph = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsSpace": func(symbol unsafe.Pointer) {
+			Char.IsSpace = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsBlank": func(symbol unsafe.Pointer) {
+			Char.IsBlank = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsPrint": func(symbol unsafe.Pointer) {
+			Char.IsPrint = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"IsPuncuation": func(symbol unsafe.Pointer) {
+			Char.IsPuncuation = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"ToLower": func(symbol unsafe.Pointer) {
+			Char.ToLower = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"ToUpper": func(symbol unsafe.Pointer) {
+			Char.ToUpper = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&FloatingPoint, map[string]func(symbol unsafe.Pointer){
+		"ClearExceptions": func(symbol unsafe.Pointer) {
+			FloatingPoint.ClearExceptions = ffi.Func1[abi.FloatException, abi.Error](symbol)
+		},
+		"Exceptions": func(symbol unsafe.Pointer) {
+			FloatingPoint.Exceptions = ffi.Func1[abi.FloatException, abi.FloatException](symbol)
+		},
+		"RaiseExceptions": func(symbol unsafe.Pointer) {
+			FloatingPoint.RaiseExceptions = ffi.Func1[abi.FloatException, abi.Error](symbol)
+		},
+		"GetExceptionFlag": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.FloatException, abi.Error](symbol)
+			FloatingPoint.GetExceptionFlag = func(a0 *abi.FloatingPointEnvironment, a1 abi.FloatException) abi.Error {
+				return call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"SetExceptionFlag": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.FloatException, abi.Error](symbol)
+			FloatingPoint.SetExceptionFlag = func(a0 *abi.FloatingPointEnvironment, a1 abi.FloatException) abi.Error {
+				return call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"SetRoundingMode": func(symbol unsafe.Pointer) {
+			FloatingPoint.SetRoundingMode = ffi.Func1[abi.FloatRoundingMode, abi.Error](symbol)
+		},
+		"GetRoundingMode": func(symbol unsafe.Pointer) {
+			FloatingPoint.GetRoundingMode = ffi.Func0[abi.FloatRoundingMode](symbol)
+		},
+		"GetEnvironment": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			FloatingPoint.GetEnvironment = func(a0 *abi.FloatingPointEnvironment) abi.Error {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"SetEnvironment": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			FloatingPoint.SetEnvironment = func(a0 *abi.FloatingPointEnvironment) abi.Error {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"UpdateEnvironment": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			FloatingPoint.UpdateEnvironment = func(a0 *abi.FloatingPointEnvironment) abi.Error {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"HoldExceptions": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			FloatingPoint.HoldExceptions = func(a0 *abi.FloatingPointEnvironment) abi.Error {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+	})
+}
+
+func init() {
+	ffi.Static(&Locale, map[string]func(symbol unsafe.Pointer){
+		"Set": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[abi.LocaleCategory, unsafe.Pointer, unsafe.Pointer](symbol)
+			Locale.Set = func(a0 abi.LocaleCategory, a1 *abi.Locale) abi.String {
+				r := call(a0, unsafe.Pointer(a1))
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+		"Get": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			Locale.Get = func() *abi.Locale {
+				r := call()
+				return (*abi.Locale)(r)
+			}
+		},
+	})
+}
+
+// Program is linked by reflection for:
+//
+//   - OnExit: parameter 0: func() is a callback
+//   - OnExitFast: parameter 0: func() is a callback
+//   - LongJump: parameter 0: abi.JumpBuffer is an array
+//   - OnSignal: parameter 1: func(abi.Signal) is a callback
+func init() {
+	ffi.Static(&Program, map[string]func(symbol unsafe.Pointer){
+		"Abort": func(symbol unsafe.Pointer) {
+			Program.Abort = ffi.Proc0(symbol)
+		},
+		"Exit": func(symbol unsafe.Pointer) {
+			Program.Exit = ffi.Proc1[abi.Int](symbol)
+		},
+		"ExitFast": func(symbol unsafe.Pointer) {
+			Program.ExitFast = ffi.Proc1[abi.Int](symbol)
+		},
+		"ExitWithoutCleanup": func(symbol unsafe.Pointer) {
+			Program.ExitWithoutCleanup = ffi.Proc1[abi.Int](symbol)
+		},
+		"Raise": func(symbol unsafe.Pointer) {
+			Program.Raise = ffi.Proc1[abi.Signal](symbol)
+		},
+		"Getenv": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, unsafe.Pointer](symbol)
+			Program.Getenv = func(a0 abi.String) abi.String {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+		"Exec": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Error](symbol)
+			Program.Exec = func(a0 abi.String) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+	})
+}
+
+// Files is linked by reflection for:
+//
+//   - GetStringWide: parameter 0: abi.StringWide is passed by value
+//   - PutStringWide: parameter 0: abi.StringWide is passed by value
+//   - Scanf: variadic
+//   - Printf: variadic
+//   - ScanWidef: variadic
+//   - PrintWidef: variadic
+func init() {
+	ffi.Static(&Files, map[string]func(symbol unsafe.Pointer){
+		"Open": func(symbol unsafe.Pointer) {
+			call := ffi.FuncErrno2[unsafe.Pointer, unsafe.Pointer, unsafe.Pointer](symbol)
+			Files.Open = func(a0 string, a1 string) (*abi.File, error) {
+				r, errno := call(abi.NewString(string(a0)).Pointer(), abi.NewString(string(a1)).Pointer())
+				if r == nil {
+					return (*abi.File)(r), ffi.Failure(nil, errno, "fopen")
+				}
+				return (*abi.File)(r), nil
+			}
+		},
+		"Reopen": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, unsafe.Pointer, unsafe.Pointer](symbol)
+			Files.Reopen = func(a0 abi.String, a1 abi.String, a2 *abi.File) *abi.File {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)), unsafe.Pointer(a2))
+				return (*abi.File)(r)
+			}
+		},
+		"Flush": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Files.Flush = func(a0 *abi.File) abi.Int {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"SetBuffer": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.UnsafePointer, abi.Int](symbol)
+			Files.SetBuffer = func(a0 *abi.File, a1 abi.UnsafePointer) abi.Int {
+				return call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"SetBufferMode": func(symbol unsafe.Pointer) {
+			call := ffi.Func4[unsafe.Pointer, abi.UnsafePointer, abi.BufferMode, abi.Size, abi.Int](symbol)
+			Files.SetBufferMode = func(a0 *abi.File, a1 abi.UnsafePointer, a2 abi.BufferMode, a3 abi.Size) abi.Int {
+				return call(unsafe.Pointer(a0), a1, a2, a3)
+			}
+		},
+		"SetCharWide": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.Int, abi.Int](symbol)
+			Files.SetCharWide = func(a0 *abi.File, a1 abi.Int) abi.Int {
+				return call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"Read": func(symbol unsafe.Pointer) {
+			call := ffi.Func4[unsafe.Pointer, abi.Size, abi.Size, unsafe.Pointer, abi.Int](symbol)
+			Files.Read = func(a0 abi.Pointer[abi.Char], a1 abi.Size, a2 abi.Size, a3 *abi.File) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1, a2, unsafe.Pointer(a3))
+			}
+		},
+		"Write": func(symbol unsafe.Pointer) {
+			call := ffi.Func4[unsafe.Pointer, abi.Size, abi.Size, unsafe.Pointer, abi.Int](symbol)
+			Files.Write = func(a0 abi.Pointer[abi.Char], a1 abi.Size, a2 abi.Size, a3 *abi.File) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1, a2, unsafe.Pointer(a3))
+			}
+		},
+		"GetChar": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Files.GetChar = func(a0 *abi.File) abi.Int {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"GetString": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, abi.Int, unsafe.Pointer, unsafe.Pointer](symbol)
+			Files.GetString = func(a0 abi.Pointer[abi.Char], a1 abi.Int, a2 *abi.File) abi.Pointer[abi.Char] {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1, unsafe.Pointer(a2))
+				return *(*abi.Pointer[abi.Char])(unsafe.Pointer(&r))
+			}
+		},
+		"PutChar": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[abi.Int, unsafe.Pointer, abi.Int](symbol)
+			Files.PutChar = func(a0 abi.Int, a1 *abi.File) abi.Int {
+				return call(a0, unsafe.Pointer(a1))
+			}
+		},
+		"PutString": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Int](symbol)
+			Files.PutString = func(a0 abi.String, a1 *abi.File) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1))
+			}
+		},
+		"UngetChar": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[abi.Int, unsafe.Pointer, abi.Int](symbol)
+			Files.UngetChar = func(a0 abi.Int, a1 *abi.File) abi.Int {
+				return call(a0, unsafe.Pointer(a1))
+			}
+		},
+		"GetCharWide": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.CharWide](symbol)
+			Files.GetCharWide = func(a0 *abi.File) abi.CharWide {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"PutCharWide": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[abi.CharWide, unsafe.Pointer, abi.CharWide](symbol)
+			Files.PutCharWide = func(a0 abi.CharWide, a1 *abi.File) abi.CharWide {
+				return call(a0, unsafe.Pointer(a1))
+			}
+		},
+		"UngetCharWide": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[abi.CharWide, unsafe.Pointer, abi.CharWide](symbol)
+			Files.UngetCharWide = func(a0 abi.CharWide, a1 *abi.File) abi.CharWide {
+				return call(a0, unsafe.Pointer(a1))
+			}
+		},
+		"Tell": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Long](symbol)
+			Files.Tell = func(a0 *abi.File) abi.Long {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"GetPos": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Int](symbol)
+			Files.GetPos = func(a0 *abi.File, a1 *abi.FilePosition) abi.Int {
+				return call(unsafe.Pointer(a0), unsafe.Pointer(a1))
+			}
+		},
+		"Seek": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, abi.Long, abi.SeekMode, abi.Int](symbol)
+			Files.Seek = func(a0 *abi.File, a1 abi.Long, a2 abi.SeekMode) abi.Int {
+				return call(unsafe.Pointer(a0), a1, a2)
+			}
+		},
+		"SetPos": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Int](symbol)
+			Files.SetPos = func(a0 *abi.File, a1 *abi.FilePosition) abi.Int {
+				return call(unsafe.Pointer(a0), unsafe.Pointer(a1))
+			}
+		},
+		"Rewind": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			Files.Rewind = func(a0 *abi.File) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+		"ClearErr": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			Files.ClearErr = func(a0 *abi.File) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+		"IsEOF": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Files.IsEOF = func(a0 *abi.File) abi.Int {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"IsErr": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Files.IsErr = func(a0 *abi.File) abi.Int {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"Error": func(symbol unsafe.Pointer) {
+			call := ffi.Proc1[unsafe.Pointer](symbol)
+			Files.Error = func(a0 *abi.String) {
+				call(unsafe.Pointer(a0))
+			}
+		},
+		"Remove": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			Files.Remove = func(a0 abi.String) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Rename": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Int](symbol)
+			Files.Rename = func(a0 abi.String, a1 abi.String) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"Temp": func(symbol unsafe.Pointer) {
+			call := ffi.Func0[unsafe.Pointer](symbol)
+			Files.Temp = func() *abi.File {
+				r := call()
+				return (*abi.File)(r)
+			}
+		},
+		"TempName": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, unsafe.Pointer](symbol)
+			Files.TempName = func(a0 abi.String) abi.String {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+	})
+}
+
+// IO is linked by reflection for:
+//
+//   - Scanf: variadic
+//   - Printf: variadic
+//   - ScanWidef: variadic
+//   - PrintWidef: variadic
+func init() {
+	ffi.Static(&IO, map[string]func(symbol unsafe.Pointer){
+		"GetChar": func(symbol unsafe.Pointer) {
+			IO.GetChar = ffi.Func0[abi.Int](symbol)
+		},
+		"GetString": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, unsafe.Pointer](symbol)
+			IO.GetString = func(a0 abi.Pointer[abi.Char]) abi.Pointer[abi.Char] {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+				return *(*abi.Pointer[abi.Char])(unsafe.Pointer(&r))
+			}
+		},
+		"PutChar": func(symbol unsafe.Pointer) {
+			IO.PutChar = ffi.Func1[abi.Int, abi.Int](symbol)
+		},
+		"PutString": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			IO.PutString = func(a0 abi.String) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"GetCharWide": func(symbol unsafe.Pointer) {
+			IO.GetCharWide = ffi.Func0[abi.CharWide](symbol)
+		},
+		"PutCharWide": func(symbol unsafe.Pointer) {
+			IO.PutCharWide = ffi.Func1[abi.CharWide, abi.CharWide](symbol)
+		},
+	})
+}
+
+// String is linked by reflection for:
+//
+//   - Scanf: variadic
+//   - Printf: variadic
+//   - ScanWidef: variadic
+//   - PrintWidef: variadic
+//   - ParseDoubleLong: result: abi.DoubleLong is an array
+func init() {
+	ffi.Static(&String, map[string]func(symbol unsafe.Pointer){
+		"Error": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[abi.Error, unsafe.Pointer](symbol)
+			String.Error = func(a0 abi.Error) abi.String {
+				r := call(a0)
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+		"ToFloat": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Float](symbol)
+			String.ToFloat = func(a0 abi.String) abi.Float {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"ToInt": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Int](symbol)
+			String.ToInt = func(a0 abi.String) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"ToLong": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Long](symbol)
+			String.ToLong = func(a0 abi.String) abi.Long {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"ToLongLong": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.LongLong](symbol)
+			String.ToLongLong = func(a0 abi.String) abi.LongLong {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"ParseLong": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.Long](symbol)
+			String.ParseLong = func(a0 abi.String, a1 *abi.Char, a2 abi.Int) abi.Long {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+		"ParseLongLong": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.LongLong](symbol)
+			String.ParseLongLong = func(a0 abi.String, a1 *abi.Char, a2 abi.Int) abi.LongLong {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+		"ParseUnsignedLong": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.LongUnsigned](symbol)
+			String.ParseUnsignedLong = func(a0 abi.String, a1 *abi.Char, a2 abi.Int) abi.LongUnsigned {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+		"ParseUnsignedLongLong": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.LongLongUnsigned](symbol)
+			String.ParseUnsignedLongLong = func(a0 abi.String, a1 *abi.Char, a2 abi.Int) abi.LongLongUnsigned {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+		"ParseFloat": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Float](symbol)
+			String.ParseFloat = func(a0 abi.String, a1 *abi.Char) abi.Float {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1))
+			}
+		},
+		"ParseDouble": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Double](symbol)
+			String.ParseDouble = func(a0 abi.String, a1 *abi.Char) abi.Double {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1))
+			}
+		},
+		"ParseIntmax": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.IntMax](symbol)
+			String.ParseIntmax = func(a0 abi.String, a1 *abi.Char, a2 abi.Int) abi.IntMax {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+		"ParseUintmax": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Int, abi.UIntMax](symbol)
+			String.ParseUintmax = func(a0 abi.String, a1 *abi.Char, a2 abi.Int) abi.UIntMax {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), unsafe.Pointer(a1), a2)
+			}
+		},
+		"Copy": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Error](symbol)
+			String.Copy = func(a0 abi.String, a1 abi.String) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"CopyRange": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Error](symbol)
+			String.CopyRange = func(a0 abi.String, a1 abi.String) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"Append": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Error](symbol)
+			String.Append = func(a0 abi.String, a1 abi.String) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"AppendRange": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Error](symbol)
+			String.AppendRange = func(a0 abi.String, a1 abi.String) abi.Error {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"Localize": func(symbol unsafe.Pointer) {
+			call := ffi.Func3[unsafe.Pointer, unsafe.Pointer, abi.Size, abi.Size](symbol)
+			String.Localize = func(a0 abi.String, a1 abi.String, a2 abi.Size) abi.Size {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)), a2)
+			}
+		},
+		"Duplicate": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, unsafe.Pointer](symbol)
+			String.Duplicate = func(a0 abi.String) abi.String {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+		"DuplicateRange": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.Size, unsafe.Pointer](symbol)
+			String.DuplicateRange = func(a0 abi.String, a1 abi.Size) abi.String {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1)
+				return *(*abi.String)(unsafe.Pointer(&r))
+			}
+		},
+		"Length": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Size](symbol)
+			String.Length = func(a0 abi.String) abi.Size {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)))
+			}
+		},
+		"Compare": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Int](symbol)
+			String.Compare = func(a0 abi.String, a1 abi.String) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"CompareInLocale": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Int](symbol)
+			String.CompareInLocale = func(a0 abi.String, a1 abi.String) abi.Int {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"FindFirst": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.Int, unsafe.Pointer](symbol)
+			String.FindFirst = func(a0 abi.String, a1 abi.Int) *abi.Char {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1)
+				return (*abi.Char)(r)
+			}
+		},
+		"FindLast": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.Int, unsafe.Pointer](symbol)
+			String.FindLast = func(a0 abi.String, a1 abi.Int) *abi.Char {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1)
+				return (*abi.Char)(r)
+			}
+		},
+		"MatchLength": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Size](symbol)
+			String.MatchLength = func(a0 abi.String, a1 abi.String) abi.Size {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"Match": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, abi.Size](symbol)
+			String.Match = func(a0 abi.String, a1 abi.String) abi.Size {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+			}
+		},
+		"MatchFirst": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, unsafe.Pointer](symbol)
+			String.MatchFirst = func(a0 abi.String, a1 abi.String) *abi.Char {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+				return (*abi.Char)(r)
+			}
+		},
+		"Contains": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, unsafe.Pointer](symbol)
+			String.Contains = func(a0 abi.String, a1 abi.String) *abi.Char {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+				return (*abi.Char)(r)
+			}
+		},
+		"ScanToken": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, unsafe.Pointer, unsafe.Pointer](symbol)
+			String.ScanToken = func(a0 abi.String, a1 abi.String) *abi.Char {
+				r := call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), *(*unsafe.Pointer)(unsafe.Pointer(&a1)))
+				return (*abi.Char)(r)
+			}
+		},
+	})
+}
+
+// Memory is linked by reflection for:
+//
+//   - BinarySearch: parameter 4: func(abi.UnsafePointer, abi.UnsafePointer) abi.Int is a callback
+//   - Sort: parameter 3: func(abi.UnsafePointer, abi.UnsafePointer) abi.Int is a callback
+func init() {
+	ffi.Static(&Memory, map[string]func(symbol unsafe.Pointer){
+		"Calloc": func(symbol unsafe.Pointer) {
+			Memory.Calloc = ffi.Func2[abi.Size, abi.Size, abi.UnsafePointer](symbol)
+		},
+		"Free": func(symbol unsafe.Pointer) {
+			Memory.Free = ffi.Proc1[abi.UnsafePointer](symbol)
+		},
+		"Malloc": func(symbol unsafe.Pointer) {
+			Memory.Malloc = ffi.Func1[abi.Size, abi.UnsafePointer](symbol)
+		},
+		"Realloc": func(symbol unsafe.Pointer) {
+			Memory.Realloc = ffi.Func2[abi.UnsafePointer, abi.Size, abi.UnsafePointer](symbol)
+		},
+		"Compare": func(symbol unsafe.Pointer) {
+			Memory.Compare = ffi.Func3[abi.UnsafePointer, abi.UnsafePointer, abi.Size, abi.Int](symbol)
+		},
+		"Copy": func(symbol unsafe.Pointer) {
+			Memory.Copy = ffi.Func3[abi.UnsafePointer, abi.UnsafePointer, abi.Size, abi.UnsafePointer](symbol)
+		},
+		"Move": func(symbol unsafe.Pointer) {
+			Memory.Move = ffi.Func3[abi.UnsafePointer, abi.UnsafePointer, abi.Size, abi.UnsafePointer](symbol)
+		},
+		"Set": func(symbol unsafe.Pointer) {
+			Memory.Set = ffi.Func3[abi.UnsafePointer, abi.Int, abi.Size, abi.UnsafePointer](symbol)
+		},
+		"Find": func(symbol unsafe.Pointer) {
+			Memory.Find = ffi.Func3[abi.UnsafePointer, abi.Int, abi.Size, abi.UnsafePointer](symbol)
+		},
+	})
+}
+
+// Time is linked by reflection for:
+//
+//   - DateStringWide: parameter 0: abi.StringWide is passed by value
+func init() {
+	ffi.Static(&Time, map[string]func(symbol unsafe.Pointer){
+		"Diff": func(symbol unsafe.Pointer) {
+			Time.Diff = ffi.Func2[abi.Time, abi.Time, abi.Double](symbol)
+		},
+		"Now": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Time](symbol)
+			Time.Now = func(a0 *abi.Time) abi.Time {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+		"Clock": func(symbol unsafe.Pointer) {
+			Time.Clock = ffi.Func0[abi.Clock](symbol)
+		},
+		"Nanos": func(symbol unsafe.Pointer) {
+			call := ffi.Proc2[unsafe.Pointer, abi.TimeType](symbol)
+			Time.Nanos = func(a0 *abi.NanoTime, a1 abi.TimeType) {
+				call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"GetResolution": func(symbol unsafe.Pointer) {
+			call := ffi.Func2[unsafe.Pointer, abi.TimeType, abi.Int](symbol)
+			Time.GetResolution = func(a0 *abi.NanoTime, a1 abi.TimeType) abi.Int {
+				return call(unsafe.Pointer(a0), a1)
+			}
+		},
+		"DateString": func(symbol unsafe.Pointer) {
+			call := ffi.Func4[unsafe.Pointer, abi.Size, unsafe.Pointer, unsafe.Pointer, abi.Size](symbol)
+			Time.DateString = func(a0 abi.String, a1 abi.Size, a2 abi.String, a3 *abi.Date) abi.Size {
+				return call(*(*unsafe.Pointer)(unsafe.Pointer(&a0)), a1, *(*unsafe.Pointer)(unsafe.Pointer(&a2)), unsafe.Pointer(a3))
+			}
+		},
+		"UTC": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[abi.Time, unsafe.Pointer](symbol)
+			Time.UTC = func(a0 abi.Time) *abi.Date {
+				r := call(a0)
+				return (*abi.Date)(r)
+			}
+		},
+		"Local": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[abi.Time, unsafe.Pointer](symbol)
+			Time.Local = func(a0 abi.Time) *abi.Date {
+				r := call(a0)
+				return (*abi.Date)(r)
+			}
+		},
+		"Value": func(symbol unsafe.Pointer) {
+			call := ffi.Func1[unsafe.Pointer, abi.Time](symbol)
+			Time.Value = func(a0 *abi.Date) abi.Time {
+				return call(unsafe.Pointer(a0))
+			}
+		},
+	})
+}
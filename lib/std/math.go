@@ -22,9 +22,9 @@ var Long struct {
 	Abs func(abi.Long) abi.Long                `ffi:"labs"`
 	Div func(abi.Long, abi.Long) Div[abi.Long] `ffi:"ldiv"`
 
-	RoundFloat func(abi.Float) abi.Long      `ffi:"lround"`
+	RoundFloat func(abi.Float) abi.Long      `ffi:"lroundf"`
 	Round      func(abi.Double) abi.Long     `ffi:"lround"`
-	RoundLong  func(abi.DoubleLong) abi.Long `ffi:"lround"`
+	RoundLong  func(abi.DoubleLong) abi.Long `ffi:"lroundl" ffigen:"-"`
 }
 
 var LongLong struct {
@@ -33,9 +33,9 @@ var LongLong struct {
 	Abs func(abi.LongLong) abi.LongLong                    `ffi:"llabs"`
 	Div func(abi.LongLong, abi.LongLong) Div[abi.LongLong] `ffi:"lldiv"`
 
-	RoundFloat func(abi.Float) abi.Long      `ffi:"llround"`
-	Round      func(abi.Double) abi.Long     `ffi:"llround"`
-	RoundLong  func(abi.DoubleLong) abi.Long `ffi:"llround"`
+	RoundFloat func(abi.Float) abi.LongLong      `ffi:"llroundf"`
+	Round      func(abi.Double) abi.LongLong     `ffi:"llround"`
+	RoundLong  func(abi.DoubleLong) abi.LongLong `ffi:"llroundl" ffigen:"-"`
 }
 
 var IntMax struct {
@@ -108,7 +108,7 @@ var Double struct {
 	LogInt     func(abi.Double) abi.Int                    `ffi:"logb"`
 	Logb       func(abi.Double) abi.Double                 `ffi:"logb"`
 	NextAfter  func(abi.Double, abi.Double) abi.Double     `ffi:"nextafter"`
-	NextToward func(abi.Double, abi.DoubleLong) abi.Double `ffi:"nexttoward"`
+	NextToward func(abi.Double, abi.DoubleLong) abi.Double `ffi:"nexttoward" ffigen:"-"`
 	CopySign   func(abi.Double, abi.Double) abi.Double     `ffi:"copysign"`
 }
 
@@ -175,75 +175,75 @@ var Float struct {
 	LogInt     func(abi.Float) abi.Int                   `ffi:"logbf"`
 	Logb       func(abi.Float) abi.Float                 `ffi:"logbf"`
 	NextAfter  func(abi.Float, abi.Float) abi.Float      `ffi:"nextafterf"`
-	NextToward func(abi.Float, abi.DoubleLong) abi.Float `ffi:"nexttowardf"`
+	NextToward func(abi.Float, abi.DoubleLong) abi.Float `ffi:"nexttowardf" ffigen:"-"`
 	CopySign   func(abi.Float, abi.Float) abi.Float      `ffi:"copysignf"`
 }
 
 var DoubleLong struct {
 	LibM
 
-	Abs                func(abi.DoubleLong) abi.DoubleLong                                 `ffi:"fabsl"`
-	Mod                func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fmodl"`
-	Remainder          func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"remainderl"`
-	RemainderQuotient  func(abi.DoubleLong, abi.DoubleLong) (abi.DoubleLong, abi.Int)      `ffi:"remquol"`
-	FusedMuliplyAdd    func(abi.DoubleLong, abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"fmal"`
-	Max                func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fmaxl"`
-	Min                func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fminl"`
-	PositiveDifference func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fdiml"`
-	Nan                func(abi.String) abi.DoubleLong                                     `ffi:"nanl"`
-
-	Exp   func(abi.DoubleLong) abi.DoubleLong `ffi:"expl"`
-	Exp2  func(abi.DoubleLong) abi.DoubleLong `ffi:"exp2l"`
-	Expm1 func(abi.DoubleLong) abi.DoubleLong `ffi:"expm1l"`
-	Log   func(abi.DoubleLong) abi.DoubleLong `ffi:"logl"`
-	Log10 func(abi.DoubleLong) abi.DoubleLong `ffi:"log10l"`
-	Log2  func(abi.DoubleLong) abi.DoubleLong `ffi:"log2l"`
-	Log1p func(abi.DoubleLong) abi.DoubleLong `ffi:"log1pl"`
-
-	Pow   func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"powl"`
-	Sqrt  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"sqrtl"`
-	Cbrt  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"cbrtl"`
-	Hypot func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"hypotl"`
-
-	Sin   func(abi.DoubleLong) abi.DoubleLong                 `ffi:"sinl"`
-	Cos   func(abi.DoubleLong) abi.DoubleLong                 `ffi:"cosl"`
-	Tan   func(abi.DoubleLong) abi.DoubleLong                 `ffi:"tanl"`
-	Asin  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"asinl"`
-	Acos  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"acosl"`
-	Atan  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"atanl"`
-	Atan2 func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"atan2l"`
-
-	Sinh  func(abi.DoubleLong) abi.DoubleLong `ffi:"sinhl"`
-	Cosh  func(abi.DoubleLong) abi.DoubleLong `ffi:"coshl"`
-	Tanh  func(abi.DoubleLong) abi.DoubleLong `ffi:"tanhl"`
-	Asinh func(abi.DoubleLong) abi.DoubleLong `ffi:"asinhl"`
-	Acosh func(abi.DoubleLong) abi.DoubleLong `ffi:"acoshl"`
-	Atanh func(abi.DoubleLong) abi.DoubleLong `ffi:"atanhl"`
-
-	Erf    func(abi.DoubleLong) abi.DoubleLong `ffi:"erfl"`
-	Erfc   func(abi.DoubleLong) abi.DoubleLong `ffi:"erfcl"`
-	GammaT func(abi.DoubleLong) abi.DoubleLong `ffi:"tgammal"`
-	GammaL func(abi.DoubleLong) abi.DoubleLong `ffi:"lgammal"`
-
-	Ceil      func(abi.DoubleLong) abi.DoubleLong `ffi:"ceill"`
-	Floor     func(abi.DoubleLong) abi.DoubleLong `ffi:"floorl"`
-	Trunc     func(abi.DoubleLong) abi.DoubleLong `ffi:"truncl"`
-	Round     func(abi.DoubleLong) abi.DoubleLong `ffi:"roundl"`
-	NearbyInt func(abi.DoubleLong) abi.DoubleLong `ffi:"nearbyintl"`
-	Int       func(abi.DoubleLong) abi.DoubleLong `ffi:"rintl"`
-	Long      func(abi.DoubleLong) abi.Long       `ffi:"lrintl"`
-	LongLong  func(abi.DoubleLong) abi.LongLong   `ffi:"llrintl"`
-
-	Frexp      func(abi.DoubleLong) (abi.DoubleLong, abi.Int)        `ffi:"frexpl"`
-	Ldexp      func(abi.DoubleLong, abi.Int) abi.DoubleLong          `ffi:"ldexpl"`
-	Modf       func(abi.DoubleLong) (abi.DoubleLong, abi.DoubleLong) `ffi:"modfl"`
-	Scale      func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"scalbnl"`
-	ScaleLong  func(abi.DoubleLong, abi.Long) abi.DoubleLong         `ffi:"scalblnl"`
-	LogInt     func(abi.DoubleLong) abi.Int                          `ffi:"logbl"`
-	Logb       func(abi.DoubleLong) abi.DoubleLong                   `ffi:"logbl"`
-	NextAfter  func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"nextafterl"`
-	NextToward func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"nexttowardl"`
-	CopySign   func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"copysignl"`
+	Abs                func(abi.DoubleLong) abi.DoubleLong                                 `ffi:"fabsl" ffigen:"-"`
+	Mod                func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fmodl" ffigen:"-"`
+	Remainder          func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"remainderl" ffigen:"-"`
+	RemainderQuotient  func(abi.DoubleLong, abi.DoubleLong) (abi.DoubleLong, abi.Int)      `ffi:"remquol" ffigen:"-"`
+	FusedMuliplyAdd    func(abi.DoubleLong, abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"fmal" ffigen:"-"`
+	Max                func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fmaxl" ffigen:"-"`
+	Min                func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fminl" ffigen:"-"`
+	PositiveDifference func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong                 `ffi:"fdiml" ffigen:"-"`
+	Nan                func(abi.String) abi.DoubleLong                                     `ffi:"nanl" ffigen:"-"`
+
+	Exp   func(abi.DoubleLong) abi.DoubleLong `ffi:"expl" ffigen:"-"`
+	Exp2  func(abi.DoubleLong) abi.DoubleLong `ffi:"exp2l" ffigen:"-"`
+	Expm1 func(abi.DoubleLong) abi.DoubleLong `ffi:"expm1l" ffigen:"-"`
+	Log   func(abi.DoubleLong) abi.DoubleLong `ffi:"logl" ffigen:"-"`
+	Log10 func(abi.DoubleLong) abi.DoubleLong `ffi:"log10l" ffigen:"-"`
+	Log2  func(abi.DoubleLong) abi.DoubleLong `ffi:"log2l" ffigen:"-"`
+	Log1p func(abi.DoubleLong) abi.DoubleLong `ffi:"log1pl" ffigen:"-"`
+
+	Pow   func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"powl" ffigen:"-"`
+	Sqrt  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"sqrtl" ffigen:"-"`
+	Cbrt  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"cbrtl" ffigen:"-"`
+	Hypot func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"hypotl" ffigen:"-"`
+
+	Sin   func(abi.DoubleLong) abi.DoubleLong                 `ffi:"sinl" ffigen:"-"`
+	Cos   func(abi.DoubleLong) abi.DoubleLong                 `ffi:"cosl" ffigen:"-"`
+	Tan   func(abi.DoubleLong) abi.DoubleLong                 `ffi:"tanl" ffigen:"-"`
+	Asin  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"asinl" ffigen:"-"`
+	Acos  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"acosl" ffigen:"-"`
+	Atan  func(abi.DoubleLong) abi.DoubleLong                 `ffi:"atanl" ffigen:"-"`
+	Atan2 func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong `ffi:"atan2l" ffigen:"-"`
+
+	Sinh  func(abi.DoubleLong) abi.DoubleLong `ffi:"sinhl" ffigen:"-"`
+	Cosh  func(abi.DoubleLong) abi.DoubleLong `ffi:"coshl" ffigen:"-"`
+	Tanh  func(abi.DoubleLong) abi.DoubleLong `ffi:"tanhl" ffigen:"-"`
+	Asinh func(abi.DoubleLong) abi.DoubleLong `ffi:"asinhl" ffigen:"-"`
+	Acosh func(abi.DoubleLong) abi.DoubleLong `ffi:"acoshl" ffigen:"-"`
+	Atanh func(abi.DoubleLong) abi.DoubleLong `ffi:"atanhl" ffigen:"-"`
+
+	Erf    func(abi.DoubleLong) abi.DoubleLong `ffi:"erfl" ffigen:"-"`
+	Erfc   func(abi.DoubleLong) abi.DoubleLong `ffi:"erfcl" ffigen:"-"`
+	GammaT func(abi.DoubleLong) abi.DoubleLong `ffi:"tgammal" ffigen:"-"`
+	GammaL func(abi.DoubleLong) abi.DoubleLong `ffi:"lgammal" ffigen:"-"`
+
+	Ceil      func(abi.DoubleLong) abi.DoubleLong `ffi:"ceill" ffigen:"-"`
+	Floor     func(abi.DoubleLong) abi.DoubleLong `ffi:"floorl" ffigen:"-"`
+	Trunc     func(abi.DoubleLong) abi.DoubleLong `ffi:"truncl" ffigen:"-"`
+	Round     func(abi.DoubleLong) abi.DoubleLong `ffi:"roundl" ffigen:"-"`
+	NearbyInt func(abi.DoubleLong) abi.DoubleLong `ffi:"nearbyintl" ffigen:"-"`
+	Int       func(abi.DoubleLong) abi.DoubleLong `ffi:"rintl" ffigen:"-"`
+	Long      func(abi.DoubleLong) abi.Long       `ffi:"lrintl" ffigen:"-"`
+	LongLong  func(abi.DoubleLong) abi.LongLong   `ffi:"llrintl" ffigen:"-"`
+
+	Frexp      func(abi.DoubleLong) (abi.DoubleLong, abi.Int)        `ffi:"frexpl" ffigen:"-"`
+	Ldexp      func(abi.DoubleLong, abi.Int) abi.DoubleLong          `ffi:"ldexpl" ffigen:"-"`
+	Modf       func(abi.DoubleLong) (abi.DoubleLong, abi.DoubleLong) `ffi:"modfl" ffigen:"-"`
+	Scale      func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"scalbnl" ffigen:"-"`
+	ScaleLong  func(abi.DoubleLong, abi.Long) abi.DoubleLong         `ffi:"scalblnl" ffigen:"-"`
+	LogInt     func(abi.DoubleLong) abi.Int                          `ffi:"logbl" ffigen:"-"`
+	Logb       func(abi.DoubleLong) abi.DoubleLong                   `ffi:"logbl" ffigen:"-"`
+	NextAfter  func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"nextafterl" ffigen:"-"`
+	NextToward func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"nexttowardl" ffigen:"-"`
+	CopySign   func(abi.DoubleLong, abi.DoubleLong) abi.DoubleLong   `ffi:"copysignl" ffigen:"-"`
 }
 
 func ClassifyFloat(f abi.Float) abi.FloatClass {
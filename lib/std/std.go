@@ -1,7 +1,10 @@
 package std
 
+//go:generate go run qlova.tech/cmd/ffigen
+
 import (
 	"runtime/debug"
+	"unsafe"
 
 	"qlova.tech/abi"
 	"qlova.tech/ffi"
@@ -120,10 +123,10 @@ var Program struct {
 	Exit               func(abi.Int)                      `ffi:"exit"`
 	ExitFast           func(abi.Int)                      `ffi:"quick_exit"`
 	ExitWithoutCleanup func(abi.Int)                      `ffi:"_Exit"`
-	OnExit             func(func())                       `ffi:"atexit,__cxa_atexit"`
-	OnExitFast         func(func())                       `ffi:"at_quick_exit,__cxa_at_quick_exit"`
+	OnExit             func(func())                       `ffi:"atexit,__cxa_atexit,keep"`
+	OnExitFast         func(func())                       `ffi:"at_quick_exit,__cxa_at_quick_exit,keep"`
 	LongJump           func(abi.JumpBuffer, abi.Int)      `ffi:"longjmp"`
-	OnSignal           func(abi.Signal, func(abi.Signal)) `ffi:"signal"`
+	OnSignal           func(abi.Signal, func(abi.Signal)) `ffi:"signal,keep"`
 	Raise              func(abi.Signal)                   `ffi:"raise"`
 	Getenv             func(abi.String) abi.String        `ffi:"getenv"`
 	Exec               func(abi.String) abi.Error         `ffi:"system"`
@@ -132,7 +135,11 @@ var Program struct {
 var Files struct {
 	LibC
 
-	Open          func(abi.String, abi.String) *abi.File                               `ffi:"fopen"`
+	Stdin  ffi.Var[*abi.File] `ffi:"stdin,__stdinp"`
+	Stdout ffi.Var[*abi.File] `ffi:"stdout,__stdoutp"`
+	Stderr ffi.Var[*abi.File] `ffi:"stderr,__stderrp"`
+
+	Open          func(string, string) (*abi.File, error)                              `ffi:"fopen,err=nil"`
 	Reopen        func(abi.String, abi.String, *abi.File) *abi.File                    `ffi:"freopen"`
 	Flush         func(*abi.File) abi.Int                                              `ffi:"fflush"`
 	SetBuffer     func(*abi.File, abi.UnsafePointer) abi.Int                           `ffi:"setbuf"`
@@ -154,10 +161,10 @@ var Files struct {
 	PutStringWide func(abi.StringWide, *abi.File) abi.Int                 `ffi:"fputws"`
 	UngetCharWide func(abi.CharWide, *abi.File) abi.CharWide              `ffi:"ungetwc"`
 
-	Scanf      func(*abi.File, abi.String, ...abi.UnsafePointer) abi.Int     `ffi:"fscanf"`
-	Printf     func(*abi.File, abi.String, ...abi.UnsafePointer) abi.Int     `ffi:"fprintf"`
-	ScanWidef  func(*abi.File, abi.StringWide, ...abi.UnsafePointer) abi.Int `ffi:"fwscanf"`
-	PrintWidef func(*abi.File, abi.StringWide, ...abi.UnsafePointer) abi.Int `ffi:"fwprintf"`
+	Scanf      func(*abi.File, string, ...any) abi.Int         `ffi:"fscanf"`
+	Printf     func(*abi.File, string, ...any) abi.Int         `ffi:"fprintf"`
+	ScanWidef  func(*abi.File, abi.StringWide, ...any) abi.Int `ffi:"fwscanf"`
+	PrintWidef func(*abi.File, abi.StringWide, ...any) abi.Int `ffi:"fwprintf"`
 
 	Tell   func(*abi.File) abi.Long                        `ffi:"ftell"`
 	GetPos func(*abi.File, *abi.FilePosition) abi.Int      `ffi:"fgetpos"`
@@ -187,10 +194,10 @@ var IO struct {
 	GetCharWide func() abi.CharWide             `ffi:"getwchar"`
 	PutCharWide func(abi.CharWide) abi.CharWide `ffi:"putwchar"`
 
-	Scanf      func(abi.String, ...abi.UnsafePointer) abi.Int     `ffi:"scanf"`
-	Printf     func(abi.String, ...abi.UnsafePointer) abi.Int     `ffi:"printf"`
-	ScanWidef  func(abi.StringWide, ...abi.UnsafePointer) abi.Int `ffi:"wscanf"`
-	PrintWidef func(abi.StringWide, ...abi.UnsafePointer) abi.Int `ffi:"wprintf"`
+	Scanf      func(string, ...any) abi.Int         `ffi:"scanf"`
+	Printf     func(string, ...any) abi.Int         `ffi:"printf"`
+	ScanWidef  func(abi.StringWide, ...any) abi.Int `ffi:"wscanf"`
+	PrintWidef func(abi.StringWide, ...any) abi.Int `ffi:"wprintf"`
 }
 
 var String struct {
@@ -198,10 +205,10 @@ var String struct {
 
 	Error func(abi.Error) abi.String `ffi:"strerror"`
 
-	Scanf      func(abi.String, abi.String, ...abi.UnsafePointer) abi.Int         `ffi:"sscanf"`
-	Printf     func(abi.String, abi.String, ...abi.UnsafePointer) abi.Int         `ffi:"sprintf"`
-	ScanWidef  func(abi.StringWide, abi.StringWide, ...abi.UnsafePointer) abi.Int `ffi:"swscanf"`
-	PrintWidef func(abi.StringWide, abi.StringWide, ...abi.UnsafePointer) abi.Int `ffi:"swprintf"`
+	Scanf      func(abi.String, string, ...any) abi.Int             `ffi:"sscanf"`
+	Printf     func(abi.String, string, ...any) abi.Int             `ffi:"sprintf"`
+	ScanWidef  func(abi.StringWide, abi.StringWide, ...any) abi.Int `ffi:"swscanf"`
+	PrintWidef func(abi.StringWide, abi.StringWide, ...any) abi.Int `ffi:"swprintf"`
 
 	ToFloat               func(abi.String) abi.Float                                `ffi:"atof"`
 	ToInt                 func(abi.String) abi.Int                                  `ffi:"atoi"`
@@ -213,7 +220,7 @@ var String struct {
 	ParseUnsignedLongLong func(abi.String, *abi.Char, abi.Int) abi.LongLongUnsigned `ffi:"strtoull"`
 	ParseFloat            func(abi.String, *abi.Char) abi.Float                     `ffi:"strtof"`
 	ParseDouble           func(abi.String, *abi.Char) abi.Double                    `ffi:"strtod"`
-	ParseDoubleLong       func(abi.String, *abi.Char) abi.DoubleLong                `ffi:"strtold"`
+	ParseDoubleLong       func(abi.String, *abi.Char) abi.DoubleLong                `ffi:"strtold" ffigen:"-"`
 	ParseIntmax           func(abi.String, *abi.Char, abi.Int) abi.IntMax           `ffi:"strtoimax"`
 	ParseUintmax          func(abi.String, *abi.Char, abi.Int) abi.UIntMax          `ffi:"strtoumax"`
 
@@ -249,11 +256,27 @@ var Memory struct {
 
 	Sort func(abi.UnsafePointer, abi.Size, abi.Size, func(abi.UnsafePointer, abi.UnsafePointer) abi.Int) abi.UnsafePointer `ffi:"qsort"`
 
-	Compare func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.Int                     `ffi:"memcmp"`
-	Copy    func(abi.UnsafePointer, abi.Size, abi.UnsafePointer, abi.Size) abi.UnsafePointer `ffi:"memcpy"`
-	Move    func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.UnsafePointer           `ffi:"memmove"`
-	Set     func(abi.UnsafePointer, abi.Int, abi.Size) abi.UnsafePointer                     `ffi:"memset"`
-	Find    func(abi.UnsafePointer, abi.Int, abi.Size) abi.UnsafePointer                     `ffi:"memchr"`
+	Compare func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.Int           `ffi:"memcmp"`
+	Copy    func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.UnsafePointer `ffi:"memcpy"`
+	Move    func(abi.UnsafePointer, abi.UnsafePointer, abi.Size) abi.UnsafePointer `ffi:"memmove"`
+	Set     func(abi.UnsafePointer, abi.Int, abi.Size) abi.UnsafePointer           `ffi:"memset"`
+	Find    func(abi.UnsafePointer, abi.Int, abi.Size) abi.UnsafePointer           `ffi:"memchr"`
+}
+
+// Sort sorts the slice in place with qsort, as ordered by cmp, which
+// returns a negative, zero or positive value when a is less than, equal
+// to or greater than b. It is the typed form of Memory.Sort, which as
+// a func field cannot be generic. T must not hold any Go pointers, as
+// C moves the elements.
+func Sort[T any](slice []T, cmp func(a, b *T) abi.Int) {
+	if len(slice) == 0 {
+		return
+	}
+	compare := ffi.NewCallback(func(a, b abi.UnsafePointer) abi.Int {
+		return cmp((*T)(a), (*T)(b))
+	})
+	defer compare.Free()
+	Memory.Sort(abi.UnsafePointer(unsafe.SliceData(slice)), abi.Size(len(slice)), abi.Size(unsafe.Sizeof(slice[0])), compare.Func())
 }
 
 var Time struct {
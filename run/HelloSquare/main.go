@@ -2,13 +2,11 @@ package main
 
 import (
 	"fmt"
-	"runtime"
 
 	"qlova.tech/lib/sdl/v2"
 )
 
 func init() {
-	runtime.LockOSThread()
 	if err := sdl.Link(); err != nil {
 		panic(err)
 	}